@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -19,6 +20,22 @@ import (
 const (
 	// ShutdownTimeout is the maximum time to wait for graceful shutdown.
 	ShutdownTimeout = 10 * time.Second
+
+	// DefaultCacheMaxEntries is the default maximum number of cached files
+	// (0 = unlimited, as before the cache could be bounded).
+	DefaultCacheMaxEntries = 0
+
+	// DefaultCacheMaxTags is the default maximum number of cached headings
+	// across all files (0 = unlimited).
+	DefaultCacheMaxTags = 0
+
+	// DefaultCacheIdleTTL is the default time after which an unused cache
+	// entry is evicted (0 = never).
+	DefaultCacheIdleTTL time.Duration = 0
+
+	// DefaultIndexFlushInterval is how often the persistent tag index is
+	// written to disk while the server runs.
+	DefaultIndexFlushInterval = time.Minute
 )
 
 func main() {
@@ -38,6 +55,70 @@ func run() error {
 		"ctags",
 		"Path to the ctags executable (defaults to 'ctags' in PATH)",
 	)
+	parserFlag := flag.String(
+		"parser",
+		string(ctags.ParserAuto),
+		"Heading parser: 'native' (pure Go), 'ctags' (Universal Ctags), "+
+			"or 'auto' (ctags when installed, native otherwise)",
+	)
+	cacheMaxEntries := flag.Int(
+		"cache-max-entries",
+		DefaultCacheMaxEntries,
+		"Maximum number of files kept in the heading cache (0 = unlimited)",
+	)
+	cacheMaxTags := flag.Int(
+		"cache-max-tags",
+		DefaultCacheMaxTags,
+		"Maximum number of headings kept in the cache across all files "+
+			"(0 = unlimited)",
+	)
+	cacheIdleTTL := flag.Duration(
+		"cache-idle-ttl",
+		DefaultCacheIdleTTL,
+		"Evict cached files not accessed for this long (0 = never)",
+	)
+	cacheValidation := flag.String(
+		"cache-validation",
+		string(ctags.ValidateMtime),
+		"How cached headings are validated against the file: 'mtime', "+
+			"'size+mtime', or 'hash' (size plus content hash, read on every "+
+			"request)",
+	)
+	indexFile := flag.String(
+		"index-file",
+		"",
+		"Path of a persistent tag index reused across restarts "+
+			"(empty = disabled)",
+	)
+	indexFlushInterval := flag.Duration(
+		"index-flush-interval",
+		DefaultIndexFlushInterval,
+		"How often the persistent tag index is written to disk",
+	)
+	watchRoots := flag.String(
+		"watch",
+		"",
+		"Comma-separated directories watched for changes (Linux only); "+
+			"cached headings in them are served without checking the file "+
+			"(empty = disabled)",
+	)
+	watchAction := flag.String(
+		"watch-action",
+		string(ctags.WatchReparse),
+		"What to do when a watched file changes: 'reparse' (parse it "+
+			"again right away) or 'invalidate' (parse on the next request)",
+	)
+	prewarm := flag.Bool(
+		"prewarm",
+		false,
+		"Parse all markdown files under the -watch directories at startup",
+	)
+	journalEntries := flag.Int(
+		"journal-entries",
+		tools.DefaultJournalEntries,
+		"Number of server edits kept per file for markdown_undo "+
+			"(0 = disabled)",
+	)
 	flag.Parse()
 
 	// Create a logger
@@ -47,19 +128,88 @@ func run() error {
 		ReplaceAttr: nil,
 	}))
 
+	// Configure heading parser
+	parserMode, err := ctags.ParseParserMode(*parserFlag)
+	if err != nil {
+		return fmt.Errorf("invalid parser: %w", err)
+	}
+	if err := ctags.SetParserMode(parserMode); err != nil {
+		return fmt.Errorf("invalid parser: %w", err)
+	}
+
 	// Configure ctags executable path
-	if err := ctags.SetCtagsPath(*ctagsPath); err != nil {
-		logger.Error("Failed to configure ctags path",
-			"path", *ctagsPath,
-			"error", err,
-		)
-		return fmt.Errorf("invalid ctags path: %w", err)
+	if err := configureCtags(logger, parserMode, *ctagsPath); err != nil {
+		return err
 	}
 
-	logger.Info("Configured ctags executable",
-		"path", ctags.GetCtagsPath(),
+	logger.Info("Configured heading parser",
+		"parser", string(parserMode),
 	)
 
+	// Configure cache bounds
+	cacheLimits := ctags.CacheLimits{
+		MaxEntries: *cacheMaxEntries,
+		MaxTags:    *cacheMaxTags,
+		IdleTTL:    *cacheIdleTTL,
+	}
+	if err := ctags.GetGlobalCache().SetLimits(cacheLimits); err != nil {
+		return fmt.Errorf("invalid cache limits: %w", err)
+	}
+
+	validationMode, err := ctags.ParseValidationMode(*cacheValidation)
+	if err != nil {
+		return fmt.Errorf("invalid cache validation: %w", err)
+	}
+	err = ctags.GetGlobalCache().SetValidationMode(validationMode)
+	if err != nil {
+		return fmt.Errorf("invalid cache validation: %w", err)
+	}
+
+	logger.Info("Configured heading cache",
+		"max_entries", cacheLimits.MaxEntries,
+		"max_tags", cacheLimits.MaxTags,
+		"idle_ttl", cacheLimits.IdleTTL.String(),
+		"validation", string(validationMode),
+	)
+
+	// Configure the edit journal
+	if err := tools.SetJournalLimit(*journalEntries); err != nil {
+		return fmt.Errorf("invalid journal entries: %w", err)
+	}
+
+	logger.Info("Configured edit journal",
+		"entries_per_file", *journalEntries,
+	)
+
+	// Open the persistent tag index
+	if err := configureTagStore(
+		ctx,
+		logger,
+		*indexFile,
+		*indexFlushInterval,
+	); err != nil {
+		return err
+	}
+
+	// Watch documentation directories for changes
+	watcher, err := configureWatcher(
+		ctx,
+		logger,
+		*watchRoots,
+		*watchAction,
+		*prewarm,
+	)
+	if err != nil {
+		return err
+	}
+	if watcher != nil {
+		defer func() {
+			if err := watcher.Close(); err != nil {
+				logger.Error("Failed to stop file watcher", "error", err)
+			}
+		}()
+	}
+
 	// Setup signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -83,6 +233,21 @@ func run() error {
 	tools.RegisterMarkdownSectionBounds(srv)
 	tools.RegisterMarkdownReadSection(srv)
 	tools.RegisterMarkdownListSections(srv)
+	tools.RegisterMarkdownSectionAtLine(srv)
+	tools.RegisterMarkdownWorkspaceTree(srv)
+	tools.RegisterMarkdownFindSections(srv)
+	tools.RegisterMarkdownSearch(srv)
+	tools.RegisterMarkdownSearchRanked(srv)
+	tools.RegisterMarkdownReplaceSection(srv)
+	tools.RegisterMarkdownInsertSection(srv)
+	tools.RegisterMarkdownMoveSection(srv)
+	tools.RegisterMarkdownRenameSection(srv)
+	tools.RegisterMarkdownDeleteSection(srv)
+	tools.RegisterMarkdownRelevelSection(srv)
+	tools.RegisterMarkdownAppendToSection(srv)
+	tools.RegisterMarkdownApplyEdits(srv)
+	tools.RegisterMarkdownUndo(srv)
+	tools.RegisterMarkdownHistory(srv)
 
 	logger.Info("Starting markdown-nav MCP server",
 		"tools", []string{
@@ -90,6 +255,21 @@ func run() error {
 			"markdown_section_bounds",
 			"markdown_read_section",
 			"markdown_list_sections",
+			"markdown_section_at_line",
+			"markdown_workspace_tree",
+			"markdown_find_sections",
+			"markdown_search",
+			"markdown_search_ranked",
+			"markdown_replace_section",
+			"markdown_insert_section",
+			"markdown_move_section",
+			"markdown_rename_section",
+			"markdown_delete_section",
+			"markdown_relevel_section",
+			"markdown_append_to_section",
+			"markdown_apply_edits",
+			"markdown_undo",
+			"markdown_history",
 		},
 	)
 
@@ -136,3 +316,156 @@ func run() error {
 	logger.Info("Server shutdown complete")
 	return nil
 }
+
+// configureTagStore opens the persistent tag index, attaches it to the
+// global cache and flushes it periodically until ctx is cancelled. An empty
+// path disables the index.
+func configureTagStore(
+	ctx context.Context,
+	logger *slog.Logger,
+	path string,
+	flushInterval time.Duration,
+) error {
+	if path == "" {
+		return nil
+	}
+
+	store, err := ctags.OpenTagStore(path)
+	if err != nil {
+		return fmt.Errorf("failed to open tag index: %w", err)
+	}
+	if recovered := store.RecoveredFrom(); recovered != "" {
+		logger.Warn("Tag index was corrupt, starting with an empty index",
+			"path", path,
+			"moved_to", recovered,
+		)
+	}
+
+	cache := ctags.GetGlobalCache()
+	cache.SetStore(store)
+
+	logger.Info("Opened tag index",
+		"path", path,
+		"files", store.Len(),
+	)
+
+	if flushInterval <= 0 {
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := cache.FlushStore(); err != nil {
+					logger.Error("Failed to flush tag index",
+						"path", path,
+						"error", err,
+					)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// configureWatcher starts a file watcher on the comma-separated roots and,
+// if requested, prewarms the cache in the background. An empty roots list
+// disables watching and returns a nil watcher.
+func configureWatcher(
+	ctx context.Context,
+	logger *slog.Logger,
+	roots string,
+	actionFlag string,
+	prewarm bool,
+) (*ctags.Watcher, error) {
+	var rootList []string
+	for _, root := range strings.Split(roots, ",") {
+		if root = strings.TrimSpace(root); root != "" {
+			rootList = append(rootList, root)
+		}
+	}
+	if len(rootList) == 0 {
+		if prewarm {
+			logger.Warn("Ignoring -prewarm without -watch directories")
+		}
+		return nil, nil
+	}
+
+	action, err := ctags.ParseWatchAction(actionFlag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid watch action: %w", err)
+	}
+
+	watcher, err := ctags.NewWatcher(ctags.GetGlobalCache(), ctags.WatcherOptions{
+		Roots:  rootList,
+		Action: action,
+		Logger: logger,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	watcher.Start(ctx)
+
+	logger.Info("Watching for file changes",
+		"roots", watcher.Roots(),
+		"directories", watcher.Dirs(),
+		"action", string(action),
+	)
+
+	if prewarm {
+		go func() {
+			start := time.Now()
+			warmed, err := watcher.Prewarm(ctx)
+			if err != nil {
+				logger.Warn("Prewarm stopped early", "error", err)
+			}
+			logger.Info("Prewarmed heading cache",
+				"files", warmed,
+				"duration", time.Since(start).String(),
+			)
+		}()
+	}
+
+	return watcher, nil
+}
+
+// configureCtags sets the ctags executable path for the given parser mode.
+// A missing ctags binary is fatal only in ParserCtags mode; in ParserAuto
+// mode the native parser is used instead, and ParserNative never needs it.
+func configureCtags(
+	logger *slog.Logger,
+	mode ctags.ParserMode,
+	ctagsPath string,
+) error {
+	if mode == ctags.ParserNative {
+		return nil
+	}
+
+	if err := ctags.SetCtagsPath(ctagsPath); err != nil {
+		if mode == ctags.ParserAuto {
+			logger.Warn("ctags not available, using native parser",
+				"path", ctagsPath,
+				"error", err,
+			)
+			return nil
+		}
+
+		logger.Error("Failed to configure ctags path",
+			"path", ctagsPath,
+			"error", err,
+		)
+		return fmt.Errorf("invalid ctags path: %w", err)
+	}
+
+	logger.Info("Configured ctags executable",
+		"path", ctags.GetCtagsPath(),
+	)
+	return nil
+}
@@ -0,0 +1,159 @@
+package ctags
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// minBatchSize is the smallest number of uncached files worth a batched
+// ctags execution; a single file takes the regular GetTags path.
+const minBatchSize = 2
+
+// FileTags holds the headings of one file returned by GetTagsBatch.
+type FileTags struct {
+	FilePath string
+	Tags     []*TagEntry
+	Err      error // Why the headings could not be read, or nil
+}
+
+// preparedTags are headings extracted ahead of a cache fill, together with
+// the version of the file they were extracted from.
+type preparedTags struct {
+	fingerprint fileFingerprint
+	tags        []*TagEntry
+}
+
+// GetTagsBatch returns the headings of several files, in the order given.
+// Files missing from the cache and the persistent store are parsed with a
+// single ctags execution when the parser uses ctags; otherwise, or if that
+// execution fails, each file is parsed on its own. Cached results and
+// statistics are the same as for individual GetTags calls.
+//
+// Per-file failures are reported in FileTags.Err; only context cancellation
+// is returned as an error.
+func (cm *CacheManager) GetTagsBatch(
+	ctx context.Context,
+	filePaths []string,
+) ([]FileTags, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context error before cache operation: %w", err)
+	}
+
+	prepared := cm.prepareBatch(ctx, filePaths)
+
+	results := make([]FileTags, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("context error during batch: %w", err)
+		}
+
+		tags, err := cm.getTags(ctx, filePath, prepared[filePath])
+		results = append(results, FileTags{
+			FilePath: filePath,
+			Tags:     tags,
+			Err:      err,
+		})
+	}
+
+	return results, nil
+}
+
+// prepareBatch runs ctags once over the files that need parsing and returns
+// their normalized headings by path. It returns nil when the native parser
+// is in use, when fewer than minBatchSize files need parsing, or when ctags
+// fails.
+func (cm *CacheManager) prepareBatch(
+	ctx context.Context,
+	filePaths []string,
+) map[string]*preparedTags {
+	if !usesCtags() {
+		return nil
+	}
+
+	mode := cm.ValidationMode()
+	fingerprints := make(map[string]fileFingerprint)
+	var misses []string
+	for _, filePath := range filePaths {
+		if _, seen := fingerprints[filePath]; seen {
+			continue
+		}
+		if fingerprint, ok := cm.needsParse(filePath, mode); ok {
+			fingerprints[filePath] = fingerprint
+			misses = append(misses, filePath)
+		}
+	}
+	if len(misses) < minBatchSize {
+		return nil
+	}
+
+	jsonData, err := ExecuteCtagsBatch(ctx, misses)
+	if err != nil {
+		return nil
+	}
+	grouped, err := GroupJSONTags(jsonData, misses)
+	if err != nil {
+		return nil
+	}
+
+	prepared := make(map[string]*preparedTags, len(misses))
+	for _, filePath := range misses {
+		tags, err := normalizeCtagsTags(grouped[filePath], filePath)
+		if err != nil {
+			continue // Parsed again by getTags, which reports the error
+		}
+		prepared[filePath] = &preparedTags{
+			fingerprint: fingerprints[filePath],
+			tags:        tags,
+		}
+	}
+
+	return prepared
+}
+
+// needsParse reports whether a file is missing from both the cache and the
+// persistent store, returning its current fingerprint. Files that cannot be
+// read are left to getTags, which reports the error.
+func (cm *CacheManager) needsParse(
+	filePath string,
+	mode ValidationMode,
+) (fileFingerprint, bool) {
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return fileFingerprint{}, false
+	}
+	fingerprint, err := newFileFingerprint(filePath, stat, mode)
+	if err != nil {
+		return fileFingerprint{}, false
+	}
+
+	cm.mu.Lock()
+	entry, exists := cm.cache[filePath]
+	fresh := exists && (entry.watched || fingerprint.matches(entry, mode))
+	cm.mu.Unlock()
+	if fresh {
+		return fileFingerprint{}, false
+	}
+
+	if store := cm.tagStore.Load(); store != nil {
+		if _, ok := store.get(filePath, fingerprint, mode); ok {
+			return fileFingerprint{}, false
+		}
+	}
+
+	return fingerprint, true
+}
+
+// usesCtags reports whether files are currently parsed with ctags.
+func usesCtags() bool {
+	switch GetParserMode() {
+	case ParserCtags:
+		return true
+	case ParserAuto:
+		return IsCtagsInstalled()
+	case ParserNative:
+		return false
+	default:
+		return false
+	}
+}
@@ -1,46 +1,174 @@
 package ctags
 
 import (
+	"container/list"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
 // CacheEntry represents a cached set of tags for a file.
-// It stores the file path, modification time, and parsed tags.
+// It stores the file path, the file version it was parsed from (modification
+// time, size and, in hash validation mode, content hash), and parsed tags.
 type CacheEntry struct {
-	FilePath string
-	ModTime  time.Time
-	Tags     []*TagEntry
+	FilePath   string
+	ModTime    time.Time
+	Size       int64  // File size in bytes
+	Hash       string // Content hash (ValidateHash mode only, else empty)
+	Tags       []*TagEntry
+	LastAccess time.Time     // Time of the last cache hit or fill
+	element    *list.Element // Position in the LRU list
+	absPath    string        // Absolute path, set while a Watcher runs
+	watched    bool          // Kept current by a Watcher, not by stat
 }
 
-// CacheManager manages in-memory caching of ctags output with mtime-based invalidation.
+// CacheLimits bounds the memory used by a CacheManager. A zero value for
+// any field disables that limit.
+type CacheLimits struct {
+	MaxEntries int           // Maximum number of cached files
+	MaxTags    int           // Maximum number of tags across all files
+	IdleTTL    time.Duration // Evict entries not accessed for this long
+}
+
+// CacheStats is a snapshot of cache counters and current size.
+type CacheStats struct {
+	Hits      uint64 // Lookups served from the cache
+	Misses    uint64 // Lookups that had to parse the file
+	Evictions uint64 // Entries removed by limits or idle expiry
+	StoreHits uint64 // Misses served from the persistent TagStore
+	Entries   int    // Files currently cached
+	Tags      int    // Tags currently cached across all files
+
+	// Validation breaks hits and misses down by the ValidationMode that
+	// decided them.
+	Validation map[ValidationMode]ValidationStats
+}
+
+// ValidationStats counts the hits and misses decided by one ValidationMode.
+type ValidationStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// validationCounters holds the hit and miss counters of one ValidationMode.
+type validationCounters struct {
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// CacheManager manages in-memory caching of parsed headings, invalidated when
+// the file changes according to the ValidationMode (mtime by default).
+// Headings come from ctags or the native parser, depending on the configured
+// ParserMode.
 // It provides concurrent-safe access to cached tags with automatic invalidation
 // when files change. The cache uses per-file mutexes to prevent duplicate
 // ctags executions for the same file when multiple goroutines request it simultaneously.
+//
+// The cache can be bounded with CacheLimits. Entries are evicted in least
+// recently used order when the entry or tag limits are exceeded, and entries
+// idle for longer than the TTL expire.
 type CacheManager struct {
 	cache      map[string]*CacheEntry // Cached entries by file path
-	mu         sync.RWMutex           // Protects cache map
+	lru        *list.List             // Cache entries, most recently used first
+	totalTags  int                    // Tags across all cached entries
+	limits     CacheLimits            // Configured bounds (zero = unbounded)
+	now        func() time.Time       // Clock, replaceable in tests
+	mu         sync.Mutex             // Protects cache, lru, totalTags, limits
 	hits       atomic.Uint64          // Cache hit counter
 	misses     atomic.Uint64          // Cache miss counter
+	evictions  atomic.Uint64          // Eviction counter
 	inProgress map[string]*sync.Mutex // Track in-progress operations per file
 	progressMu sync.Mutex             // Protects inProgress map
+
+	// validation holds the current ValidationMode.
+	validation atomic.Value
+	// validationStats has one entry per mode, created up front.
+	validationStats map[ValidationMode]*validationCounters
+
+	tagStore  atomic.Pointer[TagStore] // Optional persistent index
+	storeHits atomic.Uint64            // Misses served from tagStore
+
+	watcher    atomic.Pointer[Watcher] // Running file watcher, if any
+	generation atomic.Uint64           // Bumped by every watch invalidation
+
+	sections *sectionIndex // Section text of cached files, see RankSections
+	writeMu  sync.Mutex    // Serializes ReplaceFile
 }
 
-// NewCacheManager creates a new cache manager.
+// NewCacheManager creates a new unbounded cache manager.
 func NewCacheManager() *CacheManager {
-	return &CacheManager{
+	return NewCacheManagerWithLimits(CacheLimits{
+		MaxEntries: 0,
+		MaxTags:    0,
+		IdleTTL:    0,
+	})
+}
+
+// NewCacheManagerWithLimits creates a cache manager bounded by limits.
+// Entries are validated by modification time; see SetValidationMode.
+func NewCacheManagerWithLimits(limits CacheLimits) *CacheManager {
+	counters := make(map[ValidationMode]*validationCounters)
+	for _, mode := range validationModes() {
+		counters[mode] = &validationCounters{
+			hits:   atomic.Uint64{},
+			misses: atomic.Uint64{},
+		}
+	}
+
+	cm := &CacheManager{
 		cache:      make(map[string]*CacheEntry),
-		mu:         sync.RWMutex{},
+		lru:        list.New(),
+		totalTags:  0,
+		limits:     limits,
+		now:        time.Now,
+		mu:         sync.Mutex{},
 		hits:       atomic.Uint64{},
 		misses:     atomic.Uint64{},
+		evictions:  atomic.Uint64{},
 		inProgress: make(map[string]*sync.Mutex),
 		progressMu: sync.Mutex{},
+
+		validation:      atomic.Value{},
+		validationStats: counters,
+
+		tagStore:  atomic.Pointer[TagStore]{},
+		storeHits: atomic.Uint64{},
+
+		watcher:    atomic.Pointer[Watcher]{},
+		generation: atomic.Uint64{},
+
+		sections: newSectionIndex(),
+		writeMu:  sync.Mutex{},
+	}
+	cm.validation.Store(ValidateMtime)
+
+	return cm
+}
+
+// SetValidationMode selects how cached entries are validated against the
+// file on disk. Entries cached under another mode are revalidated lazily.
+//
+// Errors include: ErrInvalidValidationMode.
+func (cm *CacheManager) SetValidationMode(mode ValidationMode) error {
+	if _, err := ParseValidationMode(string(mode)); err != nil {
+		return err
 	}
+
+	cm.validation.Store(mode)
+	return nil
+}
+
+// ValidationMode returns the current cache validation mode.
+func (cm *CacheManager) ValidationMode() ValidationMode {
+	mode, _ := cm.validation.Load().(ValidationMode)
+	return mode
 }
 
 // globalCache is the singleton cache instance used throughout the application.
@@ -55,19 +183,43 @@ func GetGlobalCache() *CacheManager {
 }
 
 // GetTags retrieves tags for a file, using cache if available and valid.
-// Cache validation is based on file modification time (mtime).
+// Cache validation follows the ValidationMode: modification time (default),
+// size and modification time, or size and content hash.
+// While a Watcher runs, files in watched directories are not checked on
+// disk at all: the watcher invalidates them when they change.
 // Concurrent requests for the same file are serialized to prevent duplicate work.
 // The context allows cancellation for graceful shutdown.
 func (cm *CacheManager) GetTags(
 	ctx context.Context,
 	filePath string,
+) ([]*TagEntry, error) {
+	return cm.getTags(ctx, filePath, nil)
+}
+
+// getTags implements GetTags. On a miss, prepared tags are used instead of
+// parsing the file if they were extracted from the same file version.
+func (cm *CacheManager) getTags(
+	ctx context.Context,
+	filePath string,
+	prepared *preparedTags,
 ) ([]*TagEntry, error) {
 	// Check context before starting
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("context error before cache operation: %w", err)
 	}
 
-	// Get file modification time
+	mode := cm.ValidationMode()
+
+	// Watched entries are current until the watcher invalidates them
+	if tags, ok := cm.lookupWatched(filePath); ok {
+		cm.recordHit(mode)
+		return tags, nil
+	}
+
+	// Changes reported after this point make the parsed headings untrusted
+	generation := cm.generation.Load()
+
+	// Get file modification time and size
 	stat, err := os.Stat(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -75,17 +227,17 @@ func (cm *CacheManager) GetTags(
 		}
 		return nil, fmt.Errorf("failed to stat file: %w", err)
 	}
-	currentMtime := stat.ModTime()
 
-	// Check cache with read lock
-	cm.mu.RLock()
-	entry, exists := cm.cache[filePath]
-	cm.mu.RUnlock()
+	fingerprint, err := newFileFingerprint(filePath, stat, mode)
+	if err != nil {
+		return nil, err
+	}
+	usePrepared := prepared != nil && prepared.fingerprint.equal(fingerprint)
 
-	// Cache hit: return cached data if mtime matches
-	if exists && entry.ModTime.Equal(currentMtime) {
-		cm.hits.Add(1)
-		return entry.Tags, nil
+	// Cache hit: return cached data if the file is unchanged
+	if tags, ok := cm.lookup(filePath, fingerprint, mode); ok {
+		cm.recordHit(mode)
+		return tags, nil
 	}
 
 	// Cache miss: need to execute ctags
@@ -109,23 +261,352 @@ func (cm *CacheManager) GetTags(
 	}()
 
 	// Check cache again in case another goroutine just populated it
-	cm.mu.RLock()
-	entry, exists = cm.cache[filePath]
-	cm.mu.RUnlock()
-
-	if exists && entry.ModTime.Equal(currentMtime) {
-		cm.hits.Add(1)
-		return entry.Tags, nil
+	if tags, ok := cm.lookup(filePath, fingerprint, mode); ok {
+		cm.recordHit(mode)
+		return tags, nil
 	}
 
-	// Execute ctags (only one goroutine reaches here per file)
-	cm.misses.Add(1)
+	// Extract tags (only one goroutine reaches here per file)
+	cm.recordMiss(mode)
+
+	// Reuse headings parsed by a previous session if the file is unchanged
+	store := cm.tagStore.Load()
+	if store != nil {
+		if tags, ok := store.get(filePath, fingerprint, mode); ok {
+			cm.storeHits.Add(1)
+			cm.insert(filePath, fingerprint, tags, generation)
+			return tags, nil
+		}
+
+		// Persisted records always carry a content hash, so that they can
+		// be validated in any mode
+		if fingerprint.Hash == "" {
+			fingerprint, err = newFileFingerprint(filePath, stat, ValidateHash)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	// Check context before expensive operation
 	if err := ctx.Err(); err != nil {
 		return nil, fmt.Errorf("context error before ctags execution: %w", err)
 	}
 
+	var tags []*TagEntry
+	if usePrepared {
+		tags = prepared.tags
+	} else if tags, err = extractTags(ctx, filePath); err != nil {
+		return nil, err
+	}
+
+	// Sort tags by line number to ensure document order
+	SortByLine(tags)
+
+	cm.insert(filePath, fingerprint, tags, generation)
+	if store != nil {
+		store.put(filePath, fingerprint, tags)
+	}
+
+	return tags, nil
+}
+
+// SetStore attaches a persistent TagStore consulted on cache misses and
+// updated after every parse. A nil store detaches it.
+func (cm *CacheManager) SetStore(store *TagStore) {
+	cm.tagStore.Store(store)
+}
+
+// FlushStore writes the attached TagStore to disk if it changed.
+// It is a no-op when no store is attached.
+func (cm *CacheManager) FlushStore() error {
+	store := cm.tagStore.Load()
+	if store == nil {
+		return nil
+	}
+	return store.Flush()
+}
+
+// recordHit counts a cache hit decided by the given validation mode.
+func (cm *CacheManager) recordHit(mode ValidationMode) {
+	cm.hits.Add(1)
+	if counters, ok := cm.validationStats[mode]; ok {
+		counters.hits.Add(1)
+	}
+}
+
+// recordMiss counts a cache miss decided by the given validation mode.
+func (cm *CacheManager) recordMiss(mode ValidationMode) {
+	cm.misses.Add(1)
+	if counters, ok := cm.validationStats[mode]; ok {
+		counters.misses.Add(1)
+	}
+}
+
+// lookup returns the cached tags for a file if the entry exists, is valid
+// for the fingerprint under the validation mode and has not been idle past
+// the TTL. A hit marks the entry as most recently used.
+func (cm *CacheManager) lookup(
+	filePath string,
+	fingerprint fileFingerprint,
+	mode ValidationMode,
+) ([]*TagEntry, bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	now := cm.now()
+	cm.evictExpiredLocked(now)
+
+	entry, exists := cm.cache[filePath]
+	if !exists || !fingerprint.matches(entry, mode) {
+		return nil, false
+	}
+
+	entry.LastAccess = now
+	cm.lru.MoveToFront(entry.element)
+	return entry.Tags, true
+}
+
+// lookupWatched returns the cached tags for a file kept current by a running
+// Watcher, without touching the file system. Idle entries still expire.
+func (cm *CacheManager) lookupWatched(filePath string) ([]*TagEntry, bool) {
+	if cm.watcher.Load() == nil {
+		return nil, false
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	now := cm.now()
+	cm.evictExpiredLocked(now)
+
+	entry, exists := cm.cache[filePath]
+	if !exists || !entry.watched {
+		return nil, false
+	}
+
+	entry.LastAccess = now
+	cm.lru.MoveToFront(entry.element)
+	return entry.Tags, true
+}
+
+// insert caches tags for a file and evicts entries beyond the limits.
+// The entry is trusted without stat checks if a running Watcher covers the
+// file and no change was reported since generation was read.
+func (cm *CacheManager) insert(
+	filePath string,
+	fingerprint fileFingerprint,
+	tags []*TagEntry,
+	generation uint64,
+) {
+	absPath, watched := "", false
+	if watcher := cm.watcher.Load(); watcher != nil {
+		if abs, err := filepath.Abs(filePath); err == nil {
+			absPath = abs
+			watched = watcher.covers(abs)
+		}
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if old, exists := cm.cache[filePath]; exists {
+		cm.removeLocked(old)
+	}
+
+	entry := &CacheEntry{
+		FilePath:   filePath,
+		ModTime:    fingerprint.ModTime,
+		Size:       fingerprint.Size,
+		Hash:       fingerprint.Hash,
+		Tags:       tags,
+		LastAccess: cm.now(),
+		element:    nil,
+		absPath:    absPath,
+		watched:    watched && cm.generation.Load() == generation,
+	}
+	entry.element = cm.lru.PushFront(entry)
+	cm.cache[filePath] = entry
+	cm.totalTags += len(tags)
+
+	cm.evictOverLimitLocked()
+}
+
+// attachWatcher makes a running Watcher responsible for keeping entries of
+// the files it covers current.
+func (cm *CacheManager) attachWatcher(watcher *Watcher) {
+	cm.watcher.Store(watcher)
+}
+
+// detachWatcher removes a stopped Watcher. Entries it kept current are
+// validated against the file system again.
+func (cm *CacheManager) detachWatcher(watcher *Watcher) {
+	if cm.watcher.CompareAndSwap(watcher, nil) {
+		cm.untrustAll()
+	}
+}
+
+// invalidatePath removes the entries for an absolute path, or for every
+// file below it when subtree is set, together with their persistent store
+// records. It returns the cache keys that were removed.
+func (cm *CacheManager) invalidatePath(absPath string, subtree bool) []string {
+	cm.generation.Add(1)
+	prefix := absPath + string(filepath.Separator)
+
+	var keys []string
+	cm.mu.Lock()
+	for key, entry := range cm.cache {
+		path := entry.absPath
+		if path == "" {
+			path = key
+		}
+		if path == absPath || (subtree && strings.HasPrefix(path, prefix)) {
+			cm.removeLocked(entry)
+			keys = append(keys, key)
+		}
+	}
+	cm.mu.Unlock()
+
+	if store := cm.tagStore.Load(); store != nil {
+		store.remove(absPath)
+		for _, key := range keys {
+			store.remove(key)
+		}
+	}
+
+	return keys
+}
+
+// untrustPath makes the entries for an absolute path subject to validation
+// against the file system again while the file is being written.
+func (cm *CacheManager) untrustPath(absPath string) {
+	cm.generation.Add(1)
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	for _, entry := range cm.cache {
+		if entry.absPath == absPath {
+			entry.watched = false
+		}
+	}
+}
+
+// untrustAll makes every entry subject to validation against the file
+// system again, e.g. after watch events were lost.
+func (cm *CacheManager) untrustAll() {
+	cm.generation.Add(1)
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	for _, entry := range cm.cache {
+		entry.watched = false
+	}
+}
+
+// evictExpiredLocked removes entries idle for longer than the TTL.
+// The LRU list is ordered by last access, so only its tail is inspected.
+// Callers must hold cm.mu.
+func (cm *CacheManager) evictExpiredLocked(now time.Time) {
+	if cm.limits.IdleTTL <= 0 {
+		return
+	}
+
+	for element := cm.lru.Back(); element != nil; element = cm.lru.Back() {
+		entry, _ := element.Value.(*CacheEntry)
+		if now.Sub(entry.LastAccess) <= cm.limits.IdleTTL {
+			return
+		}
+		cm.removeLocked(entry)
+		cm.evictions.Add(1)
+	}
+}
+
+// evictOverLimitLocked removes least recently used entries until the entry
+// and tag limits are met. The most recently used entry is always kept, even
+// if it alone exceeds the tag limit. Callers must hold cm.mu.
+func (cm *CacheManager) evictOverLimitLocked() {
+	cm.evictExpiredLocked(cm.now())
+
+	for cm.lru.Len() > 1 && cm.overLimitLocked() {
+		entry, _ := cm.lru.Back().Value.(*CacheEntry)
+		cm.removeLocked(entry)
+		cm.evictions.Add(1)
+	}
+}
+
+// overLimitLocked reports whether the cache exceeds its entry or tag limit.
+// Callers must hold cm.mu.
+func (cm *CacheManager) overLimitLocked() bool {
+	if cm.limits.MaxEntries > 0 && len(cm.cache) > cm.limits.MaxEntries {
+		return true
+	}
+	return cm.limits.MaxTags > 0 && cm.totalTags > cm.limits.MaxTags
+}
+
+// removeLocked removes an entry from the map and LRU list, together with
+// its section index postings. Callers must hold cm.mu.
+func (cm *CacheManager) removeLocked(entry *CacheEntry) {
+	delete(cm.cache, entry.FilePath)
+	cm.lru.Remove(entry.element)
+	cm.totalTags -= len(entry.Tags)
+	cm.sections.remove(entry.FilePath)
+}
+
+// SetLimits changes the cache bounds and immediately evicts entries that
+// exceed them.
+//
+// Errors include: ErrInvalidCacheLimits.
+func (cm *CacheManager) SetLimits(limits CacheLimits) error {
+	if limits.MaxEntries < 0 || limits.MaxTags < 0 || limits.IdleTTL < 0 {
+		return fmt.Errorf(
+			"%w: max entries %d, max tags %d, idle TTL %s (must not be negative)",
+			ErrInvalidCacheLimits,
+			limits.MaxEntries,
+			limits.MaxTags,
+			limits.IdleTTL,
+		)
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	cm.limits = limits
+	cm.evictOverLimitLocked()
+	return nil
+}
+
+// Limits returns the configured cache bounds.
+func (cm *CacheManager) Limits() CacheLimits {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.limits
+}
+
+// extractTags extracts heading tags from a file using the configured parser
+// mode. In ParserAuto mode, the native parser is used when ctags is missing.
+func extractTags(ctx context.Context, filePath string) ([]*TagEntry, error) {
+	mode := GetParserMode()
+	switch mode {
+	case ParserNative:
+		return ParseMarkdownFile(ctx, filePath)
+	case ParserCtags:
+		return extractTagsWithCtags(ctx, filePath)
+	case ParserAuto:
+		tags, err := extractTagsWithCtags(ctx, filePath)
+		if errors.Is(err, ErrCtagsNotFound) {
+			return ParseMarkdownFile(ctx, filePath)
+		}
+		return tags, err
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidParserMode, mode)
+	}
+}
+
+// extractTagsWithCtags runs Universal Ctags on a file and parses its output.
+func extractTagsWithCtags(
+	ctx context.Context,
+	filePath string,
+) ([]*TagEntry, error) {
 	jsonData, err := ExecuteCtags(ctx, filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute ctags: %w", err)
@@ -137,73 +618,157 @@ func (cm *CacheManager) GetTags(
 		return nil, fmt.Errorf("failed to parse ctags JSON: %w", err)
 	}
 
-	// Sort tags by line number to ensure document order
-	SortByLine(tags)
+	return normalizeCtagsTags(tags, filePath)
+}
 
-	// Update cache with write lock
-	cm.mu.Lock()
-	cm.cache[filePath] = &CacheEntry{
-		FilePath: filePath,
-		ModTime:  currentMtime,
-		Tags:     tags,
+// normalizeCtagsTags aligns the ctags entries of a file with the native
+// heading model.
+func normalizeCtagsTags(
+	tags []*TagEntry,
+	filePath string,
+) ([]*TagEntry, error) {
+	// Align ctags output with the native heading model: drop "headings"
+	// inside code blocks or comments and add setext headings
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
-	cm.mu.Unlock()
+	lines := SplitContentLines(content)
+	SortByLine(tags)
+	tags = ExcludeBlockRegions(tags, lines)
+	tags = AddSetextHeadings(tags, lines, filePath)
+
+	// Normalize Scope to the full ancestor chain used for path queries and
+	// make slugs unique across the document
+	assignScopes(tags)
+	AssignSlugs(tags)
 
 	return tags, nil
 }
 
-// InvalidateFile removes a specific file from the cache.
+// InvalidateFile removes a specific file from the cache and from the
+// persistent store, if any.
 // This is useful for manually clearing cache when file changes are detected
 // through external means, though the cache automatically invalidates based on mtime.
 func (cm *CacheManager) InvalidateFile(filePath string) {
 	cm.mu.Lock()
-	delete(cm.cache, filePath)
+	if entry, exists := cm.cache[filePath]; exists {
+		cm.removeLocked(entry)
+	}
 	cm.mu.Unlock()
+
+	if store := cm.tagStore.Load(); store != nil {
+		store.remove(filePath)
+	}
 }
 
-// Clear removes all entries from the cache.
+// Clear removes all entries from the in-memory cache. The persistent store
+// is left untouched.
 func (cm *CacheManager) Clear() {
 	cm.mu.Lock()
 	cm.cache = make(map[string]*CacheEntry)
+	cm.lru.Init()
+	cm.totalTags = 0
+	cm.sections.clear()
 	cm.mu.Unlock()
 }
 
 // Stats returns cache hit and miss statistics.
 // Useful for monitoring cache effectiveness and performance tuning.
+//
+// Evictions are reported by DetailedStats instead: Stats keeps its two
+// return values so that existing callers continue to compile.
 func (cm *CacheManager) Stats() (hits, misses uint64) {
 	return cm.hits.Load(), cm.misses.Load()
 }
 
+// DetailedStats returns cache hit, miss and eviction counters together with
+// the current number of cached entries and tags. Hits and misses are also
+// broken down by the validation mode that decided them.
+func (cm *CacheManager) DetailedStats() CacheStats {
+	cm.mu.Lock()
+	entries, tags := len(cm.cache), cm.totalTags
+	cm.mu.Unlock()
+
+	validation := make(map[ValidationMode]ValidationStats)
+	for mode, counters := range cm.validationStats {
+		validation[mode] = ValidationStats{
+			Hits:   counters.hits.Load(),
+			Misses: counters.misses.Load(),
+		}
+	}
+
+	return CacheStats{
+		Hits:       cm.hits.Load(),
+		Misses:     cm.misses.Load(),
+		Evictions:  cm.evictions.Load(),
+		StoreHits:  cm.storeHits.Load(),
+		Entries:    entries,
+		Tags:       tags,
+		Validation: validation,
+	}
+}
+
 // Size returns the number of entries currently cached.
 // Useful for monitoring memory usage and cache capacity.
 func (cm *CacheManager) Size() int {
-	cm.mu.RLock()
-	defer cm.mu.RUnlock()
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
 	return len(cm.cache)
 }
 
-// Shutdown logs cache statistics and clears all entries.
+// Shutdown logs cache statistics, flushes the persistent store and clears
+// all entries.
 // This should be called during graceful shutdown to release memory
 // and provide visibility into cache performance.
 func (cm *CacheManager) Shutdown(logger *slog.Logger) {
-	hits, misses := cm.Stats()
-	size := cm.Size()
+	stats := cm.DetailedStats()
 
-	total := hits + misses
+	if store := cm.tagStore.Load(); store != nil {
+		if err := store.Flush(); err != nil {
+			logger.Error("Failed to flush tag index",
+				"path", store.Path(),
+				"error", err,
+			)
+		} else {
+			logger.Info("Flushed tag index",
+				"path", store.Path(),
+				"files", store.Len(),
+				"store_hits", stats.StoreHits,
+			)
+		}
+	}
+
+	total := stats.Hits + stats.Misses
 	hitRate := "N/A"
 	if total > 0 {
 		hitRate = fmt.Sprintf(
 			"%.2f%%",
-			float64(hits)/float64(total)*100,
+			float64(stats.Hits)/float64(total)*100,
 		)
 	}
 
 	logger.Info("Cache shutdown statistics",
-		"hits", hits,
-		"misses", misses,
-		"size", size,
+		"hits", stats.Hits,
+		"misses", stats.Misses,
+		"evictions", stats.Evictions,
+		"size", stats.Entries,
+		"tags", stats.Tags,
 		"hit_rate", hitRate,
+		"validation", string(cm.ValidationMode()),
 	)
 
+	for _, mode := range validationModes() {
+		modeStats := stats.Validation[mode]
+		if modeStats.Hits+modeStats.Misses == 0 {
+			continue
+		}
+		logger.Info("Cache validation statistics",
+			"mode", string(mode),
+			"hits", modeStats.Hits,
+			"misses", modeStats.Misses,
+		)
+	}
+
 	cm.Clear()
 }
@@ -386,3 +386,245 @@ func BenchmarkCache_Miss(b *testing.B) {
 		_, _ = cache.GetTags(context.Background(), file)
 	}
 }
+
+// createMarkdownFiles creates count markdown files with the given number of
+// headings each and returns their paths.
+func createMarkdownFiles(t *testing.T, count, headings int) []string {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	files := make([]string, 0, count)
+	for i := range count {
+		var content string
+		for h := range headings {
+			content += fmt.Sprintf("## Heading %d\n", h)
+		}
+		file := filepath.Join(tmpDir, fmt.Sprintf("doc-%d.md", i))
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+		files = append(files, file)
+	}
+	return files
+}
+
+func TestCacheManager_MaxEntriesLRU(t *testing.T) {
+	cache := NewCacheManagerWithLimits(CacheLimits{
+		MaxEntries: 2,
+		MaxTags:    0,
+		IdleTTL:    0,
+	})
+	files := createMarkdownFiles(t, 3, 1)
+	ctx := context.Background()
+
+	_, err := cache.GetTags(ctx, files[0])
+	require.NoError(t, err)
+	_, err = cache.GetTags(ctx, files[1])
+	require.NoError(t, err)
+
+	// Touch files[0] so that files[1] becomes least recently used
+	_, err = cache.GetTags(ctx, files[0])
+	require.NoError(t, err)
+
+	_, err = cache.GetTags(ctx, files[2])
+	require.NoError(t, err)
+
+	stats := cache.DetailedStats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, uint64(1), stats.Evictions)
+
+	// files[0] is still cached, files[1] was evicted
+	_, err = cache.GetTags(ctx, files[0])
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), cache.DetailedStats().Hits)
+
+	_, err = cache.GetTags(ctx, files[1])
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), cache.DetailedStats().Misses)
+}
+
+func TestCacheManager_MaxTags(t *testing.T) {
+	cache := NewCacheManagerWithLimits(CacheLimits{
+		MaxEntries: 0,
+		MaxTags:    5,
+		IdleTTL:    0,
+	})
+	files := createMarkdownFiles(t, 3, 2)
+	ctx := context.Background()
+
+	for _, file := range files {
+		_, err := cache.GetTags(ctx, file)
+		require.NoError(t, err)
+	}
+
+	stats := cache.DetailedStats()
+	assert.Equal(t, 2, stats.Entries)
+	assert.Equal(t, 4, stats.Tags)
+	assert.Equal(t, uint64(1), stats.Evictions)
+
+	// A single file larger than the limit is still cached
+	large := createMarkdownFiles(t, 1, 8)[0]
+	_, err := cache.GetTags(ctx, large)
+	require.NoError(t, err)
+
+	stats = cache.DetailedStats()
+	assert.Equal(t, 1, stats.Entries)
+	assert.Equal(t, 8, stats.Tags)
+	assert.Equal(t, uint64(3), stats.Evictions)
+}
+
+func TestCacheManager_IdleTTL(t *testing.T) {
+	cache := NewCacheManagerWithLimits(CacheLimits{
+		MaxEntries: 0,
+		MaxTags:    0,
+		IdleTTL:    time.Minute,
+	})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	files := createMarkdownFiles(t, 2, 1)
+	ctx := context.Background()
+
+	_, err := cache.GetTags(ctx, files[0])
+	require.NoError(t, err)
+
+	now = now.Add(45 * time.Second)
+	_, err = cache.GetTags(ctx, files[1])
+	require.NoError(t, err)
+
+	// files[0] is idle for 75s, files[1] for 30s
+	now = now.Add(30 * time.Second)
+	_, err = cache.GetTags(ctx, files[1])
+	require.NoError(t, err)
+
+	stats := cache.DetailedStats()
+	assert.Equal(t, 1, stats.Entries)
+	assert.Equal(t, uint64(1), stats.Evictions)
+	assert.Equal(t, uint64(1), stats.Hits)
+}
+
+func TestCacheManager_SetLimits(t *testing.T) {
+	cache := NewCacheManager()
+	files := createMarkdownFiles(t, 4, 1)
+
+	for _, file := range files {
+		_, err := cache.GetTags(context.Background(), file)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 4, cache.Size())
+
+	err := cache.SetLimits(CacheLimits{MaxEntries: 1, MaxTags: 0, IdleTTL: 0})
+	require.NoError(t, err)
+	assert.Equal(t, 1, cache.Size())
+	assert.Equal(t, uint64(3), cache.DetailedStats().Evictions)
+
+	err = cache.SetLimits(CacheLimits{MaxEntries: -1, MaxTags: 0, IdleTTL: 0})
+	require.ErrorIs(t, err, ErrInvalidCacheLimits)
+	assert.Equal(t, 1, cache.Limits().MaxEntries)
+
+	cache.InvalidateFile(files[3])
+	stats := cache.DetailedStats()
+	assert.Equal(t, 0, stats.Entries)
+	assert.Equal(t, 0, stats.Tags)
+}
+
+func TestParseValidationMode(t *testing.T) {
+	for _, value := range []string{"mtime", "size+mtime", "hash"} {
+		mode, err := ParseValidationMode(value)
+		require.NoError(t, err)
+		assert.Equal(t, ValidationMode(value), mode)
+	}
+
+	_, err := ParseValidationMode("inode")
+	require.ErrorIs(t, err, ErrInvalidValidationMode)
+
+	cache := NewCacheManager()
+	require.ErrorIs(t, cache.SetValidationMode("inode"), ErrInvalidValidationMode)
+	assert.Equal(t, ValidateMtime, cache.ValidationMode())
+}
+
+// rewriteKeepingMtime replaces the file content and restores its previous
+// modification time, simulating edits within mtime granularity.
+func rewriteKeepingMtime(t *testing.T, filePath, content string) {
+	t.Helper()
+
+	stat, err := os.Stat(filePath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0o644))
+	require.NoError(t, os.Chtimes(filePath, stat.ModTime(), stat.ModTime()))
+}
+
+func TestCacheManager_ValidationModes(t *testing.T) {
+	tests := []struct {
+		mode ValidationMode
+		// Whether a same-mtime rewrite that changes the size is detected
+		detectsSizeChange bool
+		// Whether a same-mtime, same-size rewrite is detected
+		detectsSameSizeChange bool
+	}{
+		{ValidateMtime, false, false},
+		{ValidateSizeMtime, true, false},
+		{ValidateHash, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			cache := NewCacheManager()
+			require.NoError(t, cache.SetValidationMode(tt.mode))
+			file := createTestMarkdownFile(t, "# Alpha\n")
+			ctx := context.Background()
+
+			_, err := cache.GetTags(ctx, file)
+			require.NoError(t, err)
+
+			rewriteKeepingMtime(t, file, "# Alpha\n## Beta\n")
+			tags, err := cache.GetTags(ctx, file)
+			require.NoError(t, err)
+			if tt.detectsSizeChange {
+				assert.Len(t, tags, 2)
+			} else {
+				assert.Len(t, tags, 1, "stale entry expected")
+			}
+
+			rewriteKeepingMtime(t, file, "# Gamma\n## Beta\n")
+			tags, err = cache.GetTags(ctx, file)
+			require.NoError(t, err)
+			if tt.detectsSameSizeChange {
+				assert.Equal(t, "Gamma", tags[0].Name)
+			} else {
+				assert.NotEqual(t, "Gamma", tags[0].Name, "stale entry expected")
+			}
+
+			stats := cache.DetailedStats()
+			modeStats := stats.Validation[tt.mode]
+			assert.Equal(t, stats.Hits, modeStats.Hits)
+			assert.Equal(t, stats.Misses, modeStats.Misses)
+			assert.Equal(t, uint64(3), modeStats.Hits+modeStats.Misses)
+		})
+	}
+}
+
+func TestCacheManager_HashModeIgnoresTouch(t *testing.T) {
+	cache := NewCacheManager()
+	require.NoError(t, cache.SetValidationMode(ValidateHash))
+	file := createTestMarkdownFile(t, "# Title\n")
+	ctx := context.Background()
+
+	_, err := cache.GetTags(ctx, file)
+	require.NoError(t, err)
+
+	// A new mtime with unchanged content (e.g. git checkout) is still a hit
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(file, later, later))
+	_, err = cache.GetTags(ctx, file)
+	require.NoError(t, err)
+
+	stats := cache.DetailedStats()
+	assert.Equal(t, uint64(1), stats.Validation[ValidateHash].Hits)
+	assert.Equal(t, uint64(1), stats.Validation[ValidateHash].Misses)
+	assert.Equal(t, uint64(0), stats.Validation[ValidateMtime].Hits)
+}
+
+func TestContentHash(t *testing.T) {
+	assert.Equal(t, ContentHash([]byte("# A\n")), ContentHash([]byte("# A\n")))
+	assert.NotEqual(t, ContentHash([]byte("# A\n")), ContentHash([]byte("# B\n")))
+	assert.Len(t, ContentHash(nil), 16)
+}
@@ -1,6 +1,10 @@
 package ctags
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // Ctags execution errors.
 var (
@@ -10,3 +14,83 @@ var (
 	ErrFileNotFound     = errors.New("file not found")
 	ErrInvalidCtagsPath = errors.New("invalid ctags executable path")
 )
+
+// Parser configuration errors.
+var (
+	ErrInvalidParserMode = errors.New("invalid parser mode")
+)
+
+// Cache configuration errors.
+var (
+	ErrInvalidCacheLimits    = errors.New("invalid cache limits")
+	ErrInvalidValidationMode = errors.New("invalid cache validation mode")
+)
+
+// File watcher errors.
+var (
+	ErrWatcherUnsupported = errors.New("file watching is not supported")
+	ErrNoWatchRoots       = errors.New("no watch roots configured")
+	ErrInvalidWatchAction = errors.New("invalid watch action")
+	ErrNotADirectory      = errors.New("not a directory")
+)
+
+// File edit errors.
+var (
+	ErrFileChanged = errors.New("file changed since it was read")
+)
+
+// Section lookup errors.
+var (
+	ErrSectionNotFound      = errors.New("section not found")
+	ErrOccurrenceOutOfRange = errors.New("section occurrence out of range")
+	ErrAmbiguousSection     = errors.New("ambiguous section")
+	ErrInvalidMatchMode     = errors.New("invalid match mode")
+	ErrInvalidPattern       = errors.New("invalid pattern")
+)
+
+// SectionCandidate describes one of several sections matching a query.
+type SectionCandidate struct {
+	Name        string   `json:"name"`
+	HeadingPath []string `json:"heading_path"`
+	Level       string   `json:"level"`
+	StartLine   int      `json:"start_line"`
+	EndLine     int      `json:"end_line"`
+}
+
+// AmbiguousSectionError is returned by ResolveSection in strict mode when a
+// query matches more than one section. It lists every candidate so the
+// caller can re-ask with a heading path or occurrence.
+// errors.Is(err, ErrAmbiguousSection) reports true for this error.
+type AmbiguousSectionError struct {
+	Query      string
+	Candidates []SectionCandidate
+}
+
+// Error lists the candidates with their heading paths and line ranges.
+func (e *AmbiguousSectionError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(
+		&b,
+		"%s: '%s' matches %d sections; use a heading path or occurrence:",
+		ErrAmbiguousSection,
+		e.Query,
+		len(e.Candidates),
+	)
+	for i, candidate := range e.Candidates {
+		fmt.Fprintf(
+			&b,
+			"\n  %d. %s (%s, lines %d-%d)",
+			i+1,
+			strings.Join(candidate.HeadingPath, pathDelimiter),
+			candidate.Level,
+			candidate.StartLine,
+			candidate.EndLine,
+		)
+	}
+	return b.String()
+}
+
+// Is makes errors.Is(err, ErrAmbiguousSection) match this error type.
+func (e *AmbiguousSectionError) Is(target error) bool {
+	return target == ErrAmbiguousSection
+}
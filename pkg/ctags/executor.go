@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"time"
 )
@@ -15,22 +16,43 @@ const (
 	// This prevents hanging on very large files or ctags issues.
 	CtagsExecutionTimeout = 5 * time.Second
 
+	// CtagsBatchTimeout is the maximum time allowed for a single ctags
+	// execution over many files (see ExecuteCtagsBatch).
+	CtagsBatchTimeout = 30 * time.Second
+
 	// CtagsBinary is the name of the ctags executable to search for in PATH.
 	CtagsBinary = "ctags"
 )
 
+// ParserMode selects how markdown headings are extracted from files.
+type ParserMode string
+
+const (
+	// ParserNative uses the pure-Go heading parser (no external binary).
+	ParserNative ParserMode = "native"
+
+	// ParserCtags uses Universal Ctags exclusively.
+	ParserCtags ParserMode = "ctags"
+
+	// ParserAuto uses Universal Ctags when available and falls back to the
+	// native parser when ctags is missing.
+	ParserAuto ParserMode = "auto"
+)
+
 // Config holds the global configuration for ctags execution.
 // This pattern is acceptable for configuration as it provides a single point of
 // coordination for ctags operations throughout the application.
 type Config struct {
-	ctagsPath string
-	mu        sync.RWMutex
+	ctagsPath  string
+	parserMode ParserMode
+	mu         sync.RWMutex
 }
 
 // globalConfig is the singleton configuration instance.
 var globalConfig = &Config{ //nolint:gochecknoglobals // singleton config pattern
-	ctagsPath: CtagsBinary,
-	mu:        sync.RWMutex{},
+	ctagsPath:  CtagsBinary,
+	parserMode: ParserAuto,
+	mu:         sync.RWMutex{},
 }
 
 // SetCtagsPath sets the path to the ctags executable.
@@ -61,6 +83,43 @@ func GetCtagsPath() string {
 	return globalConfig.ctagsPath
 }
 
+// ParseParserMode converts a string ("native", "ctags" or "auto") to a
+// ParserMode. Returns ErrInvalidParserMode for unknown values.
+func ParseParserMode(value string) (ParserMode, error) {
+	mode := ParserMode(value)
+	switch mode {
+	case ParserNative, ParserCtags, ParserAuto:
+		return mode, nil
+	default:
+		return "", fmt.Errorf(
+			"%w: %s (must be 'native', 'ctags' or 'auto')",
+			ErrInvalidParserMode,
+			value,
+		)
+	}
+}
+
+// SetParserMode sets how headings are extracted from markdown files.
+// This should be called once during application initialization.
+func SetParserMode(mode ParserMode) error {
+	if _, err := ParseParserMode(string(mode)); err != nil {
+		return err
+	}
+
+	globalConfig.mu.Lock()
+	globalConfig.parserMode = mode
+	globalConfig.mu.Unlock()
+
+	return nil
+}
+
+// GetParserMode returns the currently configured parser mode.
+func GetParserMode() ParserMode {
+	globalConfig.mu.RLock()
+	defer globalConfig.mu.RUnlock()
+	return globalConfig.parserMode
+}
+
 // ExecuteCtags executes Universal Ctags on a markdown file and returns JSON output.
 // It includes timeout protection, validates that ctags is installed, and checks
 // that the file exists before execution.
@@ -150,6 +209,81 @@ func ExecuteCtags(
 	return output, nil
 }
 
+// ExecuteCtagsBatch executes Universal Ctags once over several markdown
+// files and returns the combined JSON output. File names are passed on
+// standard input, so the number of files is not limited by the command line
+// length. Use GroupJSONTags to split the output by file.
+//
+// The function executes:
+//
+//	ctags --output-format=json --fields=+KnSe --languages=markdown -f - -L -
+//
+// Errors include: ErrCtagsNotFound, ErrCtagsTimeout, ErrCtagsExecution.
+func ExecuteCtagsBatch(
+	ctx context.Context,
+	filePaths []string,
+) ([]byte, error) {
+	// Check context before starting
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context error before execution: %w", err)
+	}
+
+	if len(filePaths) == 0 {
+		return []byte{}, nil
+	}
+
+	ctagsPath := GetCtagsPath()
+	if _, err := exec.LookPath(ctagsPath); err != nil {
+		return nil, fmt.Errorf(
+			"%w: install universal-ctags (https://github.com/universal-ctags/ctags)",
+			ErrCtagsNotFound,
+		)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, CtagsBatchTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(
+		timeoutCtx,
+		ctagsPath,
+		"--output-format=json",
+		"--fields=+KnSe",
+		"--languages=markdown",
+		"-f", "-", // Output to stdout
+		"-L", "-", // Read file names from stdin
+	)
+	cmd.Stdin = strings.NewReader(strings.Join(filePaths, "\n") + "\n")
+
+	output, err := cmd.Output()
+	if err != nil {
+		if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf(
+				"%w: exceeded %v for %d files",
+				ErrCtagsTimeout,
+				CtagsBatchTimeout,
+				len(filePaths),
+			)
+		}
+		if errors.Is(timeoutCtx.Err(), context.Canceled) {
+			return nil, fmt.Errorf("ctags execution canceled: %w", err)
+		}
+
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf(
+				"%w: %w (stderr: %s)",
+				ErrCtagsExecution,
+				err,
+				string(exitErr.Stderr),
+			)
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrCtagsExecution, err)
+	}
+
+	return output, nil
+}
+
 // IsCtagsInstalled checks if Universal Ctags is available at the configured path.
 // This can be used for pre-flight checks or diagnostics.
 func IsCtagsInstalled() bool {
@@ -2,9 +2,10 @@
 // Universal Ctags with intelligent mtime-based caching.
 //
 // The package automatically executes ctags on markdown files and caches
-// the parsed results in memory. Cache invalidation is based on file
-// modification time (mtime), providing fast cache validation with
-// automatic invalidation when files change.
+// the parsed results in memory. When ctags is not installed, a native Go
+// heading parser produces the same TagEntry data (see ParserMode). Cache
+// invalidation is based on file modification time (mtime), providing fast
+// cache validation with automatic invalidation when files change.
 //
 // Performance characteristics:
 //   - Cache hit: ~528ns (mtime check only)
@@ -107,6 +108,53 @@ func ParseJSONTags(jsonData []byte, targetFile string) ([]*TagEntry, error) {
 	return entries, nil
 }
 
+// GroupJSONTags parses ctags JSON output covering several files, such as
+// the output of ExecuteCtagsBatch, and returns the entries of each requested
+// file keyed by the path as given in filePaths. Entries carry that path in
+// their File field. Files without headings map to an empty slice; entries
+// for other files are skipped.
+func GroupJSONTags(
+	jsonData []byte,
+	filePaths []string,
+) (map[string][]*TagEntry, error) {
+	byAbs := make(map[string]string, len(filePaths))
+	grouped := make(map[string][]*TagEntry, len(filePaths))
+	for _, filePath := range filePaths {
+		abs, err := filepath.Abs(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve file path: %w", err)
+		}
+		byAbs[abs] = filePath
+		grouped[filePath] = []*TagEntry{}
+	}
+
+	for _, line := range splitLines(jsonData) {
+		var jsonEntry JSONEntry
+		if err := json.Unmarshal(line, &jsonEntry); err != nil {
+			continue // Metadata or malformed entry
+		}
+		if jsonEntry.Type != "tag" {
+			continue
+		}
+
+		entryAbs, err := filepath.Abs(jsonEntry.Path)
+		if err != nil {
+			entryAbs = jsonEntry.Path
+		}
+		filePath, ok := byAbs[entryAbs]
+		if !ok {
+			continue
+		}
+
+		if entry := jsonEntryToTagEntry(&jsonEntry); entry != nil {
+			entry.File = filePath
+			grouped[filePath] = append(grouped[filePath], entry)
+		}
+	}
+
+	return grouped, nil
+}
+
 // jsonEntryToTagEntry converts a JSONEntry to a TagEntry.
 // Returns nil if the entry has an unknown or invalid kind.
 func jsonEntryToTagEntry(jsonEntry *JSONEntry) *TagEntry {
@@ -125,6 +173,7 @@ func jsonEntryToTagEntry(jsonEntry *JSONEntry) *TagEntry {
 		End:     jsonEntry.End,
 		Scope:   jsonEntry.Scope,
 		Level:   level,
+		Slug:    Slugify(jsonEntry.Name),
 	}
 }
 
@@ -0,0 +1,143 @@
+package ctags
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// inlineDestinationPattern matches the destination of an inline link or
+// image ("[text](dest)", "[text](<dest> "title")") up to its first space
+// or closing parenthesis.
+var inlineDestinationPattern = regexp.MustCompile( //nolint:gochecknoglobals // compiled once
+	`\]\(\s*<?([^\s()<>]*)`,
+)
+
+// referenceDefinitionPattern matches the destination of a link reference
+// definition ("[id]: dest").
+var referenceDefinitionPattern = regexp.MustCompile( //nolint:gochecknoglobals // compiled once
+	`^ {0,3}\[[^\]]+\]:\s*<?([^\s<>]+)`,
+)
+
+// AnchorLink is a link with a fragment found by ScanAnchorLinks, such as
+// "[Setup](#setup)" or "[Setup](guide.md#setup)".
+type AnchorLink struct {
+	Index  int    // Zero-based line index
+	Start  int    // Byte offset of the fragment (after '#') in the line
+	End    int    // Byte offset after the fragment
+	Path   string // Destination before '#', empty within the same document
+	Anchor string // Fragment without '#', as written
+}
+
+// ScanAnchorLinks returns the links with a fragment in a document given as
+// lines, in order: inline links and images and link reference
+// definitions. Links in code blocks, code spans, HTML comments and YAML
+// front matter are skipped, as are links with a URL scheme.
+func ScanAnchorLinks(lines []string) []AnchorLink {
+	var links []AnchorLink
+	scanner := blockScanner{
+		fenceChar:      0,
+		fenceLen:       0,
+		inComment:      false,
+		paragraphOpen:  false,
+		paragraphStart: -1,
+	}
+
+	for i := frontMatterEnd(lines); i < len(lines); i++ {
+		line := lines[i]
+		if scanner.skip(line) {
+			continue
+		}
+		if _, _, ok := parseATXHeading(line); ok {
+			scanner.paragraphOpen = false
+		} else {
+			scanner.trackParagraph(i, line)
+		}
+
+		masked := maskCodeSpans(line)
+		matches := inlineDestinationPattern.FindAllStringSubmatchIndex(
+			masked,
+			-1,
+		)
+		if match := referenceDefinitionPattern.FindStringSubmatchIndex(
+			masked,
+		); match != nil {
+			matches = append(matches, match)
+		}
+
+		for _, match := range matches {
+			if link, ok := newAnchorLink(i, line, match[2], match[3]); ok {
+				links = append(links, link)
+			}
+		}
+	}
+
+	return links
+}
+
+// newAnchorLink describes the destination line[start:end] if it has a
+// fragment and no URL scheme.
+func newAnchorLink(index int, line string, start, end int) (AnchorLink, bool) {
+	destination := line[start:end]
+	hash := strings.IndexByte(destination, '#')
+	if hash < 0 || hash == len(destination)-1 {
+		return AnchorLink{}, false
+	}
+	if parsed, err := url.Parse(destination); err != nil || parsed.Scheme != "" {
+		return AnchorLink{}, false
+	}
+
+	return AnchorLink{
+		Index:  index,
+		Start:  start + hash + 1,
+		End:    end,
+		Path:   destination[:hash],
+		Anchor: destination[hash+1:],
+	}, true
+}
+
+// maskCodeSpans replaces the content of code spans ("`code`") with spaces,
+// keeping byte offsets, so that links inside them are not matched.
+func maskCodeSpans(line string) string {
+	if !strings.Contains(line, "`") {
+		return line
+	}
+
+	masked := []byte(line)
+	for i := 0; i < len(masked); {
+		if masked[i] != '`' {
+			i++
+			continue
+		}
+
+		ticks := 1
+		for i+ticks < len(masked) && masked[i+ticks] == '`' {
+			ticks++
+		}
+		closing := strings.Index(
+			line[i+ticks:],
+			strings.Repeat("`", ticks),
+		)
+		if closing < 0 {
+			i += ticks
+			continue
+		}
+
+		end := i + ticks + closing + ticks
+		for j := i; j < end; j++ {
+			masked[j] = ' '
+		}
+		i = end
+	}
+	return string(masked)
+}
+
+// AnchorKey normalizes a link fragment for comparison with slugs: it is
+// percent-decoded and lowercased, as FindBySlug does.
+func AnchorKey(anchor string) string {
+	anchor = strings.TrimPrefix(anchor, "#")
+	if decoded, err := url.PathUnescape(anchor); err == nil {
+		anchor = decoded
+	}
+	return strings.ToLower(anchor)
+}
@@ -0,0 +1,39 @@
+package ctags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanAnchorLinks(t *testing.T) {
+	lines := []string{
+		"---",
+		"link: [x](#front-matter)",
+		"---",
+		"See [Setup](#setup) and ![img](guide.md#install \"title\").",
+		"Code `[x](#code)` and [web](https://example.com/#top).",
+		"```",
+		"[x](#fenced)",
+		"```",
+		"<!-- [x](#comment) -->",
+		"[ref]: other.md#Caf%C3%A9",
+		"[empty](#) and [page](guide.md)",
+	}
+
+	links := ScanAnchorLinks(lines)
+	require.Len(t, links, 3)
+
+	assert.Equal(t, 3, links[0].Index)
+	assert.Equal(t, "", links[0].Path)
+	assert.Equal(t, "setup", links[0].Anchor)
+	assert.Equal(t, "setup", lines[3][links[0].Start:links[0].End])
+
+	assert.Equal(t, "guide.md", links[1].Path)
+	assert.Equal(t, "install", links[1].Anchor)
+
+	assert.Equal(t, 9, links[2].Index)
+	assert.Equal(t, "other.md", links[2].Path)
+	assert.Equal(t, "café", AnchorKey(links[2].Anchor))
+}
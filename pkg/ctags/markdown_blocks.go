@@ -0,0 +1,440 @@
+package ctags
+
+import "strings"
+
+const (
+	// minFenceLength is the minimum number of backticks or tildes that open
+	// a fenced code block.
+	minFenceLength = 3
+
+	// codeIndent is the indentation width that starts an indented code block.
+	codeIndent = 4
+)
+
+// Heading is a heading found by ScanHeadings.
+type Heading struct {
+	Index          int    // Zero-based index of the (first) heading line
+	UnderlineIndex int    // Index of the setext underline, or -1 for ATX
+	Level          int    // Heading level (1-6)
+	Text           string // Heading text without markers
+	Raw            string // Original (first) heading line
+}
+
+// ScanHeadings returns the headings in a document given as lines, in order.
+// Both ATX ("## Title") and setext ("Title" underlined with === or ---)
+// headings are recognised. Lines inside fenced code blocks (``` or ~~~),
+// indented code blocks, HTML comments (<!-- -->) and YAML front matter are
+// never reported as headings.
+func ScanHeadings(lines []string) []Heading {
+	var headings []Heading
+	scanner := blockScanner{
+		fenceChar:      0,
+		fenceLen:       0,
+		inComment:      false,
+		paragraphOpen:  false,
+		paragraphStart: -1,
+	}
+
+	for i := frontMatterEnd(lines); i < len(lines); i++ {
+		line := lines[i]
+		if scanner.skip(line) {
+			scanner.paragraphStart = -1
+			continue
+		}
+
+		// Setext underline turns the open paragraph into a heading
+		if level, ok := setextLevel(line); ok && scanner.paragraphStart >= 0 {
+			start := scanner.paragraphStart
+			headings = append(headings, Heading{
+				Index:          start,
+				UnderlineIndex: i,
+				Level:          level,
+				Text:           joinParagraph(lines[start:i]),
+				Raw:            lines[start],
+			})
+			scanner.paragraphOpen = false
+			scanner.paragraphStart = -1
+			continue
+		}
+
+		level, text, ok := parseATXHeading(line)
+		if !ok {
+			scanner.trackParagraph(i, line)
+			continue
+		}
+
+		scanner.paragraphOpen = false
+		scanner.paragraphStart = -1
+		if text == "" {
+			continue
+		}
+
+		headings = append(headings, Heading{
+			Index:          i,
+			UnderlineIndex: -1,
+			Level:          level,
+			Text:           text,
+			Raw:            line,
+		})
+	}
+
+	return headings
+}
+
+// UnclosedBlock reports whether a fenced code block or HTML comment opened
+// in lines is still open after the last line, and returns the zero-based
+// index of the line that opened it. Such lines would hide every heading
+// that follows them when inserted into a document. Unlike ScanHeadings,
+// the first lines are never taken for YAML front matter.
+func UnclosedBlock(lines []string) (int, bool) {
+	scanner := blockScanner{
+		fenceChar:      0,
+		fenceLen:       0,
+		inComment:      false,
+		paragraphOpen:  false,
+		paragraphStart: -1,
+	}
+
+	opening := -1
+	for i, line := range lines {
+		inBlock := scanner.inBlock()
+		if scanner.skip(line) {
+			if !inBlock && scanner.inBlock() {
+				opening = i
+			}
+			continue
+		}
+		if _, _, ok := parseATXHeading(line); ok {
+			scanner.paragraphOpen = false
+		} else {
+			scanner.trackParagraph(i, line)
+		}
+	}
+
+	if !scanner.inBlock() {
+		return -1, false
+	}
+	return opening, true
+}
+
+// blockScanner tracks multi-line markdown constructs whose lines can never be
+// headings: fenced code blocks, indented code blocks and HTML comments.
+// It also tracks open paragraphs, which setext underlines turn into headings.
+type blockScanner struct {
+	fenceChar      byte // '`' or '~' while inside a fenced code block
+	fenceLen       int  // Length of the opening fence
+	inComment      bool // Inside an unterminated HTML comment
+	paragraphOpen  bool // Previous line was paragraph text
+	paragraphStart int  // Index of the open setext-eligible paragraph, or -1
+}
+
+// skip reports whether the line belongs to a code block or HTML comment,
+// updating the scanner state for the lines that follow.
+func (s *blockScanner) skip(line string) bool {
+	if s.fenceChar != 0 {
+		if isClosingFence(line, s.fenceChar, s.fenceLen) {
+			s.fenceChar = 0
+			s.fenceLen = 0
+		}
+		return true
+	}
+
+	if s.inComment {
+		if strings.Contains(line, "-->") {
+			s.inComment = false
+		}
+		return true
+	}
+
+	// Indented code cannot interrupt a paragraph
+	if !s.paragraphOpen && isIndentedCode(line) {
+		return true
+	}
+
+	if char, length, ok := openingFence(line); ok {
+		s.fenceChar = char
+		s.fenceLen = length
+		s.paragraphOpen = false
+		return true
+	}
+
+	trimmed, ok := trimBlockIndent(line)
+	if ok && strings.HasPrefix(trimmed, "<!--") {
+		s.inComment = !strings.Contains(trimmed[len("<!--"):], "-->")
+		s.paragraphOpen = false
+		return true
+	}
+
+	return false
+}
+
+// inBlock reports whether the scanner is inside a fenced code block or an
+// HTML comment.
+func (s *blockScanner) inBlock() bool {
+	return s.fenceChar != 0 || s.inComment
+}
+
+// trackParagraph updates paragraph state for a line that is neither a
+// heading nor part of a code block or comment.
+func (s *blockScanner) trackParagraph(index int, line string) {
+	if strings.TrimSpace(line) == "" {
+		s.paragraphOpen = false
+		s.paragraphStart = -1
+		return
+	}
+
+	s.paragraphOpen = true
+	trimmed, unindented := trimBlockIndent(line)
+	switch {
+	case !unindented:
+		// Indented lines only continue an already open paragraph
+	case !isParagraphText(trimmed):
+		// List items, block quotes, tables and thematic breaks cannot
+		// become setext headings
+		s.paragraphStart = -1
+	case s.paragraphStart < 0:
+		s.paragraphStart = index
+	}
+}
+
+// setextLevel reports whether the line is a setext underline and returns the
+// heading level it produces: 1 for "===" and 2 for "---".
+func setextLevel(line string) (int, bool) {
+	trimmed, ok := trimBlockIndent(line)
+	trimmed = strings.TrimRight(trimmed, " \t")
+	if !ok || trimmed == "" {
+		return 0, false
+	}
+
+	char := trimmed[0]
+	if char != '=' && char != '-' {
+		return 0, false
+	}
+	if strings.Trim(trimmed, string(char)) != "" {
+		return 0, false
+	}
+
+	if char == '=' {
+		return 1, true
+	}
+	return 2, true
+}
+
+// isParagraphText reports whether a non-blank, unindented line can be part of
+// a paragraph that a setext underline turns into a heading.
+func isParagraphText(line string) bool {
+	switch {
+	case strings.HasPrefix(line, ">"),
+		strings.HasPrefix(line, "|"),
+		isListItem(line),
+		isThematicBreak(line):
+		return false
+	default:
+		return true
+	}
+}
+
+// isListItem reports whether the (unindented) line starts a list item.
+func isListItem(line string) bool {
+	if len(line) >= 2 && strings.ContainsRune("-*+", rune(line[0])) &&
+		(line[1] == ' ' || line[1] == '\t') {
+		return true
+	}
+
+	digits := 0
+	for digits < len(line) && line[digits] >= '0' && line[digits] <= '9' {
+		digits++
+	}
+	rest := line[digits:]
+	return digits > 0 && len(rest) >= 2 &&
+		(rest[0] == '.' || rest[0] == ')') &&
+		(rest[1] == ' ' || rest[1] == '\t')
+}
+
+// isThematicBreak reports whether the (unindented) line is a thematic break
+// such as "***", "- - -" or "___".
+func isThematicBreak(line string) bool {
+	compact := strings.NewReplacer(" ", "", "\t", "").Replace(line)
+	if len(compact) < minFenceLength {
+		return false
+	}
+
+	char := compact[0]
+	if char != '*' && char != '-' && char != '_' {
+		return false
+	}
+	return strings.Trim(compact, string(char)) == ""
+}
+
+// joinParagraph joins the lines of a setext heading into its text.
+func joinParagraph(lines []string) string {
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		parts = append(parts, strings.TrimSpace(line))
+	}
+	return strings.Join(parts, " ")
+}
+
+// frontMatterEnd returns the index of the first line after a leading YAML
+// front matter block ("---" ... "---"), or 0 if there is none.
+func frontMatterEnd(lines []string) int {
+	if len(lines) == 0 || strings.TrimRight(lines[0], " \t") != "---" {
+		return 0
+	}
+
+	for i := 1; i < len(lines); i++ {
+		closing := strings.TrimRight(lines[i], " \t")
+		if closing == "---" || closing == "..." {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// openingFence reports whether the line opens a fenced code block and
+// returns the fence character and length.
+func openingFence(line string) (char byte, length int, ok bool) {
+	trimmed, ok := trimBlockIndent(line)
+	if !ok || trimmed == "" {
+		return 0, 0, false
+	}
+
+	char = trimmed[0]
+	if char != '`' && char != '~' {
+		return 0, 0, false
+	}
+
+	for length < len(trimmed) && trimmed[length] == char {
+		length++
+	}
+	if length < minFenceLength {
+		return 0, 0, false
+	}
+
+	// Backtick fences may not have backticks in the info string
+	if char == '`' && strings.ContainsRune(trimmed[length:], '`') {
+		return 0, 0, false
+	}
+
+	return char, length, true
+}
+
+// isClosingFence reports whether the line closes a fenced code block opened
+// with the given character and length.
+func isClosingFence(line string, char byte, openLen int) bool {
+	trimmed, ok := trimBlockIndent(line)
+	if !ok {
+		return false
+	}
+
+	length := 0
+	for length < len(trimmed) && trimmed[length] == char {
+		length++
+	}
+
+	return length >= openLen && strings.TrimSpace(trimmed[length:]) == ""
+}
+
+// isIndentedCode reports whether a non-blank line is indented enough to be
+// part of an indented code block.
+func isIndentedCode(line string) bool {
+	if strings.TrimSpace(line) == "" {
+		return false
+	}
+
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += codeIndent - width%codeIndent
+		default:
+			return width >= codeIndent
+		}
+		if width >= codeIndent {
+			return true
+		}
+	}
+	return false
+}
+
+// trimBlockIndent strips up to three leading spaces. It returns false if the
+// line is indented further (and therefore cannot start a block construct).
+func trimBlockIndent(line string) (string, bool) {
+	indent := 0
+	for indent < len(line) && line[indent] == ' ' {
+		indent++
+	}
+	if indent > maxATXIndent {
+		return "", false
+	}
+	return line[indent:], true
+}
+
+// ExcludeBlockRegions removes entries whose line is not a real heading in the
+// given document lines, such as "# comment" lines inside fenced code blocks
+// or HTML comments reported by ctags. When entries are removed, the End and
+// Scope fields of the remaining entries are recomputed to match.
+// Entries must be sorted by line.
+func ExcludeBlockRegions(entries []*TagEntry, lines []string) []*TagEntry {
+	headingLines := make(map[int]bool)
+	for _, heading := range ScanHeadings(lines) {
+		headingLines[heading.Index+1] = true
+	}
+
+	kept := make([]*TagEntry, 0, len(entries))
+	for _, entry := range entries {
+		if headingLines[entry.Line] {
+			kept = append(kept, entry)
+		}
+	}
+
+	if len(kept) != len(entries) {
+		assignScopes(kept)
+		assignSectionEnds(kept, len(lines))
+	}
+
+	return kept
+}
+
+// AddSetextHeadings adds setext headings ("Title" underlined with === or ---)
+// that are missing from entries, so that documents using them produce the
+// same sections regardless of the parser. When entries are added, the End
+// and Scope fields are recomputed. Entries must be sorted by line.
+func AddSetextHeadings(
+	entries []*TagEntry,
+	lines []string,
+	filePath string,
+) []*TagEntry {
+	known := make(map[int]bool, len(entries))
+	for _, entry := range entries {
+		known[entry.Line] = true
+	}
+
+	added := false
+	for _, heading := range ScanHeadings(lines) {
+		if heading.UnderlineIndex < 0 || known[heading.Index+1] {
+			continue
+		}
+
+		entries = append(entries, NewTagEntry(
+			heading.Text,
+			filePath,
+			headingPattern(heading.Raw),
+			kindForLevel(heading.Level),
+			heading.Index+1,
+			0,
+			"",
+		))
+		added = true
+	}
+
+	if added {
+		SortByLine(entries)
+		assignScopes(entries)
+		assignSectionEnds(entries, len(lines))
+	}
+
+	return entries
+}
@@ -0,0 +1,233 @@
+package ctags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanHeadings_IgnoresFencedCode(t *testing.T) {
+	lines := []string{
+		"# Setup",
+		"",
+		"```bash",
+		"# install dependencies",
+		"make deps",
+		"```",
+		"",
+		"~~~~",
+		"## Not a heading",
+		"~~~",
+		"still code",
+		"~~~~",
+		"## Usage",
+	}
+
+	headings := ScanHeadings(lines)
+	require.Len(t, headings, 2)
+	assert.Equal(t, "Setup", headings[0].Text)
+	assert.Equal(t, 0, headings[0].Index)
+	assert.Equal(t, "Usage", headings[1].Text)
+	assert.Equal(t, 12, headings[1].Index)
+}
+
+func TestScanHeadings_IgnoresHTMLComments(t *testing.T) {
+	lines := []string{
+		"# Design",
+		"<!--",
+		"## Draft section",
+		"-->",
+		"<!-- # single line comment -->",
+		"## Final section",
+	}
+
+	headings := ScanHeadings(lines)
+	require.Len(t, headings, 2)
+	assert.Equal(t, "Design", headings[0].Text)
+	assert.Equal(t, "Final section", headings[1].Text)
+}
+
+func TestScanHeadings_IgnoresIndentedCode(t *testing.T) {
+	lines := []string{
+		"# Shell",
+		"",
+		"    # comment in indented code",
+		"\t# tab indented code",
+		"",
+		"## Next",
+	}
+
+	headings := ScanHeadings(lines)
+	require.Len(t, headings, 2)
+	assert.Equal(t, "Shell", headings[0].Text)
+	assert.Equal(t, "Next", headings[1].Text)
+}
+
+func TestScanHeadings_UnclosedFenceRunsToEnd(t *testing.T) {
+	lines := []string{"# Title", "```", "# hidden", "## hidden too"}
+
+	headings := ScanHeadings(lines)
+	require.Len(t, headings, 1)
+	assert.Equal(t, "Title", headings[0].Text)
+}
+
+func TestUnclosedBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected int
+		open     bool
+	}{
+		{"no blocks", []string{"# Title", "text"}, -1, false},
+		{"closed fence", []string{"```go", "x", "```", "text"}, -1, false},
+		{"open fence", []string{"text", "", "```", "x"}, 2, true},
+		{"shorter closing fence", []string{"````", "```"}, 0, true},
+		{"open tilde fence", []string{"~~~", "```", "```"}, 0, true},
+		{"closed comment", []string{"<!-- a", "b -->", "text"}, -1, false},
+		{"one-line comment", []string{"<!-- note -->"}, -1, false},
+		{"open comment", []string{"```", "```", "<!-- a", "b"}, 2, true},
+		{"comment in fence", []string{"```", "<!--", "```"}, -1, false},
+		{"fence in comment", []string{"<!--", "```", "-->"}, -1, false},
+		{"thematic break first", []string{"---", "```", "x"}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opening, open := UnclosedBlock(tt.lines)
+			assert.Equal(t, tt.open, open)
+			assert.Equal(t, tt.expected, opening)
+		})
+	}
+}
+
+func TestParseMarkdown_RecomputesEndsAroundCode(t *testing.T) {
+	content := "# Guide\n\n## Install\n\n```sh\n# build\ngo build\n```\n\n" +
+		"## Run\n\ntext\n"
+
+	entries := ParseMarkdown([]byte(content), "test.md")
+	require.Len(t, entries, 3)
+	assert.Equal(t, "Install", entries[1].Name)
+	assert.Equal(t, 3, entries[1].Line)
+	assert.Equal(t, 9, entries[1].End)
+	assert.Equal(t, "Run", entries[2].Name)
+	assert.Equal(t, 12, entries[2].End)
+}
+
+func TestExcludeBlockRegions(t *testing.T) {
+	lines := []string{
+		"# Guide",
+		"```",
+		"# comment",
+		"```",
+		"## Install",
+		"text",
+	}
+
+	// Simulate ctags reporting the comment line as an H1
+	entries := []*TagEntry{
+		{Name: "Guide", Line: 1, End: 1, Level: 1},
+		{Name: "comment", Line: 3, End: 6, Level: 1},
+		{Name: "Install", Line: 5, End: 6, Level: 2, Scope: "comment"},
+	}
+
+	kept := ExcludeBlockRegions(entries, lines)
+	require.Len(t, kept, 2)
+	assert.Equal(t, "Guide", kept[0].Name)
+	assert.Equal(t, 6, kept[0].End)
+	assert.Equal(t, "Install", kept[1].Name)
+	assert.Equal(t, "Guide", kept[1].Scope)
+}
+
+func TestScanHeadings_Setext(t *testing.T) {
+	lines := []string{
+		"Project Title",
+		"=============",
+		"",
+		"Intro text.",
+		"",
+		"Installation",
+		"------------",
+		"",
+		"## Usage",
+	}
+
+	headings := ScanHeadings(lines)
+	require.Len(t, headings, 3)
+
+	assert.Equal(t, "Project Title", headings[0].Text)
+	assert.Equal(t, 1, headings[0].Level)
+	assert.Equal(t, 0, headings[0].Index)
+	assert.Equal(t, 1, headings[0].UnderlineIndex)
+
+	assert.Equal(t, "Installation", headings[1].Text)
+	assert.Equal(t, 2, headings[1].Level)
+	assert.Equal(t, 5, headings[1].Index)
+
+	assert.Equal(t, "Usage", headings[2].Text)
+	assert.Equal(t, -1, headings[2].UnderlineIndex)
+}
+
+func TestScanHeadings_SetextNotHeadings(t *testing.T) {
+	lines := []string{
+		"---",
+		"title: Front Matter",
+		"---",
+		"",
+		"---",
+		"",
+		"- list item",
+		"---",
+		"",
+		"> quote",
+		"---",
+		"",
+		"```",
+		"code",
+		"---",
+		"```",
+	}
+
+	assert.Empty(t, ScanHeadings(lines))
+}
+
+func TestScanHeadings_SetextMultilineParagraph(t *testing.T) {
+	lines := []string{"A long", "heading text", "==="}
+
+	headings := ScanHeadings(lines)
+	require.Len(t, headings, 1)
+	assert.Equal(t, "A long heading text", headings[0].Text)
+	assert.Equal(t, 0, headings[0].Index)
+	assert.Equal(t, 2, headings[0].UnderlineIndex)
+}
+
+func TestParseMarkdown_SetextHierarchy(t *testing.T) {
+	content := "Title\n=====\n\nSetup\n-----\n\n### Details\n\ntext\n\n" +
+		"Usage\n-----\n"
+
+	entries := ParseMarkdown([]byte(content), "test.md")
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, "Title", entries[0].Name)
+	assert.Equal(t, 1, entries[0].Level)
+	assert.Equal(t, 12, entries[0].End)
+	assert.Equal(t, "Setup", entries[1].Name)
+	assert.Equal(t, 4, entries[1].Line)
+	assert.Equal(t, 10, entries[1].End)
+	assert.Equal(t, `Title""Setup`, entries[2].Scope)
+	assert.Equal(t, "Usage", entries[3].Name)
+	assert.Equal(t, 11, entries[3].Line)
+}
+
+func TestAddSetextHeadings(t *testing.T) {
+	lines := []string{"# Doc", "", "Part", "----", "text"}
+	entries := []*TagEntry{{Name: "Doc", Line: 1, End: 5, Level: 1}}
+
+	result := AddSetextHeadings(entries, lines, "test.md")
+	require.Len(t, result, 2)
+	assert.Equal(t, "Part", result[1].Name)
+	assert.Equal(t, 2, result[1].Level)
+	assert.Equal(t, 3, result[1].Line)
+	assert.Equal(t, 5, result[1].End)
+	assert.Equal(t, "Doc", result[1].Scope)
+}
@@ -0,0 +1,173 @@
+package ctags
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MatchMode selects how a heading name is compared with a query.
+type MatchMode string
+
+const (
+	// MatchExact requires the whole heading name to equal the query.
+	MatchExact MatchMode = "exact"
+
+	// MatchPrefix requires the heading name to start with the query.
+	MatchPrefix MatchMode = "prefix"
+
+	// MatchSubstring requires the heading name to contain the query.
+	MatchSubstring MatchMode = "substring"
+
+	// MatchRegex treats the query as an RE2 regular expression that must
+	// match somewhere in the heading name (use ^ and $ to anchor it).
+	MatchRegex MatchMode = "regex"
+
+	// MatchGlob treats the query as a shell-style glob ("Task *", "Phase ?")
+	// that must match the whole heading name.
+	MatchGlob MatchMode = "glob"
+)
+
+// ParseMatchMode converts a string to a MatchMode. An empty string selects
+// MatchSubstring. Returns ErrInvalidMatchMode for unknown values.
+func ParseMatchMode(value string) (MatchMode, error) {
+	if value == "" {
+		return MatchSubstring, nil
+	}
+
+	mode := MatchMode(value)
+	switch mode {
+	case MatchExact, MatchPrefix, MatchSubstring, MatchRegex, MatchGlob:
+		return mode, nil
+	default:
+		return "", fmt.Errorf(
+			"%w: %s (must be 'exact', 'prefix', 'substring', 'regex' or 'glob')",
+			ErrInvalidMatchMode,
+			value,
+		)
+	}
+}
+
+// NameMatcher matches heading names against a query using a MatchMode.
+// Create it with NewNameMatcher so that patterns are validated once.
+type NameMatcher struct {
+	mode          MatchMode
+	query         string
+	caseSensitive bool
+	re            *regexp.Regexp // Compiled pattern for regex and glob modes
+}
+
+// NewNameMatcher creates a matcher for the query. Comparison is
+// case-insensitive unless caseSensitive is set. For the literal modes
+// (exact, prefix, substring) surrounding whitespace is ignored. An empty
+// mode behaves like MatchSubstring.
+//
+// Errors include: ErrInvalidMatchMode, ErrInvalidPattern.
+func NewNameMatcher(
+	mode MatchMode,
+	query string,
+	caseSensitive bool,
+) (*NameMatcher, error) {
+	if mode == "" {
+		mode = MatchSubstring
+	}
+
+	matcher := &NameMatcher{
+		mode:          mode,
+		query:         strings.TrimSpace(query),
+		caseSensitive: caseSensitive,
+		re:            nil,
+	}
+
+	var expr string
+	switch mode {
+	case MatchExact, MatchPrefix, MatchSubstring:
+		if !caseSensitive {
+			matcher.query = strings.ToLower(matcher.query)
+		}
+		return matcher, nil
+	case MatchRegex:
+		expr = query
+	case MatchGlob:
+		expr = globToRegexp(strings.TrimSpace(query))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMatchMode, mode)
+	}
+
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w '%s': %w", ErrInvalidPattern, query, err)
+	}
+	matcher.re = re
+
+	return matcher, nil
+}
+
+// Match reports whether the heading name matches.
+func (m *NameMatcher) Match(name string) bool {
+	if m.re != nil {
+		return m.re.MatchString(strings.TrimSpace(name))
+	}
+
+	name = strings.TrimSpace(name)
+	if !m.caseSensitive {
+		name = strings.ToLower(name)
+	}
+
+	switch m.mode {
+	case MatchExact:
+		return name == m.query
+	case MatchPrefix:
+		return strings.HasPrefix(name, m.query)
+	case MatchSubstring, MatchRegex, MatchGlob:
+		return strings.Contains(name, m.query)
+	default:
+		return strings.Contains(name, m.query)
+	}
+}
+
+// globToRegexp converts a shell-style glob into an anchored regular
+// expression. '*' matches any run of characters, '?' a single character and
+// "[...]" a character class ("[!...]" negates it). Other characters,
+// including '/', match literally.
+func globToRegexp(glob string) string {
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	for i := 0; i < len(glob); i++ {
+		switch char := glob[i]; char {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			} else {
+				expr.WriteString(`\\`)
+			}
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+
+	expr.WriteString("$")
+	return expr.String()
+}
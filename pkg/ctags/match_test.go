@@ -0,0 +1,171 @@
+package ctags
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatchMode(t *testing.T) {
+	mode, err := ParseMatchMode("")
+	require.NoError(t, err)
+	assert.Equal(t, MatchSubstring, mode)
+
+	for _, value := range []string{
+		"exact", "prefix", "substring", "regex", "glob",
+	} {
+		mode, err = ParseMatchMode(value)
+		require.NoError(t, err)
+		assert.Equal(t, MatchMode(value), mode)
+	}
+
+	_, err = ParseMatchMode("fuzzy")
+	require.ErrorIs(t, err, ErrInvalidMatchMode)
+}
+
+func TestNameMatcher(t *testing.T) {
+	tests := []struct {
+		mode          MatchMode
+		name          string
+		query         string
+		caseSensitive bool
+		want          bool
+	}{
+		{MatchExact, "Acceptance Criteria", "acceptance criteria", false, true},
+		{MatchExact, "Acceptance Criteria (v2)", "Acceptance Criteria", false, false},
+		{MatchPrefix, "Task 2.1: Parser", "task 2.1", false, true},
+		{MatchPrefix, "Subtask 2.1", "Task 2.1", false, false},
+		{MatchSubstring, "Subtask 2.1", "task 2.1", false, true},
+		{MatchSubstring, "Task 2.1", "task 2.1", true, false},
+		{MatchSubstring, "Overview", "Testing", false, false},
+		{MatchRegex, "Task 4: Cache", "Task.*", false, true},
+		{MatchRegex, "Subtask 4", "^Task", false, false},
+		{MatchRegex, "task 4", "^Task", true, false},
+		{MatchRegex, "Task 12", `^Task \d+$`, false, true},
+		{MatchGlob, "Task 4: Cache", "task *", false, true},
+		{MatchGlob, "Subtask 4", "Task *", false, false},
+		{MatchGlob, "Phase 2", "Phase ?", false, true},
+		{MatchGlob, "Phase 2", "Phase [13]", false, false},
+		{MatchGlob, "Phase 2", "Phase [!13]", false, true},
+		{MatchGlob, "C++ (draft)", "C++ (*)", false, true},
+	}
+
+	for _, tt := range tests {
+		matcher, err := NewNameMatcher(tt.mode, tt.query, tt.caseSensitive)
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			tt.want,
+			matcher.Match(tt.name),
+			"%s: %q vs %q",
+			tt.mode,
+			tt.name,
+			tt.query,
+		)
+	}
+}
+
+func TestNewNameMatcher_InvalidPattern(t *testing.T) {
+	_, err := NewNameMatcher(MatchRegex, "Task (", false)
+	require.ErrorIs(t, err, ErrInvalidPattern)
+
+	_, err = NewNameMatcher(MatchMode("fuzzy"), "Task", false)
+	require.ErrorIs(t, err, ErrInvalidMatchMode)
+
+	// Regex metacharacters are literal in substring mode
+	matcher, err := NewNameMatcher(MatchSubstring, "Task (", false)
+	require.NoError(t, err)
+	assert.True(t, matcher.Match("Task (draft)"))
+}
+
+func TestFilterByMatcher_Regex(t *testing.T) {
+	entries := ParseMarkdown(
+		[]byte("# Plan\n## Task 1\n### Notes\n## Subtask list\n## Task 2\n"),
+		"plan.md",
+	)
+
+	matcher, err := NewNameMatcher(MatchRegex, "^Task.*", false)
+	require.NoError(t, err)
+
+	filtered := FilterByMatcher(entries, matcher)
+	require.Len(t, filtered, 2)
+	assert.Equal(t, "Task 1", filtered[0].Name)
+	assert.Equal(t, "Task 2", filtered[1].Name)
+
+	matcher, err = NewNameMatcher(MatchGlob, "notes", false)
+	require.NoError(t, err)
+
+	withParents := FilterByMatcherWithParents(entries, matcher)
+	require.Len(t, withParents, 3)
+	assert.Equal(t, "Plan", withParents[0].Name)
+	assert.Equal(t, "Task 1", withParents[1].Name)
+	assert.Equal(t, "Notes", withParents[2].Name)
+
+	// The legacy helper keeps its substring semantics
+	assert.Empty(t, FilterByPattern(entries, "Task.*"))
+}
+
+func TestResolveSection_StrictAmbiguity(t *testing.T) {
+	entries := createPlanEntries()
+	query := ParseSectionQuery("Testing", 0)
+	query.Strict = true
+
+	_, err := ResolveSection(entries, query)
+	require.ErrorIs(t, err, ErrAmbiguousSection)
+
+	var ambiguous *AmbiguousSectionError
+	require.True(t, errors.As(err, &ambiguous))
+	require.Len(t, ambiguous.Candidates, 3)
+
+	candidate := ambiguous.Candidates[1]
+	assert.Equal(t, "Testing", candidate.Name)
+	assert.Equal(
+		t,
+		[]string{"Plan", "Phase 2", "Task 2.1", "Testing"},
+		candidate.HeadingPath,
+	)
+	assert.Equal(t, "H4", candidate.Level)
+	assert.Equal(t, 7, candidate.StartLine)
+	assert.Equal(t, 7, candidate.EndLine)
+	assert.Contains(t, err.Error(), "Plan > Phase 2 > Task 2.1 > Testing (H4, lines 7-7)")
+
+	// A unique match is not ambiguous in strict mode
+	query = ParseSectionQuery("Task 2.1 > Testing", 0)
+	query.Strict = true
+	entry, err := ResolveSection(entries, query)
+	require.NoError(t, err)
+	assert.Equal(t, 7, entry.Line)
+}
+
+func TestFindSections_MatchModes(t *testing.T) {
+	entries := ParseMarkdown(
+		[]byte("# Criteria\n## Acceptance Criteria\n## Criteria Notes\n"),
+		"test.md",
+	)
+
+	query := ParseSectionQuery("criteria", 0)
+	assert.Len(t, FindSections(entries, query), 3)
+
+	query.Mode = MatchPrefix
+	assert.Len(t, FindSections(entries, query), 2)
+
+	query.Mode = MatchExact
+	matches := FindSections(entries, query)
+	require.Len(t, matches, 1)
+	assert.Equal(t, 1, matches[0].Line)
+
+	query.CaseSensitive = true
+	assert.Empty(t, FindSections(entries, query))
+
+	query = ParseSectionQuery("Criteria$", 0)
+	query.Mode = MatchRegex
+	assert.Len(t, FindSections(entries, query), 2)
+
+	query = ParseSectionQuery("Criteria (", 0)
+	query.Mode = MatchRegex
+	assert.Empty(t, FindSections(entries, query))
+	_, err := ResolveSection(entries, query)
+	require.ErrorIs(t, err, ErrInvalidPattern)
+}
@@ -0,0 +1,192 @@
+package ctags
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// maxATXIndent is the maximum number of leading spaces allowed before
+	// an ATX heading marker (CommonMark).
+	maxATXIndent = 3
+
+	// maxHeadingLevel is the deepest heading level supported by markdown.
+	maxHeadingLevel = 6
+
+	// scopeSeparator separates parent names in TagEntry.Scope.
+	// This matches the separator used by Universal Ctags' markdown parser.
+	scopeSeparator = `""`
+)
+
+// ParseMarkdownFile reads a markdown file and extracts its headings with the
+// native Go parser. It is the pure-Go counterpart of ExecuteCtags followed by
+// ParseJSONTags and needs no external binary.
+//
+// Errors include: ErrFileNotFound.
+func ParseMarkdownFile(
+	ctx context.Context,
+	filePath string,
+) ([]*TagEntry, error) {
+	// Check context before starting
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("context error before parsing: %w", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filePath)
+		}
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return ParseMarkdown(content, filePath), nil
+}
+
+// ParseMarkdown extracts headings from markdown content and returns them as
+// TagEntry structs in document order. The entries carry the same fields that
+// ParseJSONTags produces from ctags output: Name, Line, End, Scope and Level,
+// plus unique GitHub-style slugs.
+// Headings inside code blocks and HTML comments are ignored (see
+// ScanHeadings).
+//
+// End is the line before the next heading of the same or higher level, or the
+// last line of the content for the final section.
+//
+// Returns an empty slice if the content has no headings.
+func ParseMarkdown(content []byte, filePath string) []*TagEntry {
+	lines := SplitContentLines(content)
+	headings := ScanHeadings(lines)
+
+	entries := make([]*TagEntry, 0, len(headings))
+	for _, heading := range headings {
+		entries = append(entries, NewTagEntry(
+			heading.Text,
+			filePath,
+			headingPattern(heading.Raw),
+			kindForLevel(heading.Level),
+			heading.Index+1,
+			0,
+			"",
+		))
+	}
+
+	assignScopes(entries)
+	assignSectionEnds(entries, len(lines))
+	AssignSlugs(entries)
+
+	return entries
+}
+
+// parseATXHeading parses an ATX heading line ("## Title").
+// It follows CommonMark rules: up to three spaces of indentation, one to six
+// '#' characters followed by whitespace or end of line, and an optional
+// closing sequence of '#' characters.
+func parseATXHeading(line string) (level int, text string, ok bool) {
+	indent := 0
+	for indent < len(line) && line[indent] == ' ' {
+		indent++
+	}
+	if indent > maxATXIndent {
+		return 0, "", false
+	}
+
+	rest := line[indent:]
+	for level < len(rest) && rest[level] == '#' {
+		level++
+	}
+	if level == 0 || level > maxHeadingLevel {
+		return 0, "", false
+	}
+
+	rest = rest[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false // "#hashtag" is not a heading
+	}
+
+	text = strings.TrimSpace(rest)
+
+	// Strip optional closing sequence ("## Title ##")
+	withoutClosing := strings.TrimRight(text, "#")
+	switch {
+	case withoutClosing == "":
+		text = ""
+	case strings.HasSuffix(withoutClosing, " "),
+		strings.HasSuffix(withoutClosing, "\t"):
+		text = strings.TrimSpace(withoutClosing)
+	}
+
+	return level, text, true
+}
+
+// assignSectionEnds sets the End field of every entry to the line before the
+// next entry with the same or higher level, or to lastLine when the section
+// runs to the end of the document. Entries must be sorted by line.
+func assignSectionEnds(entries []*TagEntry, lastLine int) {
+	for i, entry := range entries {
+		entry.End = lastLine
+		for _, next := range entries[i+1:] {
+			if next.Level <= entry.Level {
+				entry.End = next.Line - 1
+				break
+			}
+		}
+	}
+}
+
+// assignScopes sets the Scope field of every entry to the names of its
+// ancestors joined with the ctags scope separator. Entries must be sorted by
+// line.
+func assignScopes(entries []*TagEntry) {
+	var stack []*TagEntry // Open ancestors of the current entry
+
+	for _, entry := range entries {
+		// Pop stack to the parent of this entry
+		for len(stack) > 0 && stack[len(stack)-1].Level >= entry.Level {
+			stack = stack[:len(stack)-1]
+		}
+
+		names := make([]string, 0, len(stack))
+		for _, parent := range stack {
+			names = append(names, parent.Name)
+		}
+		entry.Scope = strings.Join(names, scopeSeparator)
+
+		stack = append(stack, entry)
+	}
+}
+
+// kindForLevel returns the ctags kind for a heading level (inverse of
+// kindLevelMap).
+func kindForLevel(level int) string {
+	for kind, kindLevel := range kindLevelMap {
+		if kindLevel == level {
+			return kind
+		}
+	}
+	return ""
+}
+
+// headingPattern builds a ctags-style search pattern for a heading line.
+func headingPattern(line string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `/`, `\/`).Replace(line)
+	return "/^" + escaped + "$/"
+}
+
+// SplitContentLines splits file content into lines, dropping line endings
+// ("\n" or "\r\n").
+// A trailing newline does not produce an extra empty line.
+func SplitContentLines(content []byte) []string {
+	if len(content) == 0 {
+		return []string{}
+	}
+
+	text := strings.TrimSuffix(string(content), "\n")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
@@ -0,0 +1,142 @@
+package ctags
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarkdown_SampleFile(t *testing.T) {
+	targetFile := filepath.Join("..", "..", "testdata", "sample.md")
+
+	entries, err := ParseMarkdownFile(context.Background(), targetFile)
+	require.NoError(t, err)
+	require.Len(t, entries, 10)
+
+	expected := []struct {
+		name  string
+		level int
+		line  int
+		end   int
+	}{
+		{"Test Document", 1, 1, 47},
+		{"Section 1: Introduction", 2, 5, 20},
+		{"Subsection 1.1: Background", 3, 10, 13},
+		{"Subsection 1.2: Goals", 3, 14, 20},
+		{"Section 2: Implementation", 2, 21, 43},
+		{"Subsection 2.1: Architecture", 3, 25, 31},
+		{"Subsection 2.2: Testing", 3, 32, 43},
+		{"Deep Section 2.2.1: Unit Tests", 4, 36, 39},
+		{"Deep Section 2.2.2: Integration Tests", 4, 40, 43},
+		{"Section 3: Conclusion", 2, 44, 47},
+	}
+
+	for i, exp := range expected {
+		assert.Equal(t, exp.name, entries[i].Name)
+		assert.Equal(t, exp.level, entries[i].Level, exp.name)
+		assert.Equal(t, exp.line, entries[i].Line, exp.name)
+		assert.Equal(t, exp.end, entries[i].End, exp.name)
+		assert.Equal(t, targetFile, entries[i].File)
+	}
+}
+
+func TestParseMarkdown_ScopeAndKind(t *testing.T) {
+	content := "# Root\n## Child\n### Grandchild\n## Sibling\n"
+
+	entries := ParseMarkdown([]byte(content), "test.md")
+	require.Len(t, entries, 4)
+
+	assert.Empty(t, entries[0].Scope)
+	assert.Equal(t, "chapter", entries[0].Kind)
+	assert.Equal(t, "Root", entries[1].Scope)
+	assert.Equal(t, "section", entries[1].Kind)
+	assert.Equal(t, `Root""Child`, entries[2].Scope)
+	assert.Equal(t, "subsection", entries[2].Kind)
+	assert.Equal(t, "Root", entries[3].Scope)
+	assert.Equal(t, "/^## Child$/", entries[1].Pattern)
+}
+
+func TestParseMarkdown_AllHeadingLevels(t *testing.T) {
+	content := "# H1\n## H2\n### H3\n#### H4\n##### H5\n###### H6\n"
+
+	entries := ParseMarkdown([]byte(content), "test.md")
+	require.Len(t, entries, 6)
+
+	for i, entry := range entries {
+		assert.Equal(t, i+1, entry.Level)
+		assert.Equal(t, i+1, entry.Line)
+		assert.Equal(t, kindForLevel(i+1), entry.Kind)
+	}
+}
+
+func TestParseMarkdown_EmptyAndNoHeadings(t *testing.T) {
+	assert.Empty(t, ParseMarkdown([]byte(""), "test.md"))
+	assert.Empty(t, ParseMarkdown([]byte("just text\nmore text\n"), "test.md"))
+}
+
+func TestParseATXHeading(t *testing.T) {
+	tests := []struct {
+		line  string
+		level int
+		text  string
+		ok    bool
+	}{
+		{"# Title", 1, "Title", true},
+		{"###   Spaced   ", 3, "Spaced", true},
+		{"## Closed ##", 2, "Closed", true},
+		{"## C# Language", 2, "C# Language", true},
+		{"   # Indented", 1, "Indented", true},
+		{"    # Code", 0, "", false},
+		{"#hashtag", 0, "", false},
+		{"####### Seven", 0, "", false},
+		{"#", 1, "", true},
+		{"plain text", 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			level, text, ok := parseATXHeading(tt.line)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.level, level)
+			assert.Equal(t, tt.text, text)
+		})
+	}
+}
+
+func TestParseMarkdownFile_FileNotFound(t *testing.T) {
+	_, err := ParseMarkdownFile(context.Background(), "/nonexistent/file.md")
+	require.ErrorIs(t, err, ErrFileNotFound)
+}
+
+func TestParseParserMode(t *testing.T) {
+	for _, value := range []string{"native", "ctags", "auto"} {
+		mode, err := ParseParserMode(value)
+		require.NoError(t, err)
+		assert.Equal(t, ParserMode(value), mode)
+	}
+
+	_, err := ParseParserMode("treesitter")
+	require.ErrorIs(t, err, ErrInvalidParserMode)
+}
+
+func TestCacheManager_NativeParser(t *testing.T) {
+	original := GetParserMode()
+	require.NoError(t, SetParserMode(ParserNative))
+	t.Cleanup(func() {
+		_ = SetParserMode(original)
+	})
+
+	cache := NewCacheManager()
+	file := createTestMarkdownFile(t, "# Test\n\n## Section\n\ntext\n")
+
+	tags, err := cache.GetTags(context.Background(), file)
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	assert.Equal(t, "Test", tags[0].Name)
+	assert.Equal(t, 5, tags[0].End)
+	assert.Equal(t, "Section", tags[1].Name)
+	assert.Equal(t, 3, tags[1].Line)
+}
@@ -0,0 +1,363 @@
+package ctags
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// BM25 parameters: term frequency saturation and document length
+// normalization, with the common defaults.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// headingWeight is how often a term in the heading counts towards the
+// term frequency of its section.
+const headingWeight = 2
+
+// minStemLength is the shortest stem left by stripping a suffix.
+const minStemLength = 3
+
+// SectionScore is a section ranked by RankSections.
+type SectionScore struct {
+	FilePath string
+	Entry    *TagEntry
+	Score    float64  // BM25 score, higher is more relevant
+	Terms    []string // Query terms found in the section
+}
+
+// RankedSections is the result of RankSections.
+type RankedSections struct {
+	Terms    []string       // Normalized query terms
+	Sections []SectionScore // Best sections first, at most the limit
+	Matched  int            // Sections containing at least one query term
+	Searched int            // Sections ranked against the query
+}
+
+// sectionIndex is an inverted index over the text of cached sections.
+// Each file's postings belong to the cache entry they were built from and
+// are dropped with it, so that the index never outlives the headings the
+// CacheManager serves and stays within the cache limits.
+type sectionIndex struct {
+	mu    sync.RWMutex
+	files map[string]*indexedFile // By cache key
+}
+
+// indexedFile holds the postings of the sections of one cached file.
+type indexedFile struct {
+	tags     []*TagEntry          // Cached headings the postings refer to
+	lengths  []int                // Terms per section, by tags index
+	total    int                  // Terms across all sections
+	postings map[string][]posting // Sections containing each term
+}
+
+// posting records how often a term occurs in a section.
+type posting struct {
+	section   int // Index into indexedFile.tags
+	frequency int
+}
+
+// newSectionIndex creates an empty index.
+func newSectionIndex() *sectionIndex {
+	return &sectionIndex{
+		mu:    sync.RWMutex{},
+		files: make(map[string]*indexedFile),
+	}
+}
+
+// has reports whether the postings of a file were built from tags.
+func (idx *sectionIndex) has(filePath string, tags []*TagEntry) bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	file, ok := idx.files[filePath]
+	return ok && sameTags(file.tags, tags)
+}
+
+// put stores the postings of a file.
+func (idx *sectionIndex) put(filePath string, file *indexedFile) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.files[filePath] = file
+}
+
+// remove drops the postings of a file.
+func (idx *sectionIndex) remove(filePath string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	delete(idx.files, filePath)
+}
+
+// clear drops all postings.
+func (idx *sectionIndex) clear() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.files = make(map[string]*indexedFile)
+}
+
+// IndexSections makes the text of a file's sections searchable by
+// RankSections. The headings come from GetTags, so a changed file is
+// parsed and indexed again; an unchanged file is not read at all.
+//
+// Errors include: ErrFileNotFound.
+func (cm *CacheManager) IndexSections(
+	ctx context.Context,
+	filePath string,
+) error {
+	tags, err := cm.GetTags(ctx, filePath)
+	if err != nil {
+		return err
+	}
+	if cm.sections.has(filePath, tags) {
+		return nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	file := newIndexedFile(tags, SplitContentLines(content))
+
+	// Only index headings that are still cached: postings of replaced or
+	// evicted entries would never be dropped
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if entry, ok := cm.cache[filePath]; ok && sameTags(entry.Tags, tags) {
+		cm.sections.put(filePath, file)
+	}
+	return nil
+}
+
+// RankSections scores the indexed sections of the given files against a
+// free-text query with BM25 and returns the best limit sections. Document
+// statistics are taken over the sections of these files only. Files that
+// were not indexed with IndexSections are skipped.
+//
+// Query and section text are split into lowercase words and reduced with a
+// light suffix stemmer, so that "caching" also matches "cache" and
+// "cached". Heading words count twice.
+func (cm *CacheManager) RankSections(
+	filePaths []string,
+	query string,
+	limit int,
+) RankedSections {
+	terms := uniqueTerms(tokenize(query))
+	result := RankedSections{
+		Terms:    terms,
+		Sections: []SectionScore{},
+		Matched:  0,
+		Searched: 0,
+	}
+
+	cm.sections.mu.RLock()
+	defer cm.sections.mu.RUnlock()
+
+	files := make([]*indexedFile, 0, len(filePaths))
+	paths := make([]string, 0, len(filePaths))
+	seen := make(map[string]bool, len(filePaths))
+	total := 0
+	frequencies := make(map[string]int, len(terms)) // Sections per term
+	for _, filePath := range filePaths {
+		file, ok := cm.sections.files[filePath]
+		if !ok || seen[filePath] {
+			continue
+		}
+		seen[filePath] = true
+		files = append(files, file)
+		paths = append(paths, filePath)
+
+		result.Searched += len(file.tags)
+		total += file.total
+		for _, term := range terms {
+			frequencies[term] += len(file.postings[term])
+		}
+	}
+	if result.Searched == 0 || len(terms) == 0 {
+		return result
+	}
+
+	averageLength := float64(total) / float64(result.Searched)
+	var scored []SectionScore
+	for i, file := range files {
+		scores := make(map[int]*SectionScore)
+		for _, term := range terms {
+			idf := bm25IDF(result.Searched, frequencies[term])
+			for _, post := range file.postings[term] {
+				score, ok := scores[post.section]
+				if !ok {
+					score = &SectionScore{
+						FilePath: paths[i],
+						Entry:    file.tags[post.section],
+						Score:    0,
+						Terms:    nil,
+					}
+					scores[post.section] = score
+				}
+				score.Score += idf * bm25TermScore(
+					post.frequency,
+					file.lengths[post.section],
+					averageLength,
+				)
+				score.Terms = append(score.Terms, term)
+			}
+		}
+		for _, score := range scores {
+			scored = append(scored, *score)
+		}
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		a, b := scored[i], scored[j]
+		switch {
+		case a.Score != b.Score:
+			return a.Score > b.Score
+		case a.FilePath != b.FilePath:
+			return a.FilePath < b.FilePath
+		default:
+			return a.Entry.Line < b.Entry.Line
+		}
+	})
+
+	result.Matched = len(scored)
+	if limit > 0 && len(scored) > limit {
+		scored = scored[:limit]
+	}
+	if len(scored) > 0 {
+		result.Sections = scored
+	}
+	return result
+}
+
+// newIndexedFile builds the postings of a file's sections. A section's
+// text is its heading and the lines up to its first subsection, so that
+// every line belongs to exactly one section.
+func newIndexedFile(tags []*TagEntry, lines []string) *indexedFile {
+	file := &indexedFile{
+		tags:     tags,
+		lengths:  make([]int, len(tags)),
+		total:    0,
+		postings: make(map[string][]posting),
+	}
+
+	for i, entry := range tags {
+		counts := make(map[string]int)
+		length := 0
+		for _, term := range tokenize(entry.Name) {
+			counts[term] += headingWeight
+			length += headingWeight
+		}
+
+		end := min(entry.End, len(lines))
+		if i+1 < len(tags) && tags[i+1].Line <= end {
+			end = tags[i+1].Line - 1
+		}
+		for line := entry.Line + 1; line <= end; line++ {
+			for _, term := range tokenize(lines[line-1]) {
+				counts[term]++
+				length++
+			}
+		}
+
+		for term, frequency := range counts {
+			file.postings[term] = append(file.postings[term], posting{
+				section:   i,
+				frequency: frequency,
+			})
+		}
+		file.lengths[i] = length
+		file.total += length
+	}
+
+	return file
+}
+
+// bm25IDF returns the inverse document frequency of a term found in
+// matching of total sections. This variant is never negative, so that very
+// common terms still add to the score.
+func bm25IDF(total, matching int) float64 {
+	return math.Log(
+		1 + (float64(total)-float64(matching)+0.5)/(float64(matching)+0.5),
+	)
+}
+
+// bm25TermScore returns the saturated, length-normalized frequency of a
+// term in a section.
+func bm25TermScore(frequency, length int, averageLength float64) float64 {
+	tf := float64(frequency)
+	norm := 1 - bm25B + bm25B*float64(length)/averageLength
+	return tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+}
+
+// tokenize splits text into lowercase, stemmed words of letters and digits.
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, stem(word))
+	}
+	return terms
+}
+
+// stem strips common English inflections: plurals, "-ation", "-ing",
+// "-ed" and a final "e". Both "invalidation" and "invalidated" become
+// "invalidat", and "caches" and "caching" become "cach".
+func stem(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies"):
+		word = replaceSuffix(word, "ies", "y")
+	case strings.HasSuffix(word, "ss"), strings.HasSuffix(word, "us"):
+		// Not a plural: "class", "status"
+	case strings.HasSuffix(word, "s"):
+		word = replaceSuffix(word, "s", "")
+	}
+
+	for _, rule := range [][2]string{{"ation", "at"}, {"ing", ""}, {"ed", ""}} {
+		if strings.HasSuffix(word, rule[0]) {
+			word = replaceSuffix(word, rule[0], rule[1])
+			break
+		}
+	}
+
+	return replaceSuffix(word, "e", "")
+}
+
+// replaceSuffix replaces the suffix of word unless the stem would become
+// shorter than minStemLength.
+func replaceSuffix(word, suffix, replacement string) string {
+	base, ok := strings.CutSuffix(word, suffix)
+	if !ok || len(base)+len(replacement) < minStemLength {
+		return word
+	}
+	return base + replacement
+}
+
+// uniqueTerms returns terms without duplicates, in order of appearance.
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
+
+// sameTags reports whether two heading slices are the same cached slice.
+func sameTags(a, b []*TagEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
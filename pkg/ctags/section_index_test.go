@@ -0,0 +1,157 @@
+package ctags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"Cache invalidation", []string{"cach", "invalidat"}},
+		{"caching, cached; caches", []string{"cach", "cach", "cach"}},
+		{"Invalidate the `mtime` check", []string{"invalidat", "the", "mtim", "check"}},
+		{"class status uses", []string{"class", "status", "use"}},
+		{"## Task 2.1: policies", []string{"task", "2", "1", "policy"}},
+		{"", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tokenize(tt.text))
+		})
+	}
+}
+
+func TestCacheManager_RankSections(t *testing.T) {
+	root := createWorkspace(t, map[string]string{
+		"plan.md": "# Plan\n" +
+			"Overview of the project.\n" +
+			"## Caching\n" +
+			"Cached headings are invalidated when the mtime changes.\n" +
+			"Cache invalidation also runs on file events.\n" +
+			"## Deployment\n" +
+			"Deploy the server.\n",
+		"notes.md": "# Notes\n" +
+			"The cache is mentioned once among many other words here.\n",
+	})
+	plan := filepath.Join(root, "plan.md")
+	notes := filepath.Join(root, "notes.md")
+
+	cm := NewCacheManager()
+	ctx := context.Background()
+	require.NoError(t, cm.IndexSections(ctx, plan))
+	require.NoError(t, cm.IndexSections(ctx, notes))
+
+	ranked := cm.RankSections(
+		[]string{plan, notes},
+		"caching invalidation",
+		10,
+	)
+	assert.Equal(t, []string{"cach", "invalidat"}, ranked.Terms)
+	assert.Equal(t, 4, ranked.Searched)
+	require.Equal(t, 2, ranked.Matched)
+
+	best := ranked.Sections[0]
+	assert.Equal(t, plan, best.FilePath)
+	assert.Equal(t, "Caching", best.Entry.Name)
+	assert.Equal(t, []string{"cach", "invalidat"}, best.Terms)
+	assert.Equal(t, "Notes", ranked.Sections[1].Entry.Name)
+	assert.Greater(t, best.Score, ranked.Sections[1].Score)
+
+	// Limits and scopes
+	ranked = cm.RankSections([]string{plan, notes}, "cache", 1)
+	assert.Len(t, ranked.Sections, 1)
+	assert.Equal(t, 2, ranked.Matched)
+	ranked = cm.RankSections([]string{notes}, "deploy", 10)
+	assert.Equal(t, 1, ranked.Searched)
+	assert.Empty(t, ranked.Sections)
+}
+
+func TestCacheManager_RankSectionsStaysInSync(t *testing.T) {
+	root := createWorkspace(t, map[string]string{
+		"doc.md": "# Doc\n## Alpha\nAlpha text.\n",
+	})
+	doc := filepath.Join(root, "doc.md")
+
+	cm := NewCacheManager()
+	ctx := context.Background()
+	require.NoError(t, cm.IndexSections(ctx, doc))
+	assert.Equal(t, 1, cm.RankSections([]string{doc}, "alpha", 10).Matched)
+
+	// A modified file is parsed and indexed again
+	require.NoError(t, os.WriteFile(
+		doc,
+		[]byte("# Doc\n## Beta\nBeta text.\n"),
+		0o644,
+	))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(doc, later, later))
+
+	require.NoError(t, cm.IndexSections(ctx, doc))
+	assert.Equal(t, 0, cm.RankSections([]string{doc}, "alpha", 10).Matched)
+	ranked := cm.RankSections([]string{doc}, "beta", 10)
+	require.Len(t, ranked.Sections, 1)
+	assert.Equal(t, 2, ranked.Sections[0].Entry.Line)
+
+	// Postings are dropped when the file is invalidated
+	cm.InvalidateFile(doc)
+	assert.Equal(t, 0, cm.RankSections([]string{doc}, "beta", 10).Searched)
+
+	require.NoError(t, cm.IndexSections(ctx, doc))
+	cm.Clear()
+	assert.Equal(t, 0, cm.RankSections([]string{doc}, "beta", 10).Searched)
+}
+
+func TestCacheManager_RankSectionsEviction(t *testing.T) {
+	root := createWorkspace(t, map[string]string{
+		"a.md": "# A\nshared\n",
+		"b.md": "# B\nshared\n\n## B.1\nshared\n",
+	})
+	a, b := filepath.Join(root, "a.md"), filepath.Join(root, "b.md")
+
+	cm := NewCacheManagerWithLimits(CacheLimits{
+		MaxEntries: 0,
+		MaxTags:    2,
+		IdleTTL:    0,
+	})
+	ctx := context.Background()
+	require.NoError(t, cm.IndexSections(ctx, a))
+	assert.Equal(t, 1, indexedFiles(cm))
+	assert.Equal(t, 1, cm.DetailedStats().Tags)
+
+	// Indexing b evicted a from the cache, and with it a's postings
+	require.NoError(t, cm.IndexSections(ctx, b))
+	assert.Equal(t, 1, indexedFiles(cm))
+	assert.Equal(t, 2, cm.DetailedStats().Tags)
+	ranked := cm.RankSections([]string{a, b}, "shared", 10)
+	assert.Equal(t, 2, ranked.Searched)
+	require.Len(t, ranked.Sections, 2)
+	assert.Equal(t, b, ranked.Sections[0].FilePath)
+
+	// Tightening the limits evicts the postings of b as well
+	require.NoError(t, cm.SetLimits(CacheLimits{
+		MaxEntries: 0,
+		MaxTags:    1,
+		IdleTTL:    0,
+	}))
+	require.NoError(t, cm.IndexSections(ctx, a))
+	assert.Equal(t, 1, indexedFiles(cm))
+	assert.Equal(t, 1, cm.DetailedStats().Tags)
+	assert.Equal(t, 1, cm.RankSections([]string{a, b}, "shared", 10).Searched)
+}
+
+// indexedFiles returns the number of files with section index postings.
+func indexedFiles(cm *CacheManager) int {
+	cm.sections.mu.RLock()
+	defer cm.sections.mu.RUnlock()
+	return len(cm.sections.files)
+}
@@ -0,0 +1,101 @@
+package ctags
+
+// SectionAtLine returns the innermost section containing the given line
+// (1-based), i.e. the deepest entry whose Line..End range includes it.
+// Returns false for lines before the first heading or past the last section.
+// Entries must be sorted by line.
+func SectionAtLine(entries []*TagEntry, line int) (*TagEntry, bool) {
+	var innermost *TagEntry
+	for _, entry := range entries {
+		if entry.Line > line {
+			break
+		}
+		if entry.End >= line {
+			innermost = entry
+		}
+	}
+
+	return innermost, innermost != nil
+}
+
+// Ancestors returns the sections enclosing entry, from the outermost to the
+// direct parent. Entries must be sorted by line.
+func Ancestors(entries []*TagEntry, entry *TagEntry) []*TagEntry {
+	index := indexOf(entries, entry)
+	if index < 0 {
+		return []*TagEntry{}
+	}
+
+	var reversed []*TagEntry
+	level := entry.Level
+	for i := index - 1; i >= 0; i-- {
+		if entries[i].Level < level {
+			reversed = append(reversed, entries[i])
+			level = entries[i].Level
+		}
+	}
+
+	ancestors := make([]*TagEntry, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		ancestors = append(ancestors, reversed[i])
+	}
+	return ancestors
+}
+
+// Siblings returns the sections directly before and after entry that share
+// its parent (top-level sections are siblings of each other). Either result
+// is nil when there is no such sibling. Entries must be sorted by line.
+func Siblings(
+	entries []*TagEntry,
+	entry *TagEntry,
+) (previous, next *TagEntry) {
+	index := indexOf(entries, entry)
+	if index < 0 {
+		return nil, nil
+	}
+
+	parents := parentIndices(entries)
+	for i := index - 1; i >= 0 && previous == nil; i-- {
+		if parents[i] == parents[index] {
+			previous = entries[i]
+		}
+	}
+	for i := index + 1; i < len(entries) && next == nil; i++ {
+		if parents[i] == parents[index] {
+			next = entries[i]
+		}
+	}
+
+	return previous, next
+}
+
+// parentIndices returns, for every entry, the index of its parent entry or
+// -1 for top-level sections. Entries must be sorted by line.
+func parentIndices(entries []*TagEntry) []int {
+	parents := make([]int, len(entries))
+	var stack []int // Indices of the open ancestors
+
+	for i, entry := range entries {
+		for len(stack) > 0 && entries[stack[len(stack)-1]].Level >= entry.Level {
+			stack = stack[:len(stack)-1]
+		}
+
+		parents[i] = -1
+		if len(stack) > 0 {
+			parents[i] = stack[len(stack)-1]
+		}
+		stack = append(stack, i)
+	}
+
+	return parents
+}
+
+// indexOf returns the position of entry in entries, or -1.
+func indexOf(entries []*TagEntry, entry *TagEntry) int {
+	for i, candidate := range entries {
+		if candidate == entry {
+			return i
+		}
+	}
+	return -1
+}
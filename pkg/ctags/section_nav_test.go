@@ -0,0 +1,97 @@
+package ctags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createNavEntries() []*TagEntry {
+	content := `# Guide
+intro
+## Install
+### Linux
+steps
+### macOS
+## Usage
+#### Flags
+## FAQ
+text
+`
+	return ParseMarkdown([]byte(content), "guide.md")
+}
+
+func TestSectionAtLine(t *testing.T) {
+	entries := createNavEntries()
+
+	tests := []struct {
+		line int
+		want string
+	}{
+		{1, "Guide"},
+		{2, "Guide"},
+		{3, "Install"},
+		{5, "Linux"},
+		{6, "macOS"},
+		{8, "Flags"},
+		{10, "FAQ"},
+	}
+
+	for _, tt := range tests {
+		entry, ok := SectionAtLine(entries, tt.line)
+		require.True(t, ok, "line %d", tt.line)
+		assert.Equal(t, tt.want, entry.Name, "line %d", tt.line)
+	}
+
+	_, ok := SectionAtLine(entries, 11)
+	assert.False(t, ok)
+
+	preamble := ParseMarkdown([]byte("front\n\n# Title\n"), "test.md")
+	_, ok = SectionAtLine(preamble, 1)
+	assert.False(t, ok)
+}
+
+func TestAncestors(t *testing.T) {
+	entries := createNavEntries()
+
+	linux, _ := SectionAtLine(entries, 5)
+	ancestors := Ancestors(entries, linux)
+	require.Len(t, ancestors, 2)
+	assert.Equal(t, "Guide", ancestors[0].Name)
+	assert.Equal(t, "Install", ancestors[1].Name)
+
+	// Skipped levels: H4 directly under H2
+	flags, _ := SectionAtLine(entries, 8)
+	ancestors = Ancestors(entries, flags)
+	require.Len(t, ancestors, 2)
+	assert.Equal(t, "Usage", ancestors[1].Name)
+
+	assert.Empty(t, Ancestors(entries, entries[0]))
+}
+
+func TestSiblings(t *testing.T) {
+	entries := createNavEntries()
+
+	usage, _ := SectionAtLine(entries, 7)
+	previous, next := Siblings(entries, usage)
+	require.NotNil(t, previous)
+	require.NotNil(t, next)
+	assert.Equal(t, "Install", previous.Name)
+	assert.Equal(t, "FAQ", next.Name)
+
+	linux, _ := SectionAtLine(entries, 4)
+	previous, next = Siblings(entries, linux)
+	assert.Nil(t, previous)
+	require.NotNil(t, next)
+	assert.Equal(t, "macOS", next.Name)
+
+	// Siblings never cross into another parent
+	macOS, _ := SectionAtLine(entries, 6)
+	_, next = Siblings(entries, macOS)
+	assert.Nil(t, next)
+
+	previous, next = Siblings(entries, entries[0])
+	assert.Nil(t, previous)
+	assert.Nil(t, next)
+}
@@ -0,0 +1,280 @@
+package ctags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PathSeparator separates heading names in a hierarchical section query,
+// e.g. "Phase 2 > Task 2.1 > Testing".
+const PathSeparator = ">"
+
+// pathDelimiter is PathSeparator as it appears between heading names in a
+// query. Only a separator with a space on each side splits the query, so
+// headings such as "Input -> Output" or "a>b" are matched as they are.
+const pathDelimiter = " " + PathSeparator + " "
+
+// SectionQuery addresses a section by its heading path or anchor slug.
+type SectionQuery struct {
+	// Anchor is a GitHub-style slug ("task-21-add-json-output-format")
+	// parsed from a "#anchor" query. When set, the section with that slug
+	// is selected; Path is only used if no slug matches.
+	Anchor string
+
+	// Path lists heading names from the outermost ancestor to the target
+	// section. Ancestors may be skipped: "Phase 2 > Testing" matches
+	// "Phase 2 > Task 2.1 > Testing".
+	Path []string
+
+	// Literal is the whole query as a single heading name. It is matched
+	// when a Path of several elements matches nothing, so that headings
+	// containing " > " can still be found.
+	Literal string
+
+	// Occurrence selects among several matching sections (1-based, in
+	// document order). Zero selects the first match, or reports an
+	// ambiguity in strict mode.
+	Occurrence int
+
+	// Mode selects how each path element is compared with heading names.
+	// The zero value behaves like MatchSubstring.
+	Mode MatchMode
+
+	// CaseSensitive disables the default case-insensitive comparison.
+	CaseSensitive bool
+
+	// Strict makes ResolveSection fail with an AmbiguousSectionError instead
+	// of silently picking the first of several matches.
+	Strict bool
+}
+
+// ParseSectionQuery parses a section heading or heading path such as
+// "Phase 2 > Task 2.1 > Testing" into a SectionQuery. Path elements are
+// separated by " > " with spaces on both sides. A query starting with '#'
+// ("#task-21-testing") also addresses the section by its anchor slug.
+func ParseSectionQuery(heading string, occurrence int) SectionQuery {
+	literal := strings.TrimSpace(heading)
+	anchor := ""
+	if strings.HasPrefix(literal, "#") {
+		anchor = strings.TrimPrefix(literal, "#")
+	}
+
+	var path []string
+	for _, part := range strings.Split(heading, pathDelimiter) {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			path = append(path, trimmed)
+		}
+	}
+
+	return SectionQuery{
+		Anchor:        anchor,
+		Path:          path,
+		Literal:       literal,
+		Occurrence:    occurrence,
+		Mode:          MatchSubstring,
+		CaseSensitive: false,
+		Strict:        false,
+	}
+}
+
+// Validate reports whether every path element is a valid pattern for the
+// query's match mode.
+//
+// Errors include: ErrInvalidMatchMode, ErrInvalidPattern.
+func (q SectionQuery) Validate() error {
+	_, err := compilePath(q.Path, q)
+	return err
+}
+
+// String returns the query in "A > B > C" form.
+func (q SectionQuery) String() string {
+	return strings.Join(q.Path, pathDelimiter)
+}
+
+// ScopeNames returns the names of the entry's ancestors from outermost to
+// innermost, as recorded in the Scope field.
+func (e *TagEntry) ScopeNames() []string {
+	if e.Scope == "" {
+		return []string{}
+	}
+	return strings.Split(e.Scope, scopeSeparator)
+}
+
+// HeadingPath returns the entry's ancestors followed by its own name.
+func (e *TagEntry) HeadingPath() []string {
+	return append(e.ScopeNames(), e.Name)
+}
+
+// FindSections returns the entry with the query's anchor slug, or all
+// entries matching the query's heading path, in document order. The last
+// path element is matched against the entry name and the preceding
+// elements against its Scope chain, in order. Names are compared using the
+// query's Mode and CaseSensitive fields. The Occurrence and Strict fields
+// are ignored. A query with an invalid pattern matches nothing; use
+// ResolveSection to get the validation error.
+//
+// If a multi-element path matches nothing, the query's Literal is tried as
+// a single heading name so that headings containing " > " can still be
+// found.
+func FindSections(entries []*TagEntry, query SectionQuery) []*TagEntry {
+	matches, err := findSections(entries, query)
+	if err != nil {
+		return []*TagEntry{}
+	}
+	return matches
+}
+
+// ResolveSection returns the single entry selected by the query.
+// Errors include: ErrSectionNotFound, ErrOccurrenceOutOfRange,
+// ErrInvalidMatchMode, ErrInvalidPattern and, in strict mode without an
+// occurrence, *AmbiguousSectionError.
+func ResolveSection(
+	entries []*TagEntry,
+	query SectionQuery,
+) (*TagEntry, error) {
+	matches, err := findSections(entries, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: '%s'", ErrSectionNotFound, query)
+	}
+
+	if query.Occurrence <= 0 {
+		if query.Strict && len(matches) > 1 {
+			return nil, &AmbiguousSectionError{
+				Query:      query.String(),
+				Candidates: NewSectionCandidates(matches),
+			}
+		}
+		return matches[0], nil
+	}
+
+	if query.Occurrence > len(matches) {
+		return nil, fmt.Errorf(
+			"%w: occurrence %d requested for '%s', but only %d match(es)",
+			ErrOccurrenceOutOfRange,
+			query.Occurrence,
+			query,
+			len(matches),
+		)
+	}
+
+	return matches[query.Occurrence-1], nil
+}
+
+// NewSectionCandidates describes entries as ambiguity candidates.
+func NewSectionCandidates(entries []*TagEntry) []SectionCandidate {
+	candidates := make([]SectionCandidate, 0, len(entries))
+	for _, entry := range entries {
+		candidates = append(candidates, SectionCandidate{
+			Name:        entry.Name,
+			HeadingPath: entry.HeadingPath(),
+			Level:       fmt.Sprintf("H%d", entry.Level),
+			StartLine:   entry.Line,
+			EndLine:     entry.End,
+		})
+	}
+	return candidates
+}
+
+// Subtree returns the entry and all of its descendants (the entries that
+// follow it with a deeper level, up to the next sibling or parent).
+// Entries must be sorted by line.
+func Subtree(entries []*TagEntry, root *TagEntry) []*TagEntry {
+	for i, entry := range entries {
+		if entry != root {
+			continue
+		}
+
+		end := i + 1
+		for end < len(entries) && entries[end].Level > root.Level {
+			end++
+		}
+		return entries[i:end]
+	}
+
+	return []*TagEntry{}
+}
+
+// findSections implements FindSections, reporting invalid patterns.
+func findSections(
+	entries []*TagEntry,
+	query SectionQuery,
+) ([]*TagEntry, error) {
+	if query.Anchor != "" {
+		if entry, ok := FindBySlug(entries, query.Anchor); ok {
+			return []*TagEntry{entry}, nil
+		}
+	}
+
+	if len(query.Path) == 0 {
+		return []*TagEntry{}, nil
+	}
+
+	matchers, err := compilePath(query.Path, query)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := findSectionsByPath(entries, matchers)
+	if len(matches) == 0 && len(query.Path) > 1 && query.Literal != "" {
+		literal := []string{query.Literal}
+		if matchers, err = compilePath(literal, query); err == nil {
+			matches = findSectionsByPath(entries, matchers)
+		}
+	}
+
+	return matches, nil
+}
+
+// compilePath creates a NameMatcher for every path element.
+func compilePath(path []string, query SectionQuery) ([]*NameMatcher, error) {
+	matchers := make([]*NameMatcher, 0, len(path))
+	for _, element := range path {
+		matcher, err := NewNameMatcher(query.Mode, element, query.CaseSensitive)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+// findSectionsByPath returns the entries whose heading path matches the
+// path matchers.
+func findSectionsByPath(
+	entries []*TagEntry,
+	path []*NameMatcher,
+) []*TagEntry {
+	target := path[len(path)-1]
+	ancestors := path[:len(path)-1]
+
+	var matches []*TagEntry
+	for _, entry := range entries {
+		if !target.Match(entry.Name) {
+			continue
+		}
+		if matchesAncestors(entry.ScopeNames(), ancestors) {
+			matches = append(matches, entry)
+		}
+	}
+
+	return matches
+}
+
+// matchesAncestors reports whether the query ancestors appear in the scope
+// chain in order (not necessarily adjacent).
+func matchesAncestors(scope []string, ancestors []*NameMatcher) bool {
+	next := 0
+	for _, name := range scope {
+		if next == len(ancestors) {
+			break
+		}
+		if ancestors[next].Match(name) {
+			next++
+		}
+	}
+
+	return next == len(ancestors)
+}
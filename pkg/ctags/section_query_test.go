@@ -0,0 +1,145 @@
+package ctags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createPlanEntries returns a plan with repeated "Testing" subsections.
+func createPlanEntries() []*TagEntry {
+	content := `# Plan
+## Phase 1
+### Task 1.1
+#### Testing
+## Phase 2
+### Task 2.1
+#### Testing
+### Task 2.2
+#### Testing
+`
+	return ParseMarkdown([]byte(content), "plan.md")
+}
+
+func TestParseSectionQuery(t *testing.T) {
+	query := ParseSectionQuery(" Phase 2 > Task 2.1  >  Testing ", 2)
+
+	assert.Equal(t, []string{"Phase 2", "Task 2.1", "Testing"}, query.Path)
+	assert.Equal(t, 2, query.Occurrence)
+	assert.Equal(t, "Phase 2 > Task 2.1 > Testing", query.String())
+}
+
+func TestTagEntry_HeadingPath(t *testing.T) {
+	entries := createPlanEntries()
+
+	assert.Equal(t, []string{}, entries[0].ScopeNames())
+	assert.Equal(t, []string{"Plan"}, entries[0].HeadingPath())
+	assert.Equal(
+		t,
+		[]string{"Plan", "Phase 2", "Task 2.1", "Testing"},
+		entries[6].HeadingPath(),
+	)
+}
+
+func TestFindSections_Path(t *testing.T) {
+	entries := createPlanEntries()
+
+	tests := []struct {
+		name      string
+		heading   string
+		wantLines []int
+	}{
+		{"plain name", "Testing", []int{4, 7, 9}},
+		{"full path", "Plan > Phase 2 > Task 2.1 > Testing", []int{7}},
+		{"skipped ancestor", "Phase 2 > Testing", []int{7, 9}},
+		{"case insensitive", "phase 1 > testing", []int{4}},
+		{"wrong order", "Task 2.1 > Phase 2 > Testing", nil},
+		{"no match", "Phase 3 > Testing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := FindSections(entries, ParseSectionQuery(tt.heading, 0))
+
+			var lines []int
+			for _, match := range matches {
+				lines = append(lines, match.Line)
+			}
+			assert.Equal(t, tt.wantLines, lines)
+		})
+	}
+}
+
+func TestFindSections_LiteralHeadingWithSeparator(t *testing.T) {
+	entries := ParseMarkdown([]byte("# Input > Output\n## Other\n"), "t.md")
+
+	matches := FindSections(entries, ParseSectionQuery("Input > Output", 0))
+	require.Len(t, matches, 1)
+	assert.Equal(t, "Input > Output", matches[0].Name)
+}
+
+func TestFindSectionBounds_HeadingWithUnspacedSeparator(t *testing.T) {
+	entries := ParseMarkdown(
+		[]byte("# Plan\n## Input -> Output\n## a>b\n## Input  >  Raw\n"),
+		"t.md",
+	)
+
+	tests := []struct {
+		query string
+		line  int
+	}{
+		{"Input -> Output", 2},
+		{"a>b", 3},
+		{"Plan > a>b", 3},
+		{"Input  >  Raw", 4},
+	}
+
+	for _, tt := range tests {
+		start, _, _, found := FindSectionBounds(entries, tt.query)
+		assert.True(t, found, tt.query)
+		assert.Equal(t, tt.line, start, tt.query)
+	}
+
+	query := ParseSectionQuery("a>b", 0)
+	assert.Equal(t, []string{"a>b"}, query.Path)
+}
+
+func TestResolveSection_Occurrence(t *testing.T) {
+	entries := createPlanEntries()
+
+	entry, err := ResolveSection(entries, ParseSectionQuery("Testing", 0))
+	require.NoError(t, err)
+	assert.Equal(t, 4, entry.Line)
+
+	entry, err = ResolveSection(entries, ParseSectionQuery("Testing", 3))
+	require.NoError(t, err)
+	assert.Equal(t, 9, entry.Line)
+
+	_, err = ResolveSection(entries, ParseSectionQuery("Testing", 4))
+	require.ErrorIs(t, err, ErrOccurrenceOutOfRange)
+
+	_, err = ResolveSection(entries, ParseSectionQuery("Missing", 0))
+	require.ErrorIs(t, err, ErrSectionNotFound)
+}
+
+func TestFindSectionBounds_Path(t *testing.T) {
+	entries := createPlanEntries()
+
+	start, end, name, found := FindSectionBounds(entries, "Task 2.2 > Testing")
+	require.True(t, found)
+	assert.Equal(t, 9, start)
+	assert.Equal(t, 9, end)
+	assert.Equal(t, "Testing", name)
+}
+
+func TestSubtree(t *testing.T) {
+	entries := createPlanEntries()
+
+	subtree := Subtree(entries, entries[4]) // Phase 2
+	require.Len(t, subtree, 5)
+	assert.Equal(t, "Phase 2", subtree[0].Name)
+	assert.Equal(t, 9, subtree[4].Line)
+
+	assert.Empty(t, Subtree(entries, &TagEntry{Name: "unknown"}))
+}
@@ -0,0 +1,81 @@
+package ctags
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// inlineLinkPattern matches inline links and images ("[text](url)",
+// "![alt](url)"), whose rendered text is just the bracketed part.
+var inlineLinkPattern = regexp.MustCompile( //nolint:gochecknoglobals // compiled once
+	`!?\[([^\]]*)\]\([^)]*\)`,
+)
+
+// htmlTagPattern matches inline HTML tags, which do not contribute to the
+// rendered heading text.
+var htmlTagPattern = regexp.MustCompile( //nolint:gochecknoglobals // compiled once
+	`</?[A-Za-z][^>]*>`,
+)
+
+// Slugify converts heading text into a GitHub-compatible anchor slug:
+// the rendered text is lowercased, punctuation is removed (except '-' and
+// '_'), and spaces become hyphens. For example,
+// "Task 2.1: Add JSON output format" becomes "task-21-add-json-output-format".
+//
+// Slugify does not make slugs unique; see AssignSlugs.
+func Slugify(text string) string {
+	text = inlineLinkPattern.ReplaceAllString(text, "$1")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = strings.ToLower(strings.TrimSpace(text))
+
+	var slug strings.Builder
+	for _, r := range text {
+		switch {
+		case r == ' ':
+			slug.WriteRune('-')
+		case r == '-', r == '_',
+			unicode.IsLetter(r), unicode.IsNumber(r), unicode.IsMark(r):
+			slug.WriteRune(r)
+		}
+	}
+
+	return slug.String()
+}
+
+// AssignSlugs sets the Slug field of every entry to its GitHub anchor.
+// Repeated slugs get "-1", "-2", ... suffixes in document order, exactly as
+// GitHub renders them, so "#setup-1" addresses the second "Setup" heading.
+// Entries must be sorted by line.
+func AssignSlugs(entries []*TagEntry) {
+	occurrences := make(map[string]int, len(entries))
+
+	for _, entry := range entries {
+		base := Slugify(entry.Name)
+		slug := base
+		for {
+			if _, taken := occurrences[slug]; !taken {
+				break
+			}
+			occurrences[base]++
+			slug = base + "-" + strconv.Itoa(occurrences[base])
+		}
+		occurrences[slug] = 0
+		entry.Slug = slug
+	}
+}
+
+// FindBySlug returns the entry whose slug equals anchor. A leading '#' is
+// ignored, percent-encoding (as in "#caf%C3%A9") is decoded and the
+// comparison is case-insensitive.
+func FindBySlug(entries []*TagEntry, anchor string) (*TagEntry, bool) {
+	anchor = AnchorKey(anchor)
+
+	for _, entry := range entries {
+		if entry.Slug == anchor {
+			return entry, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,81 @@
+package ctags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		heading string
+		want    string
+	}{
+		{"Task 2.1: Add JSON output format", "task-21-add-json-output-format"},
+		{"Section 1: Introduction", "section-1-introduction"},
+		{"What's new?", "whats-new"},
+		{"snake_case and kebab-case", "snake_case-and-kebab-case"},
+		{"`code` in heading", "code-in-heading"},
+		{"See [the docs](https://example.com/docs)", "see-the-docs"},
+		{"Install <em>now</em>", "install-now"},
+		{"C++ & Go", "c--go"},
+		{"Café Überblick", "café-überblick"},
+		{"  Trimmed  ", "trimmed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.heading, func(t *testing.T) {
+			assert.Equal(t, tt.want, Slugify(tt.heading))
+		})
+	}
+}
+
+func TestAssignSlugs_Duplicates(t *testing.T) {
+	content := "# Setup\n## Setup\n## Setup 1\n## Setup\n## Notes\n"
+
+	entries := ParseMarkdown([]byte(content), "test.md")
+	require.Len(t, entries, 5)
+
+	slugs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		slugs = append(slugs, entry.Slug)
+	}
+	// "Setup 1" slugifies to "setup-1", which the second "Setup" already
+	// took, so GitHub gives it a suffix of its own
+	assert.Equal(
+		t,
+		[]string{"setup", "setup-1", "setup-1-1", "setup-2", "notes"},
+		slugs,
+	)
+}
+
+func TestFindBySlug(t *testing.T) {
+	entries := ParseMarkdown(
+		[]byte("# Guide\n## Café\n## Testing\n## Testing\n"),
+		"test.md",
+	)
+
+	entry, ok := FindBySlug(entries, "#testing-1")
+	require.True(t, ok)
+	assert.Equal(t, 4, entry.Line)
+
+	entry, ok = FindBySlug(entries, "caf%C3%A9")
+	require.True(t, ok)
+	assert.Equal(t, "Café", entry.Name)
+
+	_, ok = FindBySlug(entries, "#missing")
+	assert.False(t, ok)
+}
+
+func TestResolveSection_Anchor(t *testing.T) {
+	entries := createPlanEntries()
+
+	entry, err := ResolveSection(entries, ParseSectionQuery("#testing-2", 0))
+	require.NoError(t, err)
+	assert.Equal(t, "Testing", entry.Name)
+	assert.Equal(t, 9, entry.Line)
+
+	_, err = ResolveSection(entries, ParseSectionQuery("#no-such-anchor", 0))
+	require.ErrorIs(t, err, ErrSectionNotFound)
+}
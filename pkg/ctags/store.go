@@ -0,0 +1,304 @@
+package ctags
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// StoreFormatVersion is the version of the on-disk index format. Indexes
+	// written with another version are discarded and rebuilt.
+	StoreFormatVersion = 1
+
+	// corruptSuffix is appended to an index file that cannot be decoded
+	// before a fresh index replaces it.
+	corruptSuffix = ".corrupt"
+
+	// storeFileMode is the permission of the index file.
+	storeFileMode = 0o600
+
+	// storeDirMode is the permission of directories created for the index.
+	storeDirMode = 0o755
+)
+
+// TagStore is a persistent index of parsed headings, so that a new server
+// session can reuse the TagEntry data of the previous one instead of parsing
+// every file again. Records are keyed by absolute path and carry the file's
+// modification time, size and content hash, which CacheManager validates
+// with its ValidationMode before use.
+//
+// The index is a single JSON file written atomically by Flush. A file that
+// cannot be decoded is moved aside (see RecoveredFrom) and replaced by an
+// empty index; a file with another format version is silently discarded.
+type TagStore struct {
+	path          string
+	records       map[string]*storeRecord
+	dirty         bool
+	recoveredFrom string
+	mu            sync.Mutex
+}
+
+// storeFile is the on-disk layout of the index.
+type storeFile struct {
+	Version int                     `json:"version"`
+	Files   map[string]*storeRecord `json:"files"`
+}
+
+// storeRecord holds the parsed headings of one file version.
+type storeRecord struct {
+	ModTime time.Time   `json:"mod_time"`
+	Size    int64       `json:"size"`
+	Hash    string      `json:"hash"`
+	Tags    []storedTag `json:"tags"`
+}
+
+// storedTag is the serialized form of a TagEntry. The file path is implied
+// by the record key.
+type storedTag struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+	Kind    string `json:"kind"`
+	Line    int    `json:"line"`
+	End     int    `json:"end"`
+	Scope   string `json:"scope,omitempty"`
+	Level   int    `json:"level"`
+	Slug    string `json:"slug"`
+}
+
+// OpenTagStore opens the index at path, creating an empty one if the file
+// does not exist. Records for files that no longer exist are dropped.
+//
+// Errors include: failures to read an existing index or to move a corrupt
+// index aside. Decoding failures are recovered from, not returned.
+func OpenTagStore(path string) (*TagStore, error) {
+	store := &TagStore{
+		path:          path,
+		records:       make(map[string]*storeRecord),
+		dirty:         false,
+		recoveredFrom: "",
+		mu:            sync.Mutex{},
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag index: %w", err)
+	}
+
+	var file storeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		quarantine := path + corruptSuffix
+		if err := os.Rename(path, quarantine); err != nil {
+			return nil, fmt.Errorf("failed to move corrupt tag index: %w", err)
+		}
+		store.recoveredFrom = quarantine
+		return store, nil
+	}
+
+	if file.Version != StoreFormatVersion {
+		store.dirty = true // Rewrite in the current format on next Flush
+		return store, nil
+	}
+
+	for filePath, record := range file.Files {
+		if record == nil {
+			continue
+		}
+		if _, err := os.Stat(filePath); err != nil {
+			store.dirty = true
+			continue
+		}
+		store.records[filePath] = record
+	}
+
+	return store, nil
+}
+
+// Path returns the location of the index file.
+func (s *TagStore) Path() string {
+	return s.path
+}
+
+// RecoveredFrom returns the path a corrupt index was moved to when the store
+// was opened, or an empty string if the index was intact.
+func (s *TagStore) RecoveredFrom() string {
+	return s.recoveredFrom
+}
+
+// Len returns the number of indexed files.
+func (s *TagStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
+
+// get returns the stored tags for a file if its record is valid for the
+// fingerprint under the validation mode. The returned entries are fresh
+// copies whose File field is filePath.
+func (s *TagStore) get(
+	filePath string,
+	fingerprint fileFingerprint,
+	mode ValidationMode,
+) ([]*TagEntry, bool) {
+	key, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	record, exists := s.records[key]
+	s.mu.Unlock()
+	if !exists {
+		return nil, false
+	}
+
+	candidate := &CacheEntry{
+		FilePath:   filePath,
+		ModTime:    record.ModTime,
+		Size:       record.Size,
+		Hash:       record.Hash,
+		Tags:       nil,
+		LastAccess: time.Time{},
+		element:    nil,
+	}
+	if !fingerprint.matches(candidate, mode) {
+		return nil, false
+	}
+
+	tags := make([]*TagEntry, 0, len(record.Tags))
+	for _, stored := range record.Tags {
+		tags = append(tags, &TagEntry{
+			Name:    stored.Name,
+			File:    filePath,
+			Pattern: stored.Pattern,
+			Kind:    stored.Kind,
+			Line:    stored.Line,
+			End:     stored.End,
+			Scope:   stored.Scope,
+			Level:   stored.Level,
+			Slug:    stored.Slug,
+		})
+	}
+	return tags, true
+}
+
+// put records the tags parsed from a file version.
+func (s *TagStore) put(
+	filePath string,
+	fingerprint fileFingerprint,
+	tags []*TagEntry,
+) {
+	key, err := filepath.Abs(filePath)
+	if err != nil {
+		return
+	}
+
+	record := &storeRecord{
+		ModTime: fingerprint.ModTime,
+		Size:    fingerprint.Size,
+		Hash:    fingerprint.Hash,
+		Tags:    make([]storedTag, 0, len(tags)),
+	}
+	for _, tag := range tags {
+		record.Tags = append(record.Tags, storedTag{
+			Name:    tag.Name,
+			Pattern: tag.Pattern,
+			Kind:    tag.Kind,
+			Line:    tag.Line,
+			End:     tag.End,
+			Scope:   tag.Scope,
+			Level:   tag.Level,
+			Slug:    tag.Slug,
+		})
+	}
+
+	s.mu.Lock()
+	s.records[key] = record
+	s.dirty = true
+	s.mu.Unlock()
+}
+
+// remove drops the record of a file.
+func (s *TagStore) remove(filePath string) {
+	key, err := filepath.Abs(filePath)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	if _, exists := s.records[key]; exists {
+		delete(s.records, key)
+		s.dirty = true
+	}
+	s.mu.Unlock()
+}
+
+// Flush writes the index to disk if it changed since the last flush. The
+// file is written to a temporary file and renamed into place, so readers
+// never observe a partially written index.
+func (s *TagStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(storeFile{
+		Version: StoreFormatVersion,
+		Files:   s.records,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode tag index: %w", err)
+	}
+
+	if err := writeFileAtomic(s.path, data, storeFileMode); err != nil {
+		return fmt.Errorf("failed to write tag index: %w", err)
+	}
+
+	s.dirty = false
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path, creating the parent directory if needed.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, storeDirMode); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // No-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set permissions: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	return nil
+}
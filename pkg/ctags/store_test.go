@@ -0,0 +1,171 @@
+package ctags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStoreCache returns an unbounded cache backed by the index at path.
+func newStoreCache(t *testing.T, path string) (*CacheManager, *TagStore) {
+	t.Helper()
+
+	store, err := OpenTagStore(path)
+	require.NoError(t, err)
+
+	cache := NewCacheManager()
+	cache.SetStore(store)
+	return cache, store
+}
+
+func TestTagStore_ReusedAcrossSessions(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "index", "tags.json")
+	file := createTestMarkdownFile(t, "# Guide\n## Install\n## Install\n")
+	ctx := context.Background()
+
+	// First session parses the file and persists it
+	cache, _ := newStoreCache(t, indexPath)
+	original, err := cache.GetTags(ctx, file)
+	require.NoError(t, err)
+	require.NoError(t, cache.FlushStore())
+	assert.Equal(t, uint64(0), cache.DetailedStats().StoreHits)
+
+	// Second session loads the parsed headings from the index
+	cache, store := newStoreCache(t, indexPath)
+	assert.Equal(t, 1, store.Len())
+
+	restored, err := cache.GetTags(ctx, file)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), cache.DetailedStats().StoreHits)
+
+	require.Len(t, restored, len(original))
+	for i := range original {
+		assert.Equal(t, *original[i], *restored[i])
+	}
+	assert.Equal(t, "install-1", restored[2].Slug)
+
+	// Subsequent lookups are served from memory
+	_, err = cache.GetTags(ctx, file)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), cache.DetailedStats().Hits)
+}
+
+func TestTagStore_RejectsChangedFile(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "tags.json")
+	file := createTestMarkdownFile(t, "# Alpha\n")
+	ctx := context.Background()
+
+	cache, _ := newStoreCache(t, indexPath)
+	_, err := cache.GetTags(ctx, file)
+	require.NoError(t, err)
+	require.NoError(t, cache.FlushStore())
+
+	// Same mtime and size, different content: only hash validation notices
+	rewriteKeepingMtime(t, file, "# Gamma\n")
+
+	cache, _ = newStoreCache(t, indexPath)
+	require.NoError(t, cache.SetValidationMode(ValidateHash))
+	tags, err := cache.GetTags(ctx, file)
+	require.NoError(t, err)
+	assert.Equal(t, "Gamma", tags[0].Name)
+	assert.Equal(t, uint64(0), cache.DetailedStats().StoreHits)
+
+	// A modified mtime is caught in every mode
+	modifyMarkdownFile(t, file, "# Delta\n")
+	cache, _ = newStoreCache(t, indexPath)
+	tags, err = cache.GetTags(ctx, file)
+	require.NoError(t, err)
+	assert.Equal(t, "Delta", tags[0].Name)
+	assert.Equal(t, uint64(0), cache.DetailedStats().StoreHits)
+}
+
+func TestTagStore_KeyedByAbsolutePath(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(dir, "tags.json")
+	require.NoError(
+		t,
+		os.WriteFile(filepath.Join(dir, "doc.md"), []byte("# Doc\n"), 0o644),
+	)
+	t.Chdir(dir)
+	ctx := context.Background()
+
+	cache, _ := newStoreCache(t, indexPath)
+	_, err := cache.GetTags(ctx, "doc.md")
+	require.NoError(t, err)
+	require.NoError(t, cache.FlushStore())
+
+	cache, _ = newStoreCache(t, indexPath)
+	tags, err := cache.GetTags(ctx, filepath.Join(dir, "doc.md"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), cache.DetailedStats().StoreHits)
+	assert.Equal(t, filepath.Join(dir, "doc.md"), tags[0].File)
+}
+
+func TestTagStore_CorruptionRecovery(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "tags.json")
+	require.NoError(t, os.WriteFile(indexPath, []byte(`{"version": 1, "fil`), 0o600))
+
+	store, err := OpenTagStore(indexPath)
+	require.NoError(t, err)
+	assert.Equal(t, indexPath+".corrupt", store.RecoveredFrom())
+	assert.Equal(t, 0, store.Len())
+	assert.FileExists(t, indexPath+".corrupt")
+
+	// The recovered store is usable and writes a fresh index
+	cache := NewCacheManager()
+	cache.SetStore(store)
+	_, err = cache.GetTags(context.Background(), createTestMarkdownFile(t, "# A\n"))
+	require.NoError(t, err)
+	require.NoError(t, cache.FlushStore())
+
+	reopened, err := OpenTagStore(indexPath)
+	require.NoError(t, err)
+	assert.Empty(t, reopened.RecoveredFrom())
+	assert.Equal(t, 1, reopened.Len())
+}
+
+func TestTagStore_VersionMismatchAndPruning(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(dir, "tags.json")
+	require.NoError(t, os.WriteFile(
+		indexPath,
+		[]byte(`{"version": 999, "files": {"/x.md": {"tags": []}}}`),
+		0o600,
+	))
+
+	store, err := OpenTagStore(indexPath)
+	require.NoError(t, err)
+	assert.Empty(t, store.RecoveredFrom())
+	assert.Equal(t, 0, store.Len())
+	assert.NoFileExists(t, indexPath+".corrupt")
+
+	// Records of deleted files are dropped when the index is opened
+	file := createTestMarkdownFile(t, "# A\n")
+	cache := NewCacheManager()
+	cache.SetStore(store)
+	_, err = cache.GetTags(context.Background(), file)
+	require.NoError(t, err)
+	require.NoError(t, cache.FlushStore())
+	require.NoError(t, os.Remove(file))
+
+	store, err = OpenTagStore(indexPath)
+	require.NoError(t, err)
+	assert.Equal(t, 0, store.Len())
+}
+
+func TestTagStore_InvalidateFileRemovesRecord(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "tags.json")
+	file := createTestMarkdownFile(t, "# A\n")
+
+	cache, store := newStoreCache(t, indexPath)
+	_, err := cache.GetTags(context.Background(), file)
+	require.NoError(t, err)
+	require.Equal(t, 1, store.Len())
+
+	cache.InvalidateFile(file)
+	assert.Equal(t, 0, store.Len())
+}
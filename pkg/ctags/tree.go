@@ -2,7 +2,9 @@ package ctags
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -71,9 +73,14 @@ type stackEntry struct {
 }
 
 // TreeNode represents a node in the hierarchical JSON tree structure.
+// Workspace trees (see BuildWorkspaceTree) add directory and file nodes
+// above the headings; heading nodes have an empty Kind.
 type TreeNode struct {
 	Name      string      `json:"name"`
-	Level     string      `json:"level"`
+	Kind      string      `json:"kind,omitempty"`  // NodeDirectory or NodeFile
+	Path      string      `json:"path,omitempty"`  // Relative workspace path
+	Slug      string      `json:"slug,omitempty"`  // Anchor, empty for the root
+	Level     string      `json:"level,omitempty"` // Empty for directories
 	StartLine int         `json:"start_line"`
 	EndLine   int         `json:"end_line"`
 	Children  []*TreeNode `json:"children"`
@@ -89,6 +96,9 @@ func BuildTreeJSON(entries []*TagEntry) *TreeNode {
 	// Create root node
 	root := &TreeNode{
 		Name:      filepath.Base(entries[0].File),
+		Kind:      "",
+		Path:      "",
+		Slug:      "",
 		Level:     "H0",
 		StartLine: 0,
 		EndLine:   0,
@@ -101,6 +111,9 @@ func BuildTreeJSON(entries []*TagEntry) *TreeNode {
 	for _, entry := range entries {
 		node := &TreeNode{
 			Name:      entry.Name,
+			Kind:      "",
+			Path:      "",
+			Slug:      entry.Slug,
 			Level:     fmt.Sprintf("H%d", entry.Level),
 			StartLine: entry.Line,
 			EndLine:   entry.End,
@@ -134,3 +147,101 @@ func getLevel(levelStr string) int {
 
 	return level
 }
+
+// Kinds of workspace tree nodes above the headings.
+const (
+	NodeDirectory = "directory"
+	NodeFile      = "file"
+)
+
+// WorkspaceFile is a file and its headings, as input for BuildWorkspaceTree.
+type WorkspaceFile struct {
+	Path    string      // Slash-separated path relative to the workspace root
+	Entries []*TagEntry // Headings to show, in document order
+}
+
+// BuildWorkspaceTree combines the heading trees of several files into one
+// tree rooted at a directory node named rootName. Directory nodes come
+// first, followed by file nodes, each sorted by name; the children of a
+// file node are its headings as built by BuildTreeJSON. Files without
+// headings appear as file nodes without children.
+func BuildWorkspaceTree(rootName string, files []WorkspaceFile) *TreeNode {
+	root := newDirectoryNode(rootName, "")
+	dirs := map[string]*TreeNode{"": root}
+
+	for _, file := range files {
+		parent := workspaceDirectory(dirs, path.Dir(file.Path))
+
+		node := BuildTreeJSON(file.Entries)
+		if node == nil {
+			node = &TreeNode{
+				Name:      "",
+				Kind:      "",
+				Path:      "",
+				Slug:      "",
+				Level:     "H0",
+				StartLine: 0,
+				EndLine:   0,
+				Children:  []*TreeNode{},
+			}
+		}
+		node.Name = path.Base(file.Path)
+		node.Kind = NodeFile
+		node.Path = file.Path
+
+		parent.Children = append(parent.Children, node)
+	}
+
+	sortWorkspaceNodes(root)
+	return root
+}
+
+// workspaceDirectory returns the node for a directory path, creating it and
+// its missing ancestors.
+func workspaceDirectory(dirs map[string]*TreeNode, dir string) *TreeNode {
+	if dir == "." {
+		dir = ""
+	}
+	if node, ok := dirs[dir]; ok {
+		return node
+	}
+
+	parent := workspaceDirectory(dirs, path.Dir(dir))
+	node := newDirectoryNode(path.Base(dir), dir)
+	parent.Children = append(parent.Children, node)
+	dirs[dir] = node
+	return node
+}
+
+// newDirectoryNode creates an empty directory node.
+func newDirectoryNode(name, dirPath string) *TreeNode {
+	return &TreeNode{
+		Name:      name,
+		Kind:      NodeDirectory,
+		Path:      dirPath,
+		Slug:      "",
+		Level:     "",
+		StartLine: 0,
+		EndLine:   0,
+		Children:  []*TreeNode{},
+	}
+}
+
+// sortWorkspaceNodes orders the children of directory nodes: directories
+// before files, each by name. Heading order is left untouched.
+func sortWorkspaceNodes(node *TreeNode) {
+	if node.Kind != NodeDirectory {
+		return
+	}
+
+	sort.SliceStable(node.Children, func(i, j int) bool {
+		a, b := node.Children[i], node.Children[j]
+		if a.Kind != b.Kind {
+			return a.Kind == NodeDirectory
+		}
+		return a.Name < b.Name
+	})
+	for _, child := range node.Children {
+		sortWorkspaceNodes(child)
+	}
+}
@@ -15,6 +15,7 @@ type TagEntry struct {
 	End     int    // End line of section (from ctags JSON output)
 	Scope   string // Full scope with separators
 	Level   int    // Heading level (1-6)
+	Slug    string // GitHub-style anchor, unique within the file
 }
 
 // kindLevelMap maps ctags kind to heading level.
@@ -31,6 +32,7 @@ var kindLevelMap = map[string]int{ //nolint:gochecknoglobals // immutable lookup
 }
 
 // NewTagEntry creates a new TagEntry with level determined from kind.
+// The slug is derived from the name; AssignSlugs makes it unique.
 func NewTagEntry(
 	name, file, pattern, kind string,
 	line, end int,
@@ -46,33 +48,25 @@ func NewTagEntry(
 		End:     end,
 		Scope:   scope,
 		Level:   level,
+		Slug:    Slugify(name),
 	}
 }
 
 // FindSectionBounds finds the start and end line numbers for a section.
+// The query is a heading name or a heading path such as "Phase 2 > Testing"
+// (see ParseSectionQuery); the first matching section is used.
 // Uses the End field from ctags JSON output for accurate section boundaries.
 func FindSectionBounds(
 	entries []*TagEntry,
 	sectionQuery string,
 ) (startLine, endLine int, sectionName string, found bool) {
-	// Find matching section (case-insensitive substring match)
-	lowerQuery := strings.ToLower(sectionQuery)
-
-	for _, entry := range entries {
-		if strings.Contains(strings.ToLower(entry.Name), lowerQuery) {
-			startLine = entry.Line
-			endLine = entry.End // Use End field from ctags JSON
-			sectionName = entry.Name
-			found = true
-			break
-		}
-	}
-
-	if !found {
+	matches := FindSections(entries, ParseSectionQuery(sectionQuery, 0))
+	if len(matches) == 0 {
 		return 0, 0, "", false
 	}
 
-	return startLine, endLine, sectionName, true
+	entry := matches[0]
+	return entry.Line, entry.End, entry.Name, true
 }
 
 // FilterByLevel filters entries by heading level.
@@ -87,21 +81,37 @@ func FilterByLevel(entries []*TagEntry, level int) []*TagEntry {
 }
 
 // FilterByPattern filters entries by a pattern (case-insensitive substring match).
+// Use FilterByMatcher for regex, glob or case-sensitive matching.
 func FilterByPattern(entries []*TagEntry, pattern string) []*TagEntry {
 	if pattern == "" {
 		return entries
 	}
 
+	return FilterByMatcher(entries, substringMatcher(pattern))
+}
+
+// FilterByMatcher filters entries whose name matches the matcher.
+func FilterByMatcher(entries []*TagEntry, matcher *NameMatcher) []*TagEntry {
 	var filtered []*TagEntry
-	lowerPattern := strings.ToLower(pattern)
 	for _, entry := range entries {
-		if strings.Contains(strings.ToLower(entry.Name), lowerPattern) {
+		if matcher.Match(entry.Name) {
 			filtered = append(filtered, entry)
 		}
 	}
 	return filtered
 }
 
+// substringMatcher returns a case-insensitive substring matcher, which
+// cannot fail to compile.
+func substringMatcher(pattern string) *NameMatcher {
+	return &NameMatcher{
+		mode:          MatchSubstring,
+		query:         strings.ToLower(strings.TrimSpace(pattern)),
+		caseSensitive: false,
+		re:            nil,
+	}
+}
+
 // SortByLine sorts entries by line number in ascending order.
 // This is useful after parsing to ensure entries are in document order.
 func SortByLine(entries []*TagEntry) {
@@ -116,6 +126,9 @@ func SortByLine(entries []*TagEntry) {
 //
 // Example: If searching for "Testing" matches "Section 2.1: Testing", the
 // result will include "Section 2" (parent) even if it doesn't match the pattern.
+//
+// The pattern is a case-insensitive substring; use FilterByMatcherWithParents
+// for other match modes.
 func FilterByPatternWithParents(
 	entries []*TagEntry,
 	pattern string,
@@ -124,12 +137,20 @@ func FilterByPatternWithParents(
 		return entries
 	}
 
+	return FilterByMatcherWithParents(entries, substringMatcher(pattern))
+}
+
+// FilterByMatcherWithParents is FilterByPatternWithParents with an arbitrary
+// NameMatcher.
+func FilterByMatcherWithParents(
+	entries []*TagEntry,
+	matcher *NameMatcher,
+) []*TagEntry {
 	// First pass: identify all matching entries and their descendants
 	matchingIndices := make(map[int]bool)
-	lowerPattern := strings.ToLower(pattern)
 
 	for i, entry := range entries {
-		if strings.Contains(strings.ToLower(entry.Name), lowerPattern) {
+		if matcher.Match(entry.Name) {
 			matchingIndices[i] = true
 		}
 	}
@@ -0,0 +1,112 @@
+package ctags
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"time"
+)
+
+// ValidationMode selects how CacheManager decides whether a cached entry is
+// still valid for the file on disk.
+type ValidationMode string
+
+const (
+	// ValidateMtime trusts the modification time alone. Cheapest, but misses
+	// edits within the filesystem's mtime granularity and files replaced
+	// with an identical mtime (network filesystems, git checkout).
+	ValidateMtime ValidationMode = "mtime"
+
+	// ValidateSizeMtime requires both the size and the modification time to
+	// match, catching most same-mtime rewrites without reading the file.
+	ValidateSizeMtime ValidationMode = "size+mtime"
+
+	// ValidateHash compares the size and a hash of the file content on every
+	// lookup. It reads the file each time but never serves stale headings,
+	// and keeps serving hits when only the mtime changed.
+	ValidateHash ValidationMode = "hash"
+)
+
+// validationModes lists every ValidationMode, in order of increasing cost.
+func validationModes() []ValidationMode {
+	return []ValidationMode{ValidateMtime, ValidateSizeMtime, ValidateHash}
+}
+
+// ParseValidationMode converts a string to a ValidationMode.
+// Returns ErrInvalidValidationMode for unknown values.
+func ParseValidationMode(value string) (ValidationMode, error) {
+	mode := ValidationMode(value)
+	switch mode {
+	case ValidateMtime, ValidateSizeMtime, ValidateHash:
+		return mode, nil
+	default:
+		return "", fmt.Errorf(
+			"%w: %s (must be 'mtime', 'size+mtime' or 'hash')",
+			ErrInvalidValidationMode,
+			value,
+		)
+	}
+}
+
+// ContentHash returns a fast, non-cryptographic hash (64-bit FNV-1a, as 16
+// hex digits) of file content. It detects content changes; it is not meant
+// to resist deliberate collisions.
+func ContentHash(content []byte) string {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write(content) // hash.Hash never returns an error
+	return fmt.Sprintf("%016x", hasher.Sum64())
+}
+
+// fileFingerprint identifies a version of a file for cache validation.
+type fileFingerprint struct {
+	ModTime time.Time
+	Size    int64
+	Hash    string // Content hash, only computed in ValidateHash mode
+}
+
+// newFileFingerprint builds the fingerprint of a file for the given mode,
+// reading its content only when the mode needs a hash.
+func newFileFingerprint(
+	filePath string,
+	stat os.FileInfo,
+	mode ValidationMode,
+) (fileFingerprint, error) {
+	fingerprint := fileFingerprint{
+		ModTime: stat.ModTime(),
+		Size:    stat.Size(),
+		Hash:    "",
+	}
+
+	if mode == ValidateHash {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return fileFingerprint{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		fingerprint.Size = int64(len(content))
+		fingerprint.Hash = ContentHash(content)
+	}
+
+	return fingerprint, nil
+}
+
+// matches reports whether a cached entry is valid for the fingerprint under
+// the given mode.
+func (f fileFingerprint) matches(entry *CacheEntry, mode ValidationMode) bool {
+	switch mode {
+	case ValidateSizeMtime:
+		return entry.ModTime.Equal(f.ModTime) && entry.Size == f.Size
+	case ValidateHash:
+		return entry.Hash != "" && entry.Size == f.Size && entry.Hash == f.Hash
+	case ValidateMtime:
+		return entry.ModTime.Equal(f.ModTime)
+	default:
+		return entry.ModTime.Equal(f.ModTime)
+	}
+}
+
+// equal reports whether two fingerprints describe the same file version.
+func (f fileFingerprint) equal(other fileFingerprint) bool {
+	return f.ModTime.Equal(other.ModTime) &&
+		f.Size == other.Size &&
+		f.Hash == other.Hash
+}
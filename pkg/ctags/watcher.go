@@ -0,0 +1,262 @@
+package ctags
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// WatchAction selects what a Watcher does when a watched file changes.
+type WatchAction string
+
+const (
+	// WatchInvalidate drops the cached headings; the next request parses
+	// the file again.
+	WatchInvalidate WatchAction = "invalidate"
+
+	// WatchReparse parses the file again as soon as it has been written,
+	// so that the next request is served from memory.
+	WatchReparse WatchAction = "reparse"
+)
+
+// ParseWatchAction converts a string to a WatchAction.
+// An empty string selects WatchReparse.
+//
+// Errors include: ErrInvalidWatchAction.
+func ParseWatchAction(value string) (WatchAction, error) {
+	switch action := WatchAction(value); action {
+	case WatchInvalidate, WatchReparse:
+		return action, nil
+	case "":
+		return WatchReparse, nil
+	default:
+		return "", fmt.Errorf(
+			"%w: %q (must be %q or %q)",
+			ErrInvalidWatchAction,
+			value,
+			WatchInvalidate,
+			WatchReparse,
+		)
+	}
+}
+
+// WatcherOptions configures a Watcher.
+type WatcherOptions struct {
+	// Roots are the directories watched recursively. Hidden directories
+	// (".git", ".cache", ...) below a root are skipped.
+	Roots []string
+
+	// Action selects whether changed files are invalidated or re-parsed.
+	// The zero value behaves like WatchReparse.
+	Action WatchAction
+
+	// Logger receives watch errors and queue overflows. Nil disables
+	// logging.
+	Logger *slog.Logger
+}
+
+// Watcher keeps a CacheManager in sync with the file system using inotify.
+// While a watcher is running, cached headings of files in watched
+// directories are served without checking the file on disk: write, rename
+// and delete events invalidate (or re-parse) them instead.
+//
+// Watchers are only available on Linux; elsewhere NewWatcher returns
+// ErrWatcherUnsupported and the cache keeps validating files on every
+// request.
+type Watcher struct {
+	cache   *CacheManager
+	roots   []string // Absolute, cleaned root directories
+	action  WatchAction
+	logger  *slog.Logger
+	inotify *os.File // Inotify instance (Linux only)
+	mu      sync.RWMutex
+	dirs    map[string]int // Watch descriptor by watched directory
+	paths   map[int]string // Watched directory by watch descriptor
+	started atomic.Bool    // Start was called
+	running atomic.Bool    // The event loop is processing events
+	done    chan struct{}  // Closed when the event loop exits
+}
+
+// newWatcher creates a watcher with absolute roots but no watches.
+func newWatcher(cache *CacheManager, options WatcherOptions) (*Watcher, error) {
+	if len(options.Roots) == 0 {
+		return nil, ErrNoWatchRoots
+	}
+
+	action, err := ParseWatchAction(string(options.Action))
+	if err != nil {
+		return nil, err
+	}
+
+	roots := make([]string, 0, len(options.Roots))
+	for _, root := range options.Roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve watch root: %w", err)
+		}
+
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat watch root: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%w: %s", ErrNotADirectory, root)
+		}
+		roots = append(roots, abs)
+	}
+
+	logger := options.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	return &Watcher{
+		cache:   cache,
+		roots:   roots,
+		action:  action,
+		logger:  logger,
+		inotify: nil,
+		mu:      sync.RWMutex{},
+		dirs:    make(map[string]int),
+		paths:   make(map[int]string),
+		started: atomic.Bool{},
+		running: atomic.Bool{},
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Roots returns the absolute root directories.
+func (w *Watcher) Roots() []string {
+	return append([]string(nil), w.roots...)
+}
+
+// Action returns the configured WatchAction.
+func (w *Watcher) Action() WatchAction {
+	return w.action
+}
+
+// Dirs returns the number of directories currently watched.
+func (w *Watcher) Dirs() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.dirs)
+}
+
+// covers reports whether changes to the file at absPath are reported by the
+// running watcher. Paths through symbolic links are never covered: edits to
+// a link target raise events only in the target's directory, and under the
+// target's path.
+func (w *Watcher) covers(absPath string) bool {
+	if !w.running.Load() {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err != nil ||
+		resolved != absPath {
+		return false
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	_, ok := w.dirs[filepath.Dir(absPath)]
+	return ok
+}
+
+// Prewarm parses every markdown file under the roots into the cache and
+// returns the number of files cached. Files that fail to parse are logged
+// and skipped. Call it after Start so that the prewarmed entries are served
+// from memory.
+func (w *Watcher) Prewarm(ctx context.Context) (int, error) {
+	warmed := 0
+	for _, root := range w.roots {
+		err := filepath.WalkDir(
+			root,
+			func(path string, entry fs.DirEntry, err error) error {
+				if err != nil {
+					w.logger.Warn("Failed to read directory while prewarming",
+						"path", path,
+						"error", err,
+					)
+					return nil
+				}
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return ctxErr
+				}
+
+				if entry.IsDir() {
+					if path != root && skipDirectory(entry.Name()) {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if !entry.Type().IsRegular() || !IsMarkdownFile(path) {
+					return nil
+				}
+
+				if _, err := w.cache.GetTags(ctx, path); err != nil {
+					w.logger.Warn("Failed to prewarm file",
+						"path", path,
+						"error", err,
+					)
+					return nil
+				}
+				warmed++
+				return nil
+			},
+		)
+		if err != nil {
+			return warmed, fmt.Errorf("prewarm interrupted: %w", err)
+		}
+	}
+
+	return warmed, nil
+}
+
+// fileChanged applies the watch action to a file that was written or
+// created. Only files that were cached, or markdown files, are re-parsed.
+func (w *Watcher) fileChanged(ctx context.Context, absPath string) {
+	keys := w.cache.invalidatePath(absPath, false)
+	if w.action != WatchReparse {
+		return
+	}
+
+	if len(keys) == 0 && IsMarkdownFile(absPath) {
+		keys = []string{absPath}
+	}
+	for _, key := range keys {
+		if _, err := w.cache.GetTags(ctx, key); err != nil {
+			w.logger.Warn("Failed to re-parse changed file",
+				"path", key,
+				"error", err,
+			)
+		}
+	}
+}
+
+// fileRemoved drops the cached headings of a deleted or renamed file, or of
+// every file below a removed directory.
+func (w *Watcher) fileRemoved(absPath string, isDir bool) {
+	w.cache.invalidatePath(absPath, isDir)
+}
+
+// IsMarkdownFile reports whether the path has a markdown file extension
+// (.md or .markdown, case-insensitive).
+func IsMarkdownFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	default:
+		return false
+	}
+}
+
+// skipDirectory reports whether a directory below a watch or workspace root
+// is skipped: hidden directories such as .git are never scanned.
+func skipDirectory(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
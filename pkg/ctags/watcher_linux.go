@@ -0,0 +1,267 @@
+//go:build linux
+
+package ctags
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+const (
+	// inotifyBufferSize holds many events with names up to NAME_MAX bytes.
+	inotifyBufferSize = 64 * 1024
+
+	// watchMask selects the inotify events a Watcher subscribes to.
+	watchMask = syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+		syscall.IN_CREATE | syscall.IN_DELETE |
+		syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+		syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF |
+		syscall.IN_ONLYDIR | syscall.IN_EXCL_UNLINK
+)
+
+// inotifyEvent is a decoded inotify_event.
+type inotifyEvent struct {
+	wd   int
+	mask uint32
+	name string
+}
+
+// NewWatcher creates an inotify watcher for the roots and their
+// subdirectories. Call Start to begin processing events and Close to
+// release the inotify instance.
+//
+// Errors include: ErrNoWatchRoots, ErrInvalidWatchAction and
+// ErrNotADirectory.
+func NewWatcher(cache *CacheManager, options WatcherOptions) (*Watcher, error) {
+	w, err := newWatcher(cache, options)
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create inotify instance: %w", err)
+	}
+	// A non-blocking descriptor is handled by the runtime poller, so that
+	// Close interrupts a pending Read
+	w.inotify = os.NewFile(uintptr(fd), "inotify")
+
+	for _, root := range w.roots {
+		if err := w.addTree(root); err != nil {
+			_ = w.inotify.Close()
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// Start attaches the watcher to its cache and processes events in a
+// goroutine until ctx is cancelled or Close is called.
+func (w *Watcher) Start(ctx context.Context) {
+	w.started.Store(true)
+	w.running.Store(true)
+	w.cache.attachWatcher(w)
+
+	go func() {
+		defer close(w.done)
+		defer w.cache.detachWatcher(w)
+		defer w.running.Store(false)
+
+		stop := context.AfterFunc(ctx, func() {
+			_ = w.inotify.Close()
+		})
+		defer stop()
+
+		w.loop(ctx)
+	}()
+}
+
+// Close stops the watcher and waits for its event loop to exit. Cached
+// entries are validated against the file system again afterwards.
+func (w *Watcher) Close() error {
+	err := w.inotify.Close()
+	if w.started.Load() {
+		<-w.done
+	}
+	if err != nil && !errors.Is(err, os.ErrClosed) {
+		return fmt.Errorf("failed to close inotify instance: %w", err)
+	}
+	return nil
+}
+
+// loop reads and dispatches events until the inotify file is closed.
+func (w *Watcher) loop(ctx context.Context) {
+	buf := make([]byte, inotifyBufferSize)
+	for {
+		n, err := w.inotify.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				w.logger.Error("Failed to read inotify events", "error", err)
+			}
+			return
+		}
+
+		for _, event := range decodeInotifyEvents(buf[:n]) {
+			w.handle(ctx, event)
+		}
+	}
+}
+
+// handle applies a single event to the cache and the watch set.
+func (w *Watcher) handle(ctx context.Context, event inotifyEvent) {
+	if event.mask&syscall.IN_Q_OVERFLOW != 0 {
+		w.logger.Warn("Inotify queue overflow, revalidating cached files")
+		w.cache.untrustAll()
+		return
+	}
+
+	w.mu.RLock()
+	dir, ok := w.paths[event.wd]
+	w.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	if event.mask&(syscall.IN_IGNORED|syscall.IN_DELETE_SELF|
+		syscall.IN_MOVE_SELF) != 0 {
+		// The directory itself is gone or moved; its entries were reported
+		// by the parent, unless it is a root
+		w.removeTree(dir)
+		w.fileRemoved(dir, true)
+		return
+	}
+
+	path := filepath.Join(dir, event.name)
+	isDir := event.mask&syscall.IN_ISDIR != 0
+
+	switch {
+	case isDir && event.mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+		if skipDirectory(event.name) {
+			return
+		}
+		if err := w.addTree(path); err != nil {
+			w.logger.Warn("Failed to watch new directory",
+				"path", path,
+				"error", err,
+			)
+		}
+	case isDir && event.mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+		w.removeTree(path)
+		w.fileRemoved(path, true)
+	case isDir:
+		return
+	case event.mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+		w.fileRemoved(path, false)
+	case event.mask&(syscall.IN_CLOSE_WRITE|syscall.IN_MOVED_TO) != 0:
+		w.fileChanged(ctx, path)
+	case event.mask&syscall.IN_MODIFY != 0:
+		// Still being written: validate on disk until the file is closed
+		w.cache.untrustPath(path)
+	}
+}
+
+// addTree watches a directory and its non-hidden subdirectories.
+func (w *Watcher) addTree(root string) error {
+	return filepath.WalkDir(
+		root,
+		func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if path == root {
+					return fmt.Errorf("failed to read directory: %w", err)
+				}
+				return nil // Removed or unreadable subdirectory
+			}
+			if !entry.IsDir() {
+				return nil
+			}
+			if path != root && skipDirectory(entry.Name()) {
+				return filepath.SkipDir
+			}
+
+			var wd int
+			err = w.control(func(fd int) error {
+				var addErr error
+				wd, addErr = syscall.InotifyAddWatch(fd, path, watchMask)
+				return addErr
+			})
+			if err != nil {
+				return fmt.Errorf("failed to watch %s: %w", path, err)
+			}
+
+			w.mu.Lock()
+			w.dirs[path] = wd
+			w.paths[wd] = path
+			w.mu.Unlock()
+			return nil
+		},
+	)
+}
+
+// removeTree stops watching a directory and its subdirectories.
+func (w *Watcher) removeTree(root string) {
+	prefix := root + string(filepath.Separator)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for path, wd := range w.dirs {
+		if path != root && !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		delete(w.dirs, path)
+		delete(w.paths, wd)
+		_ = w.control(func(fd int) error {
+			_, err := syscall.InotifyRmWatch(fd, uint32(wd))
+			return err
+		})
+	}
+}
+
+// control runs fn with the inotify descriptor. Unlike os.File.Fd, it keeps
+// the descriptor in non-blocking mode and fails once it has been closed.
+func (w *Watcher) control(fn func(fd int) error) error {
+	conn, err := w.inotify.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("failed to access inotify instance: %w", err)
+	}
+
+	var fnErr error
+	if err := conn.Control(func(fd uintptr) {
+		fnErr = fn(int(fd))
+	}); err != nil {
+		return fmt.Errorf("failed to access inotify instance: %w", err)
+	}
+	return fnErr
+}
+
+// decodeInotifyEvents splits a buffer read from an inotify descriptor into
+// events.
+func decodeInotifyEvents(buf []byte) []inotifyEvent {
+	var events []inotifyEvent
+	for offset := 0; offset+syscall.SizeofInotifyEvent <= len(buf); {
+		header := buf[offset : offset+syscall.SizeofInotifyEvent]
+		nameLen := int(binary.NativeEndian.Uint32(header[12:16]))
+
+		nameStart := offset + syscall.SizeofInotifyEvent
+		if nameStart+nameLen > len(buf) {
+			break
+		}
+		name := string(buf[nameStart : nameStart+nameLen])
+
+		events = append(events, inotifyEvent{
+			wd:   int(int32(binary.NativeEndian.Uint32(header[0:4]))),
+			mask: binary.NativeEndian.Uint32(header[4:8]),
+			name: strings.TrimRight(name, "\x00"),
+		})
+		offset = nameStart + nameLen
+	}
+	return events
+}
@@ -0,0 +1,226 @@
+//go:build linux
+
+package ctags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// watchTimeout bounds how long tests wait for inotify events.
+	watchTimeout = 2 * time.Second
+	watchTick    = 10 * time.Millisecond
+)
+
+// startWatcher watches root with a fresh cache and stops it on cleanup.
+func startWatcher(
+	t *testing.T,
+	root string,
+	action WatchAction,
+) (*CacheManager, *Watcher) {
+	t.Helper()
+
+	cache := NewCacheManager()
+	watcher, err := NewWatcher(cache, WatcherOptions{
+		Roots:  []string{root},
+		Action: action,
+		Logger: nil,
+	})
+	require.NoError(t, err)
+
+	watcher.Start(context.Background())
+	t.Cleanup(func() {
+		assert.NoError(t, watcher.Close())
+	})
+	return cache, watcher
+}
+
+// writeFile writes content to dir/name, creating parent directories.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestParseWatchAction(t *testing.T) {
+	for _, value := range []string{"invalidate", "reparse"} {
+		action, err := ParseWatchAction(value)
+		require.NoError(t, err)
+		assert.Equal(t, WatchAction(value), action)
+	}
+
+	action, err := ParseWatchAction("")
+	require.NoError(t, err)
+	assert.Equal(t, WatchReparse, action)
+
+	_, err = ParseWatchAction("poll")
+	require.ErrorIs(t, err, ErrInvalidWatchAction)
+}
+
+func TestNewWatcher_InvalidRoots(t *testing.T) {
+	cache := NewCacheManager()
+
+	_, err := NewWatcher(cache, WatcherOptions{
+		Roots:  nil,
+		Action: WatchReparse,
+		Logger: nil,
+	})
+	require.ErrorIs(t, err, ErrNoWatchRoots)
+
+	file := createTestMarkdownFile(t, "# A\n")
+	_, err = NewWatcher(cache, WatcherOptions{
+		Roots:  []string{file},
+		Action: WatchReparse,
+		Logger: nil,
+	})
+	require.ErrorIs(t, err, ErrNotADirectory)
+}
+
+func TestWatcher_PrewarmServesFromMemory(t *testing.T) {
+	root := t.TempDir()
+	readme := writeFile(t, root, "README.md", "# Readme\n")
+	writeFile(t, root, "docs/guide.markdown", "# Guide\n## Install\n")
+	writeFile(t, root, "docs/notes.txt", "# Not markdown\n")
+	writeFile(t, root, ".git/info.md", "# Hidden\n")
+
+	cache, watcher := startWatcher(t, root, WatchReparse)
+	assert.Equal(t, 2, watcher.Dirs()) // root and docs, not .git
+
+	warmed, err := watcher.Prewarm(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, warmed)
+	assert.Equal(t, 2, cache.Size())
+
+	tags, err := cache.GetTags(context.Background(), readme)
+	require.NoError(t, err)
+	assert.Equal(t, "Readme", tags[0].Name)
+
+	stats := cache.DetailedStats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+}
+
+func TestWatcher_ReparseOnWrite(t *testing.T) {
+	root := t.TempDir()
+	file := writeFile(t, root, "doc.md", "# Before\n")
+
+	cache, _ := startWatcher(t, root, WatchReparse)
+	_, err := cache.GetTags(context.Background(), file)
+	require.NoError(t, err)
+
+	modifyMarkdownFile(t, file, "# After\n")
+
+	// The watcher parses the file without a request
+	require.Eventually(t, func() bool {
+		return cache.DetailedStats().Misses == 2
+	}, watchTimeout, watchTick)
+
+	tags, err := cache.GetTags(context.Background(), file)
+	require.NoError(t, err)
+	assert.Equal(t, "After", tags[0].Name)
+	assert.Equal(t, uint64(2), cache.DetailedStats().Misses)
+}
+
+func TestWatcher_SymlinkedFileIsValidated(t *testing.T) {
+	root := t.TempDir()
+	target := writeFile(t, t.TempDir(), "target.md", "# Before\n")
+	link := filepath.Join(root, "link.md")
+	require.NoError(t, os.Symlink(target, link))
+
+	cache, _ := startWatcher(t, root, WatchReparse)
+	tags, err := cache.GetTags(context.Background(), link)
+	require.NoError(t, err)
+	assert.Equal(t, "Before", tags[0].Name)
+
+	// The target is outside the watched root, so no event is raised; the
+	// entry is validated against the file system instead
+	modifyMarkdownFile(t, target, "# After\n")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(target, later, later))
+
+	tags, err = cache.GetTags(context.Background(), link)
+	require.NoError(t, err)
+	assert.Equal(t, "After", tags[0].Name)
+}
+
+func TestWatcher_InvalidateOnWriteAndDelete(t *testing.T) {
+	root := t.TempDir()
+	first := writeFile(t, root, "first.md", "# First\n")
+	second := writeFile(t, root, "sub/second.md", "# Second\n")
+
+	cache, _ := startWatcher(t, root, WatchInvalidate)
+	for _, file := range []string{first, second} {
+		_, err := cache.GetTags(context.Background(), file)
+		require.NoError(t, err)
+	}
+
+	modifyMarkdownFile(t, first, "# Changed\n")
+	require.Eventually(t, func() bool {
+		return cache.Size() == 1
+	}, watchTimeout, watchTick)
+
+	tags, err := cache.GetTags(context.Background(), first)
+	require.NoError(t, err)
+	assert.Equal(t, "Changed", tags[0].Name)
+
+	// Removing a directory drops the files below it
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "sub")))
+	require.Eventually(t, func() bool {
+		return cache.Size() == 1
+	}, watchTimeout, watchTick)
+
+	require.NoError(t, os.Rename(first, filepath.Join(root, "renamed.md")))
+	require.Eventually(t, func() bool {
+		return cache.Size() == 0
+	}, watchTimeout, watchTick)
+}
+
+func TestWatcher_WatchesNewDirectories(t *testing.T) {
+	root := t.TempDir()
+	cache, watcher := startWatcher(t, root, WatchReparse)
+
+	require.NoError(t, os.Mkdir(filepath.Join(root, "new"), 0o755))
+	require.Eventually(t, func() bool {
+		return watcher.Dirs() == 2
+	}, watchTimeout, watchTick)
+
+	// New markdown files are parsed as soon as they are written
+	writeFile(t, root, "new/doc.md", "# Doc\n")
+	require.Eventually(t, func() bool {
+		return cache.Size() == 1
+	}, watchTimeout, watchTick)
+}
+
+func TestWatcher_CloseRestoresValidation(t *testing.T) {
+	root := t.TempDir()
+	file := writeFile(t, root, "doc.md", "# Before\n")
+
+	cache := NewCacheManager()
+	watcher, err := NewWatcher(cache, WatcherOptions{
+		Roots:  []string{root},
+		Action: WatchInvalidate,
+		Logger: nil,
+	})
+	require.NoError(t, err)
+	watcher.Start(context.Background())
+
+	_, err = cache.GetTags(context.Background(), file)
+	require.NoError(t, err)
+	require.NoError(t, watcher.Close())
+
+	// Changes are no longer reported, so the file is checked on disk again
+	modifyMarkdownFile(t, file, "# After\n")
+	tags, err := cache.GetTags(context.Background(), file)
+	require.NoError(t, err)
+	assert.Equal(t, "After", tags[0].Name)
+}
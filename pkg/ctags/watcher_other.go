@@ -0,0 +1,26 @@
+//go:build !linux
+
+package ctags
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// NewWatcher reports ErrWatcherUnsupported: file watching requires inotify,
+// which is only available on Linux.
+func NewWatcher(cache *CacheManager, options WatcherOptions) (*Watcher, error) {
+	if _, err := newWatcher(cache, options); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("%w on %s", ErrWatcherUnsupported, runtime.GOOS)
+}
+
+// Start does nothing; NewWatcher never returns a watcher on this platform.
+func (w *Watcher) Start(_ context.Context) {}
+
+// Close does nothing; NewWatcher never returns a watcher on this platform.
+func (w *Watcher) Close() error {
+	return nil
+}
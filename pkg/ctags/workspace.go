@@ -0,0 +1,233 @@
+package ctags
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultIncludeGlobs returns the include globs used when none are given:
+// every markdown file at any depth.
+func DefaultIncludeGlobs() []string {
+	return []string{"*.md", "*.markdown"}
+}
+
+// PathFilter selects workspace files by include and exclude globs matched
+// against slash-separated paths relative to the workspace root.
+//
+// Glob syntax: '*' matches within a path segment, '?' one character,
+// '[abc]' a character class and '**' any number of segments
+// ("docs/**/*.md"). A glob without '/' matches the base name at any depth,
+// so "*.md" selects all markdown files and "drafts" excludes every
+// directory named drafts.
+type PathFilter struct {
+	include []pathGlob
+	exclude []pathGlob
+}
+
+// pathGlob is a compiled glob.
+type pathGlob struct {
+	re       *regexp.Regexp
+	baseName bool // Matched against the base name (glob without '/')
+}
+
+// NewPathFilter compiles include and exclude globs. Empty include globs
+// select DefaultIncludeGlobs.
+//
+// Errors include: ErrInvalidPattern.
+func NewPathFilter(include, exclude []string) (*PathFilter, error) {
+	if len(include) == 0 {
+		include = DefaultIncludeGlobs()
+	}
+
+	includeRes, err := compilePathGlobs(include)
+	if err != nil {
+		return nil, err
+	}
+	excludeRes, err := compilePathGlobs(exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PathFilter{include: includeRes, exclude: excludeRes}, nil
+}
+
+// Includes reports whether a file at relPath is selected: it matches an
+// include glob and no exclude glob.
+func (f *PathFilter) Includes(relPath string) bool {
+	return matchesAnyGlob(f.include, relPath) && !f.Excludes(relPath)
+}
+
+// Excludes reports whether relPath matches an exclude glob. Excluded
+// directories are not scanned.
+func (f *PathFilter) Excludes(relPath string) bool {
+	return matchesAnyGlob(f.exclude, relPath)
+}
+
+// WorkspaceFiles returns the files below root selected by the filter, as
+// sorted slash-separated paths relative to root. Hidden directories and
+// directories matching an exclude glob are skipped. Symbolic links to
+// regular files are included; linked directories are not followed.
+//
+// Errors include: ErrFileNotFound, ErrNotADirectory.
+func WorkspaceFiles(root string, filter *PathFilter) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, root)
+		}
+		return nil, fmt.Errorf("failed to stat directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrNotADirectory, root)
+	}
+
+	var files []string
+	err = filepath.WalkDir(
+		root,
+		func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if filePath == root {
+					return err
+				}
+				return nil // Unreadable subdirectory
+			}
+			if filePath == root {
+				return nil
+			}
+
+			rel, err := filepath.Rel(root, filePath)
+			if err != nil {
+				return fmt.Errorf("failed to resolve path: %w", err)
+			}
+			rel = filepath.ToSlash(rel)
+
+			if entry.IsDir() {
+				if skipDirectory(entry.Name()) || filter.Excludes(rel) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !isRegularFile(filePath, entry) || !filter.Includes(rel) {
+				return nil
+			}
+			files = append(files, rel)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan directory: %w", err)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// isRegularFile reports whether a directory entry is a regular file or a
+// symbolic link to one.
+func isRegularFile(filePath string, entry fs.DirEntry) bool {
+	if entry.Type().IsRegular() {
+		return true
+	}
+	if entry.Type()&fs.ModeSymlink == 0 {
+		return false
+	}
+
+	info, err := os.Stat(filePath)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// compilePathGlobs compiles path globs into anchored regular expressions.
+func compilePathGlobs(globs []string) ([]pathGlob, error) {
+	compiled := make([]pathGlob, 0, len(globs))
+	for _, glob := range globs {
+		glob = strings.TrimSpace(glob)
+		if glob == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(pathGlobToRegexp(glob))
+		if err != nil {
+			return nil, fmt.Errorf(
+				"%w: glob %q: %w",
+				ErrInvalidPattern,
+				glob,
+				err,
+			)
+		}
+		compiled = append(compiled, pathGlob{
+			re:       re,
+			baseName: !strings.Contains(glob, "/"),
+		})
+	}
+	return compiled, nil
+}
+
+// matchesAnyGlob reports whether relPath matches one of the compiled globs.
+// Globs without a '/' are matched against the base name.
+func matchesAnyGlob(globs []pathGlob, relPath string) bool {
+	base := path.Base(relPath)
+	for _, glob := range globs {
+		subject := relPath
+		if glob.baseName {
+			subject = base
+		}
+		if glob.re.MatchString(subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathGlobToRegexp converts a path glob to an anchored regular expression.
+// Unlike globToRegexp, wildcards do not cross '/' except for '**'.
+func pathGlobToRegexp(glob string) string {
+	glob = strings.TrimPrefix(glob, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	for i := 0; i < len(glob); i++ {
+		switch char := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += len("**/") - 1
+		case glob[i:] == "/**":
+			expr.WriteString("(?:/.*)?")
+			i += len("/**") - 1
+		case strings.HasPrefix(glob[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case char == '*':
+			expr.WriteString("[^/]*")
+		case char == '?':
+			expr.WriteString("[^/]")
+		case char == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		case char == '\\' && i+1 < len(glob):
+			i++
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+
+	expr.WriteString("$")
+	return expr.String()
+}
@@ -0,0 +1,200 @@
+package ctags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createWorkspace creates files (relative path to content) below a new
+// temporary directory and returns the directory.
+func createWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for relPath, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(relPath))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return root
+}
+
+func TestPathFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		path     string
+		expected bool
+	}{
+		{"default top level", nil, nil, "README.md", true},
+		{"default nested", nil, nil, "docs/api/auth.markdown", true},
+		{"default skips other files", nil, nil, "docs/notes.txt", false},
+		{"anchored glob", []string{"docs/*.md"}, nil, "docs/a.md", true},
+		{"star stays in segment", []string{"docs/*.md"}, nil, "docs/x/a.md", false},
+		{"double star", []string{"docs/**/*.md"}, nil, "docs/x/y/a.md", true},
+		{"double star zero dirs", []string{"docs/**/*.md"}, nil, "docs/a.md", true},
+		{"leading slash", []string{"/README.md"}, nil, "sub/README.md", false},
+		{"exclude by name", nil, []string{"CHANGELOG.md"}, "a/CHANGELOG.md", false},
+		{"exclude tree", nil, []string{"archive/**"}, "archive/old/a.md", false},
+		{"class", []string{"v[0-9].md"}, nil, "v2.md", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewPathFilter(tt.include, tt.exclude)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filter.Includes(tt.path))
+		})
+	}
+}
+
+func TestWorkspaceFiles(t *testing.T) {
+	root := createWorkspace(t, map[string]string{
+		"README.md":           "# Readme\n",
+		"docs/guide.md":       "# Guide\n",
+		"docs/api/auth.md":    "# Auth\n",
+		"docs/notes.txt":      "notes\n",
+		"archive/old.md":      "# Old\n",
+		".git/description.md": "# Hidden\n",
+	})
+
+	filter, err := NewPathFilter(nil, []string{"archive"})
+	require.NoError(t, err)
+
+	files, err := WorkspaceFiles(root, filter)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"README.md",
+		"docs/api/auth.md",
+		"docs/guide.md",
+	}, files)
+
+	_, err = WorkspaceFiles(filepath.Join(root, "README.md"), filter)
+	require.ErrorIs(t, err, ErrNotADirectory)
+	_, err = WorkspaceFiles(filepath.Join(root, "missing"), filter)
+	require.ErrorIs(t, err, ErrFileNotFound)
+
+	_, err = NewPathFilter([]string{"[z-a].md"}, nil)
+	require.ErrorIs(t, err, ErrInvalidPattern)
+}
+
+func TestBuildWorkspaceTree(t *testing.T) {
+	files := []WorkspaceFile{
+		{Path: "README.md", Entries: ParseMarkdown([]byte("# Readme\n## Usage\n"), "README.md")},
+		{Path: "docs/api/auth.md", Entries: ParseMarkdown([]byte("# Auth\n"), "auth.md")},
+		{Path: "docs/empty.md", Entries: nil},
+	}
+
+	root := BuildWorkspaceTree("project", files)
+	assert.Equal(t, "project", root.Name)
+	assert.Equal(t, NodeDirectory, root.Kind)
+	require.Len(t, root.Children, 2)
+
+	// Directories come before files
+	docs := root.Children[0]
+	assert.Equal(t, "docs", docs.Name)
+	assert.Equal(t, NodeDirectory, docs.Kind)
+	require.Len(t, docs.Children, 2)
+	assert.Equal(t, "docs/api", docs.Children[0].Path)
+	assert.Equal(t, "empty.md", docs.Children[1].Name)
+	assert.Equal(t, NodeFile, docs.Children[1].Kind)
+	assert.Empty(t, docs.Children[1].Children)
+
+	auth := docs.Children[0].Children[0]
+	assert.Equal(t, "docs/api/auth.md", auth.Path)
+	require.Len(t, auth.Children, 1)
+	assert.Equal(t, "Auth", auth.Children[0].Name)
+	assert.Empty(t, auth.Children[0].Kind)
+
+	readme := root.Children[1]
+	assert.Equal(t, "README.md", readme.Name)
+	require.Len(t, readme.Children, 1)
+	assert.Equal(t, "Usage", readme.Children[0].Children[0].Name)
+}
+
+func TestGroupJSONTags(t *testing.T) {
+	jsonData := []byte(
+		`{"_type": "tag", "name": "A", "path": "a.md", "line": 1, "kind": "chapter"}
+{"_type": "tag", "name": "B", "path": "b.md", "line": 1, "kind": "chapter"}
+{"_type": "tag", "name": "B1", "path": "b.md", "line": 2, "kind": "section"}
+{"_type": "tag", "name": "X", "path": "other.md", "line": 1, "kind": "chapter"}
+`)
+
+	grouped, err := GroupJSONTags(jsonData, []string{"a.md", "b.md", "c.md"})
+	require.NoError(t, err)
+	require.Len(t, grouped, 3)
+	assert.Len(t, grouped["a.md"], 1)
+	require.Len(t, grouped["b.md"], 2)
+	assert.Equal(t, "B1", grouped["b.md"][1].Name)
+	assert.Equal(t, 2, grouped["b.md"][1].Level)
+	assert.Equal(t, "b.md", grouped["b.md"][1].File)
+	assert.Empty(t, grouped["c.md"])
+}
+
+func TestCacheManager_GetTagsBatch(t *testing.T) {
+	root := createWorkspace(t, map[string]string{
+		"a.md": "# A\n",
+		"b.md": "# B\n## B1\n",
+	})
+	files := []string{
+		filepath.Join(root, "a.md"),
+		filepath.Join(root, "b.md"),
+		filepath.Join(root, "missing.md"),
+	}
+
+	cache := NewCacheManager()
+	results, err := cache.GetTagsBatch(context.Background(), files)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, files[0], results[0].FilePath)
+	require.NoError(t, results[0].Err)
+	assert.Equal(t, "A", results[0].Tags[0].Name)
+	require.NoError(t, results[1].Err)
+	assert.Len(t, results[1].Tags, 2)
+	require.ErrorIs(t, results[2].Err, ErrFileNotFound)
+
+	// A second batch is served from the cache
+	_, err = cache.GetTagsBatch(context.Background(), files[:2])
+	require.NoError(t, err)
+	stats := cache.DetailedStats()
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, uint64(2), stats.Hits)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = cache.GetTagsBatch(ctx, files)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestCacheManager_GetTagsBatchCtags(t *testing.T) {
+	if !IsCtagsInstalled() {
+		t.Skip("ctags not installed, skipping test")
+	}
+
+	root := createWorkspace(t, map[string]string{
+		"a.md": "# A\n```\n# Not a heading\n```\n",
+		"b.md": "B\n===\n## B1\n",
+	})
+	files := []string{filepath.Join(root, "a.md"), filepath.Join(root, "b.md")}
+
+	cache := NewCacheManager()
+	results, err := cache.GetTagsBatch(context.Background(), files)
+	require.NoError(t, err)
+
+	for i, content := range []string{"# A\n```\n# Not a heading\n```\n", "B\n===\n## B1\n"} {
+		require.NoError(t, results[i].Err)
+		expected := ParseMarkdown([]byte(content), files[i])
+		require.Len(t, results[i].Tags, len(expected))
+		for j := range expected {
+			assert.Equal(t, expected[j].Name, results[i].Tags[j].Name)
+			assert.Equal(t, expected[j].End, results[i].Tags[j].End)
+		}
+	}
+}
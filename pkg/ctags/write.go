@@ -0,0 +1,134 @@
+package ctags
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileVersion identifies the content of a file read for editing. Edits
+// carry the version they were based on, so that changes made in between
+// are detected instead of overwritten.
+type FileVersion struct {
+	Hash    string // ContentHash of the content
+	ModTime time.Time
+	Size    int64
+}
+
+// ReadFileVersion reads a file and returns its content together with its
+// version. Content and version come from the same open file.
+//
+// Errors include: ErrFileNotFound.
+func ReadFileVersion(filePath string) ([]byte, FileVersion, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, FileVersion{}, fmt.Errorf(
+				"%w: %s",
+				ErrFileNotFound,
+				filePath,
+			)
+		}
+		return nil, FileVersion{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, FileVersion{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, FileVersion{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return content, FileVersion{
+		Hash:    ContentHash(content),
+		ModTime: stat.ModTime(),
+		Size:    int64(len(content)),
+	}, nil
+}
+
+// ReplaceFile replaces the content of a file that is expected to still have
+// the content hash expectedHash, and returns the new version. The content is
+// written to a temporary file that is renamed over the original, so readers
+// never observe a partial write; the file mode is kept and symbolic links
+// are followed. Cached headings of the file are invalidated.
+//
+// Writes through the same CacheManager are serialized, so that two edits
+// based on the same version cannot both succeed.
+//
+// Errors include: ErrFileNotFound, ErrFileChanged.
+func (cm *CacheManager) ReplaceFile(
+	filePath string,
+	content []byte,
+	expectedHash string,
+) (FileVersion, error) {
+	cm.writeMu.Lock()
+	defer cm.writeMu.Unlock()
+
+	target := filePath
+	if resolved, err := filepath.EvalSymlinks(filePath); err == nil {
+		target = resolved
+	}
+
+	_, version, err := ReadFileVersion(target)
+	if err != nil {
+		return FileVersion{}, err
+	}
+	if version.Hash != expectedHash {
+		return FileVersion{}, fmt.Errorf(
+			"%w: %s (expected hash %s, found %s)",
+			ErrFileChanged,
+			filePath,
+			expectedHash,
+			version.Hash,
+		)
+	}
+
+	stat, err := os.Stat(target)
+	if err != nil {
+		return FileVersion{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if err := writeFileAtomic(target, content, stat.Mode().Perm()); err != nil {
+		return FileVersion{}, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	for _, path := range cacheKeys(filePath, target) {
+		cm.InvalidateFile(path)
+	}
+
+	stat, err = os.Stat(target)
+	if err != nil {
+		return FileVersion{}, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return FileVersion{
+		Hash:    ContentHash(content),
+		ModTime: stat.ModTime(),
+		Size:    int64(len(content)),
+	}, nil
+}
+
+// cacheKeys returns the paths a file may be cached under: as given, as the
+// link target and as absolute paths.
+func cacheKeys(filePath, target string) []string {
+	keys := []string{filePath}
+	add := func(path string) {
+		for _, key := range keys {
+			if key == path {
+				return
+			}
+		}
+		keys = append(keys, path)
+	}
+
+	add(target)
+	for _, path := range []string{filePath, target} {
+		if abs, err := filepath.Abs(path); err == nil {
+			add(abs)
+		}
+	}
+	return keys
+}
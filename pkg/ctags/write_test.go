@@ -0,0 +1,86 @@
+package ctags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFileVersion(t *testing.T) {
+	root := createWorkspace(t, map[string]string{"a.md": "# A\n"})
+
+	content, version, err := ReadFileVersion(filepath.Join(root, "a.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# A\n", string(content))
+	assert.Equal(t, ContentHash(content), version.Hash)
+	assert.Equal(t, int64(4), version.Size)
+	assert.False(t, version.ModTime.IsZero())
+
+	_, _, err = ReadFileVersion(filepath.Join(root, "missing.md"))
+	assert.ErrorIs(t, err, ErrFileNotFound)
+}
+
+func TestCacheManager_ReplaceFile(t *testing.T) {
+	root := createWorkspace(t, map[string]string{"a.md": "# A\n"})
+	path := filepath.Join(root, "a.md")
+	require.NoError(t, os.Chmod(path, 0o600))
+
+	cm := NewCacheManager()
+	ctx := context.Background()
+	tags, err := cm.GetTags(ctx, path)
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+
+	_, version, err := ReadFileVersion(path)
+	require.NoError(t, err)
+
+	updated, err := cm.ReplaceFile(path, []byte("# B\n## C\n"), version.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, ContentHash([]byte("# B\n## C\n")), updated.Hash)
+	assert.Equal(t, 0, cm.Size(), "cached headings are invalidated")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left behind")
+
+	tags, err = cm.GetTags(ctx, path)
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	assert.Equal(t, "B", tags[0].Name)
+
+	// The version read before the first write is stale now
+	_, err = cm.ReplaceFile(path, []byte("# D\n"), version.Hash)
+	require.ErrorIs(t, err, ErrFileChanged)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# B\n## C\n", string(content))
+}
+
+func TestCacheManager_ReplaceFileFollowsSymlinks(t *testing.T) {
+	root := createWorkspace(t, map[string]string{"docs/a.md": "# A\n"})
+	target := filepath.Join(root, "docs", "a.md")
+	link := filepath.Join(root, "link.md")
+	require.NoError(t, os.Symlink(target, link))
+
+	cm := NewCacheManager()
+	_, version, err := ReadFileVersion(link)
+	require.NoError(t, err)
+	_, err = cm.ReplaceFile(link, []byte("# B\n"), version.Hash)
+	require.NoError(t, err)
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink, "link is kept")
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "# B\n", string(content))
+}
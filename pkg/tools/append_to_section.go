@@ -0,0 +1,223 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// Positions of appended content within the target section.
+const (
+	positionBodyStart  = "body_start"
+	positionBodyEnd    = "body_end"
+	positionSubtreeEnd = "subtree_end"
+)
+
+// MarkdownAppendToSectionArgs defines the input arguments.
+type MarkdownAppendToSectionArgs struct {
+	FilePath       string  `json:"file_path"                description:"Path to markdown file"                                                                                                                                                                 required:"true"`
+	SectionHeading string  `json:"section_heading"          description:"Target section: heading text (without # symbols), a heading path separated by ' > ' or an anchor such as '#task-3'"                                                                     required:"true"`
+	Occurrence     *int    `json:"occurrence,omitempty"     description:"Which match to use when several sections match (1=first). Default: 1"`
+	MatchMode      *string `json:"match_mode,omitempty"     description:"How heading names are compared: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive  *bool   `json:"case_sensitive,omitempty" description:"Compare heading names case-sensitively. Default: false"`
+	Strict         *bool   `json:"strict,omitempty"         description:"Fail with a list of candidate sections instead of using the first match when the heading is ambiguous. Default: false"`
+	Content        string  `json:"content"                  description:"Lines to insert. Headings in it must be deeper than the target section"                                                                                                                 required:"true"`
+	Position       *string `json:"position,omitempty"       description:"Where to insert: 'body_start' (right after the heading), 'body_end' (after the section's own text, before its first subsection) or 'subtree_end' (after its last subsection). Default: 'body_end'"`
+	BlankLine      *bool   `json:"blank_line,omitempty"     description:"Separate content from the adjacent text by a blank line. Use false to extend a list or paragraph. Headings are always separated. Default: true"`
+	ExpectedHash   *string `json:"expected_hash,omitempty"  description:"file_hash returned by a prior markdown_read_section or markdown_section_bounds. The edit fails if the file changed since. expected_hash or expected_mtime is required"`
+	ExpectedMtime  *string `json:"expected_mtime,omitempty" description:"mtime returned by a prior markdown_read_section or markdown_section_bounds (RFC 3339). The edit fails if the file changed since"`
+}
+
+// MarkdownAppendToSectionResponse defines the response structure.
+type MarkdownAppendToSectionResponse struct {
+	SectionName  string   `json:"section_name"`
+	Slug         string   `json:"slug"`
+	HeadingPath  []string `json:"heading_path"`
+	Position     string   `json:"position"`
+	ContentStart int      `json:"content_start"` // Inserted lines
+	ContentEnd   int      `json:"content_end"`
+	StartLine    int      `json:"start_line"` // Section bounds after the edit
+	EndLine      int      `json:"end_line"`
+	FileHash     string   `json:"file_hash"` // Version after the edit
+	Mtime        string   `json:"mtime"`
+}
+
+// RegisterMarkdownAppendToSection registers the markdown_append_to_section
+// tool.
+func RegisterMarkdownAppendToSection(srv server.Server) {
+	srv.Tool(
+		"markdown_append_to_section",
+		"Insert content into a section without rewriting it: right after its heading, at the end of its own text (before its first subsection) or after its last subsection. Blank lines at the insertion point are normalized, e.g. to add a bullet to a list use blank_line false. Requires expected_hash or expected_mtime from a prior read.",
+		handleAppendToSection,
+	)
+}
+
+// handleAppendToSection implements the markdown_append_to_section tool
+// logic.
+func handleAppendToSection(
+	_ *server.Context,
+	args MarkdownAppendToSectionArgs,
+) (interface{}, error) {
+	block := contentLines(args.Content)
+	if len(block) == 0 {
+		return nil, fmt.Errorf("%w: content is empty", ErrInvalidContent)
+	}
+
+	position := positionBodyEnd
+	if args.Position != nil && *args.Position != "" {
+		position = *args.Position
+	}
+
+	doc, err := loadDocument(args.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(
+		doc.version,
+		args.ExpectedHash,
+		args.ExpectedMtime,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(doc.entries) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoEntries, args.FilePath)
+	}
+
+	entry, err := resolveSection(doc.entries, sectionAddress{
+		Heading:       args.SectionHeading,
+		Occurrence:    args.Occurrence,
+		MatchMode:     args.MatchMode,
+		CaseSensitive: args.CaseSensitive,
+		Strict:        args.Strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSubheadings(
+		block,
+		entry.Name,
+		entry.Level,
+	); err != nil {
+		return nil, err
+	}
+
+	at, err := appendPoint(doc, entry, position)
+	if err != nil {
+		return nil, err
+	}
+	blankLine := args.BlankLine == nil || *args.BlankLine
+	lines, first := doc.insertContent(at, block, blankLine)
+	updated, err := doc.save("markdown_append_to_section", lines)
+	if err != nil {
+		return nil, err
+	}
+
+	section, ok := updated.sectionAt(entry.Line)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: no heading at line %d after the edit",
+			ErrSectionNotFound,
+			entry.Line,
+		)
+	}
+
+	return MarkdownAppendToSectionResponse{
+		SectionName:  section.Name,
+		Slug:         section.Slug,
+		HeadingPath:  section.HeadingPath(),
+		Position:     position,
+		ContentStart: first,
+		ContentEnd:   first + len(block) - 1,
+		StartLine:    section.Line,
+		EndLine:      section.End,
+		FileHash:     updated.version.Hash,
+		Mtime:        formatModTime(updated.version.ModTime),
+	}, nil
+}
+
+// appendPoint returns the line before which content is inserted at
+// position in the section entry. Content goes before the first line of
+// text after the heading, or after the last line of text of the section's
+// own body or of its subtree, so blank lines at the end of a section stay
+// between it and the next heading.
+//
+// Errors include: ErrInvalidPosition.
+func appendPoint(
+	doc *document,
+	entry *ctags.TagEntry,
+	position string,
+) (int, error) {
+	headingEnd := doc.headingEnd(entry)
+	bodyEnd := doc.bodyEnd(entry)
+
+	switch position {
+	case positionBodyStart:
+		for line := headingEnd + 1; line <= bodyEnd; line++ {
+			if !isBlankLine(doc.lines[line-1]) {
+				return line, nil
+			}
+		}
+		return headingEnd + 1, nil
+	case positionBodyEnd, positionSubtreeEnd:
+		end := bodyEnd
+		if position == positionSubtreeEnd {
+			end = entry.End
+		}
+		for end > headingEnd && isBlankLine(doc.lines[end-1]) {
+			end--
+		}
+		return end + 1, nil
+	default:
+		return 0, fmt.Errorf(
+			"%w: %q (must be '%s', '%s' or '%s')",
+			ErrInvalidPosition,
+			position,
+			positionBodyStart,
+			positionBodyEnd,
+			positionSubtreeEnd,
+		)
+	}
+}
+
+// insertContent returns the lines of d with block inserted before the
+// 1-based line at, and the line at which the first line of block ends up.
+// Unlike insertBlock, blank lines already around the insertion point are
+// kept; a blank line is added between block and an adjacent heading, and
+// with blankLine also between block and adjacent text.
+func (d *document) insertContent(
+	at int,
+	block []string,
+	blankLine bool,
+) ([]string, int) {
+	var padded []string
+	first := at
+	if prev := at - 1; prev >= 1 && !isBlankLine(d.lines[prev-1]) &&
+		(blankLine || d.isHeadingLine(prev)) {
+		padded = append(padded, "")
+		first++
+	}
+	padded = append(padded, block...)
+	if at <= len(d.lines) && !isBlankLine(d.lines[at-1]) &&
+		(blankLine || d.isHeadingLine(at)) {
+		padded = append(padded, "")
+	}
+
+	return spliceLines(d.lines, at, at-1, padded), first
+}
+
+// isHeadingLine reports whether the 1-based line is part of a heading,
+// including the underline of a setext heading.
+func (d *document) isHeadingLine(line int) bool {
+	for start, heading := range d.headings {
+		end := start
+		if heading.UnderlineIndex >= 0 {
+			end = heading.UnderlineIndex + 1
+		}
+		if line >= start && line <= end {
+			return true
+		}
+	}
+	return false
+}
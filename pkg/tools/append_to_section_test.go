@@ -0,0 +1,279 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// appendSectionDoc is the document edited by the append tests.
+const appendSectionDoc = "# Task 3\n" +
+	"\n" +
+	"Task text.\n" +
+	"\n" +
+	"## Notes\n" +
+	"- first\n" +
+	"\n" +
+	"\n" +
+	"### Details\n" +
+	"\n" +
+	"Details text.\n" +
+	"\n" +
+	"# Task 4\n"
+
+func TestHandleAppendToSection_Positions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		heading      string
+		content      string
+		position     string
+		blankLine    *bool
+		expected     string
+		contentStart int
+	}{
+		{
+			name:      "bullet at body end",
+			heading:   "Notes",
+			content:   "- second\n",
+			position:  positionBodyEnd,
+			blankLine: ptr(false),
+			expected: "# Task 3\n" +
+				"\n" +
+				"Task text.\n" +
+				"\n" +
+				"## Notes\n" +
+				"- first\n" +
+				"- second\n" +
+				"\n" +
+				"\n" +
+				"### Details\n" +
+				"\n" +
+				"Details text.\n" +
+				"\n" +
+				"# Task 4\n",
+			contentStart: 7,
+		},
+		{
+			name:     "paragraph at body end",
+			heading:  "Task 3",
+			content:  "\n\nMore text.\n\n",
+			position: positionBodyEnd,
+			expected: "# Task 3\n" +
+				"\n" +
+				"Task text.\n" +
+				"\n" +
+				"More text.\n" +
+				"\n" +
+				"## Notes\n" +
+				"- first\n" +
+				"\n" +
+				"\n" +
+				"### Details\n" +
+				"\n" +
+				"Details text.\n" +
+				"\n" +
+				"# Task 4\n",
+			contentStart: 5,
+		},
+		{
+			name:      "body start after heading",
+			heading:   "Notes",
+			content:   "- zeroth",
+			position:  positionBodyStart,
+			blankLine: ptr(false),
+			expected: "# Task 3\n" +
+				"\n" +
+				"Task text.\n" +
+				"\n" +
+				"## Notes\n" +
+				"\n" +
+				"- zeroth\n" +
+				"- first\n" +
+				"\n" +
+				"\n" +
+				"### Details\n" +
+				"\n" +
+				"Details text.\n" +
+				"\n" +
+				"# Task 4\n",
+			contentStart: 7,
+		},
+		{
+			name:     "body start before text",
+			heading:  "Task 3",
+			content:  "Intro.",
+			position: positionBodyStart,
+			expected: "# Task 3\n" +
+				"\n" +
+				"Intro.\n" +
+				"\n" +
+				"Task text.\n" +
+				"\n" +
+				"## Notes\n" +
+				"- first\n" +
+				"\n" +
+				"\n" +
+				"### Details\n" +
+				"\n" +
+				"Details text.\n" +
+				"\n" +
+				"# Task 4\n",
+			contentStart: 3,
+		},
+		{
+			name:     "subtree end",
+			heading:  "Notes",
+			content:  "#### Summary\n\nDone.",
+			position: positionSubtreeEnd,
+			expected: "# Task 3\n" +
+				"\n" +
+				"Task text.\n" +
+				"\n" +
+				"## Notes\n" +
+				"- first\n" +
+				"\n" +
+				"\n" +
+				"### Details\n" +
+				"\n" +
+				"Details text.\n" +
+				"\n" +
+				"#### Summary\n" +
+				"\n" +
+				"Done.\n" +
+				"\n" +
+				"# Task 4\n",
+			contentStart: 13,
+		},
+		{
+			name:     "subtree end of last section",
+			heading:  "Task 4",
+			content:  "Last.",
+			position: positionSubtreeEnd,
+			expected: "# Task 3\n" +
+				"\n" +
+				"Task text.\n" +
+				"\n" +
+				"## Notes\n" +
+				"- first\n" +
+				"\n" +
+				"\n" +
+				"### Details\n" +
+				"\n" +
+				"Details text.\n" +
+				"\n" +
+				"# Task 4\n" +
+				"\n" +
+				"Last.\n",
+			contentStart: 15,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, appendSectionDoc)
+			response := runTool[MarkdownAppendToSectionResponse](
+				t,
+				handleAppendToSection,
+				MarkdownAppendToSectionArgs{
+					FilePath:       filePath,
+					SectionHeading: tt.heading,
+					Content:        tt.content,
+					Position:       &tt.position,
+					BlankLine:      tt.blankLine,
+					ExpectedHash:   &hash,
+				},
+			)
+
+			if got := readFixture(t, filePath); got != tt.expected {
+				t.Errorf("Unexpected content:\n%s", got)
+			}
+			if response.ContentStart != tt.contentStart ||
+				response.Position != tt.position ||
+				response.SectionName != tt.heading ||
+				response.FileHash == "" {
+				t.Errorf("Unexpected response %+v", response)
+			}
+		})
+	}
+}
+
+func TestHandleAppendToSection_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		heading  string
+		content  string
+		position *string
+		hash     string
+		expected error
+	}{
+		{
+			name:     "empty content",
+			heading:  "Notes",
+			content:  "\n \n",
+			expected: ErrInvalidContent,
+		},
+		{
+			name:     "heading not deeper",
+			heading:  "Notes",
+			content:  "## Other",
+			expected: ErrInvalidContent,
+		},
+		{
+			name:     "open fence",
+			heading:  "Notes",
+			content:  "```",
+			expected: ErrInvalidContent,
+		},
+		{
+			name:     "open comment",
+			heading:  "Task 3",
+			content:  "<!--\nx",
+			expected: ErrInvalidContent,
+		},
+		{
+			name:     "invalid position",
+			heading:  "Notes",
+			content:  "- x",
+			position: ptr("middle"),
+			expected: ErrInvalidPosition,
+		},
+		{
+			name:     "stale version",
+			heading:  "Notes",
+			content:  "- x",
+			hash:     "0000",
+			expected: ctags.ErrFileChanged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, appendSectionDoc)
+			if tt.hash != "" {
+				hash = tt.hash
+			}
+			_, err := handleAppendToSection(nil, MarkdownAppendToSectionArgs{
+				FilePath:       filePath,
+				SectionHeading: tt.heading,
+				Content:        tt.content,
+				Position:       tt.position,
+				ExpectedHash:   &hash,
+			})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if got := readFixture(t, filePath); got != appendSectionDoc {
+				t.Errorf("Rejected insert changed the file:\n%s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,596 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// Operations of a markdown_apply_edits batch.
+const (
+	opReplace = "replace"
+	opInsert  = "insert"
+	opAppend  = "append"
+	opDelete  = "delete"
+	opMove    = "move"
+	opRename  = "rename"
+	opRelevel = "relevel"
+)
+
+// EditOperation is one operation of a markdown_apply_edits batch. Fields
+// that do not apply to the operation are ignored.
+type EditOperation struct {
+	Op               string  `json:"op"`
+	SectionHeading   string  `json:"section_heading"`
+	Occurrence       *int    `json:"occurrence,omitempty"`
+	TargetHeading    *string `json:"target_heading,omitempty"`    // move
+	TargetOccurrence *int    `json:"target_occurrence,omitempty"` // move
+	Position         *string `json:"position,omitempty"`          // insert, move, append
+	Heading          *string `json:"heading,omitempty"`           // insert, rename
+	Content          *string `json:"content,omitempty"`           // replace, insert, append
+	KeepHeading      *bool   `json:"keep_heading,omitempty"`      // replace
+	KeepSubsections  *bool   `json:"keep_subsections,omitempty"`  // replace
+	KeepChildren     *bool   `json:"keep_children,omitempty"`     // delete
+	Shift            *int    `json:"shift,omitempty"`             // relevel
+	BlankLine        *bool   `json:"blank_line,omitempty"`        // append
+}
+
+// MarkdownApplyEditsArgs defines the input arguments.
+type MarkdownApplyEditsArgs struct {
+	FilePath      string          `json:"file_path"                description:"Path to markdown file"                                                                                                                                                                                                                                                                                                                                                                                                                                                                                                                                                                                                                      required:"true"`
+	Operations    []EditOperation `json:"operations"               description:"Operations applied in order. Each has 'op' and 'section_heading' (with optional 'occurrence'), which always address the sections of the file as it was read. Per op: 'replace' takes content, keep_heading, keep_subsections; 'insert' takes heading, position ('before', 'after', 'first_child', 'last_child') and content; 'append' takes content, position ('body_start', 'body_end', 'subtree_end') and blank_line; 'delete' takes keep_children; 'move' takes target_heading, target_occurrence and position; 'rename' takes heading (the new text); 'relevel' takes shift. The fields mean the same as in the single-operation tools" required:"true"`
+	MatchMode     *string         `json:"match_mode,omitempty"     description:"How heading names are compared, for all operations: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive *bool           `json:"case_sensitive,omitempty" description:"Compare heading names case-sensitively. Default: false"`
+	Strict        *bool           `json:"strict,omitempty"         description:"Fail an operation with a list of candidate sections instead of using the first match when its heading is ambiguous. Default: false"`
+	DryRun        *bool           `json:"dry_run,omitempty"        description:"Return a unified diff of the batch instead of writing it. Default: false"`
+	ExpectedHash  *string         `json:"expected_hash,omitempty"  description:"file_hash returned by a prior markdown_read_section or markdown_section_bounds. The batch fails if the file changed since. expected_hash or expected_mtime is required"`
+	ExpectedMtime *string         `json:"expected_mtime,omitempty" description:"mtime returned by a prior markdown_read_section or markdown_section_bounds (RFC 3339). The batch fails if the file changed since"`
+}
+
+// AppliedEdit is the section an operation of a batch resulted in.
+type AppliedEdit struct {
+	Index       int      `json:"index"` // 1-based position in operations
+	Op          string   `json:"op"`
+	SectionName string   `json:"section_name,omitempty"` // Empty for deletions
+	Slug        string   `json:"slug,omitempty"`
+	HeadingPath []string `json:"heading_path,omitempty"`
+	StartLine   int      `json:"start_line,omitempty"` // Bounds after the batch
+	EndLine     int      `json:"end_line,omitempty"`
+}
+
+// MarkdownApplyEditsResponse defines the response structure.
+type MarkdownApplyEditsResponse struct {
+	Operations []AppliedEdit `json:"operations"`
+	DryRun     bool          `json:"dry_run"`
+	Diff       string        `json:"diff,omitempty"`      // Dry run only
+	FileHash   string        `json:"file_hash,omitempty"` // Version after the edit
+	Mtime      string        `json:"mtime,omitempty"`
+}
+
+// RegisterMarkdownApplyEdits registers the markdown_apply_edits tool.
+func RegisterMarkdownApplyEdits(srv server.Server) {
+	srv.Tool(
+		"markdown_apply_edits",
+		"Apply several section edits (replace, insert, append, delete, move, rename, relevel) as one transaction. All headings are resolved against the file as it was read, so earlier operations do not shift the sections later ones address. The file is written once, atomically; if any operation fails nothing is written and the error lists every failed operation. dry_run returns a unified diff instead. Requires expected_hash or expected_mtime from a prior read.",
+		handleApplyEdits,
+	)
+}
+
+// handleApplyEdits implements the markdown_apply_edits tool logic.
+func handleApplyEdits(
+	_ *server.Context,
+	args MarkdownApplyEditsArgs,
+) (interface{}, error) {
+	if len(args.Operations) == 0 {
+		return nil, fmt.Errorf("%w: operations is empty", ErrInvalidOperation)
+	}
+
+	doc, err := loadDocument(args.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(
+		doc.version,
+		args.ExpectedHash,
+		args.ExpectedMtime,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(doc.entries) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoEntries, args.FilePath)
+	}
+
+	absPath, err := filepath.Abs(args.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve file path: %w", err)
+	}
+
+	batch := newEditBatch(doc, absPath)
+	results := make([]int, len(args.Operations)) // Tracked result sections
+	var failures []string
+	for i, op := range args.Operations {
+		result, err := batch.run(op, args)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf(
+				"operation %d (%s '%s'): %v",
+				i+1,
+				op.Op,
+				op.SectionHeading,
+				err,
+			))
+			continue
+		}
+		results[i] = result
+	}
+	if len(failures) > 0 {
+		return nil, fmt.Errorf(
+			"%w: %d of %d operations failed, nothing was written:\n%s",
+			ErrBatchRejected,
+			len(failures),
+			len(args.Operations),
+			strings.Join(failures, "\n"),
+		)
+	}
+
+	response := MarkdownApplyEditsResponse{
+		Operations: make([]AppliedEdit, 0, len(args.Operations)),
+		DryRun:     args.DryRun != nil && *args.DryRun,
+		Diff:       "",
+		FileHash:   "",
+		Mtime:      "",
+	}
+	for i, op := range args.Operations {
+		applied := AppliedEdit{
+			Index:       i + 1,
+			Op:          op.Op,
+			SectionName: "",
+			Slug:        "",
+			HeadingPath: nil,
+			StartLine:   0,
+			EndLine:     0,
+		}
+		if section, ok := batch.section(results[i]); ok {
+			applied.SectionName = section.Name
+			applied.Slug = section.Slug
+			applied.HeadingPath = section.HeadingPath()
+			applied.StartLine = section.Line
+			applied.EndLine = section.End
+		}
+		response.Operations = append(response.Operations, applied)
+	}
+
+	if response.DryRun {
+		response.Diff = unifiedDiff(
+			args.FilePath,
+			doc.lines,
+			diffLines(doc.lines, batch.doc.lines),
+		)
+		return response, nil
+	}
+
+	updated, err := doc.save("markdown_apply_edits", batch.doc.lines)
+	if err != nil {
+		return nil, err
+	}
+	response.FileHash = updated.version.Hash
+	response.Mtime = formatModTime(updated.version.ModTime)
+
+	return response, nil
+}
+
+// editBatch applies the operations of a batch to an in-memory document.
+// Sections are tracked by the line of their heading, so that sections of
+// the snapshot can be found after earlier operations moved them.
+type editBatch struct {
+	snapshot *document
+	doc      *document // After the operations applied so far
+	absPath  string
+	tracked  []int // Heading line per tracked section, 0 once removed
+}
+
+// lineRange is a 1-based, inclusive range of lines, empty if To < From.
+type lineRange struct {
+	From int
+	To   int
+}
+
+// contains reports whether line is in the range.
+func (r lineRange) contains(line int) bool {
+	return line >= r.From && line <= r.To
+}
+
+// batchChange is the result of one operation: the new lines, the heading
+// line of the resulting section (0 for deletions) and how headings moved.
+// Headings of the old lines in removed are gone and those of the new lines
+// in added are new. The headings of the old lines in moved reappear, in
+// order, from the new line movedTo on. All other headings keep their
+// order.
+type batchChange struct {
+	lines   []string
+	line    int
+	removed lineRange
+	added   lineRange
+	moved   lineRange
+	movedTo int
+}
+
+// newEditBatch returns a batch editing doc. The sections of doc are
+// tracked under their index in doc.entries.
+func newEditBatch(doc *document, absPath string) *editBatch {
+	tracked := make([]int, len(doc.entries))
+	for i, entry := range doc.entries {
+		tracked[i] = entry.Line
+	}
+	return &editBatch{
+		snapshot: doc,
+		doc:      doc,
+		absPath:  absPath,
+		tracked:  tracked,
+	}
+}
+
+// section returns the current section tracked under id.
+func (b *editBatch) section(id int) (*ctags.TagEntry, bool) {
+	if id < 0 || id >= len(b.tracked) || b.tracked[id] == 0 {
+		return nil, false
+	}
+	return b.doc.sectionAt(b.tracked[id])
+}
+
+// resolve finds a section of the snapshot and returns it as it is now.
+//
+// Errors include: ErrSectionNotFound.
+func (b *editBatch) resolve(address sectionAddress) (*ctags.TagEntry, error) {
+	entry, err := resolveSection(b.snapshot.entries, address)
+	if err != nil {
+		return nil, err
+	}
+	id := slices.Index(b.snapshot.entries, entry)
+	current, ok := b.section(id)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: '%s' was removed by an earlier operation",
+			ErrSectionNotFound,
+			entry.Name,
+		)
+	}
+	return current, nil
+}
+
+// run applies op to the document and returns the id under which the
+// resulting section is tracked.
+func (b *editBatch) run(
+	op EditOperation,
+	args MarkdownApplyEditsArgs,
+) (int, error) {
+	entry, err := b.resolve(sectionAddress{
+		Heading:       op.SectionHeading,
+		Occurrence:    op.Occurrence,
+		MatchMode:     args.MatchMode,
+		CaseSensitive: args.CaseSensitive,
+		Strict:        args.Strict,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var target *ctags.TagEntry
+	if op.Op == opMove {
+		if op.TargetHeading == nil {
+			return 0, fmt.Errorf(
+				"%w: move requires target_heading",
+				ErrInvalidOperation,
+			)
+		}
+		target, err = b.resolve(sectionAddress{
+			Heading:       *op.TargetHeading,
+			Occurrence:    op.TargetOccurrence,
+			MatchMode:     args.MatchMode,
+			CaseSensitive: args.CaseSensitive,
+			Strict:        args.Strict,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	change, err := b.apply(op, entry, target)
+	if err != nil {
+		return 0, err
+	}
+	if err := b.advance(change); err != nil {
+		return 0, err
+	}
+
+	b.tracked = append(b.tracked, change.line)
+	return len(b.tracked) - 1, nil
+}
+
+// apply computes the change of one operation on the section entry.
+//
+// Errors include: ErrInvalidOperation, ErrInvalidContent,
+// ErrInvalidPosition, ErrInvalidLevel.
+func (b *editBatch) apply(
+	op EditOperation,
+	entry *ctags.TagEntry,
+	target *ctags.TagEntry,
+) (batchChange, error) {
+	doc := b.doc
+	var content []string
+	if op.Content != nil {
+		content = contentLines(*op.Content)
+	}
+	position := ""
+	if op.Position != nil {
+		position = *op.Position
+	}
+
+	switch op.Op {
+	case opReplace:
+		keepHeading := op.KeepHeading == nil || *op.KeepHeading
+		keepSubsections := op.KeepSubsections == nil || *op.KeepSubsections
+		if err := validateReplacement(entry, content, keepHeading); err != nil {
+			return batchChange{}, err
+		}
+		lines, line, from, to := replaceSection(
+			doc,
+			entry,
+			content,
+			keepHeading,
+			keepSubsections,
+		)
+		change := batchChange{
+			lines:   lines,
+			line:    line,
+			removed: lineRange{From: from, To: to},
+			added: lineRange{
+				From: from,
+				To:   from + len(lines) - len(doc.lines) + to - from,
+			},
+			moved:   lineRange{From: 0, To: -1},
+			movedTo: 0,
+		}
+		if !keepHeading {
+			change.moved = lineRange{From: entry.Line, To: entry.Line}
+			change.movedTo = line
+		}
+		return change, nil
+
+	case opInsert:
+		if op.Heading == nil {
+			return batchChange{}, fmt.Errorf(
+				"%w: insert requires heading",
+				ErrInvalidOperation,
+			)
+		}
+		heading, err := headingText(*op.Heading, "heading")
+		if err != nil {
+			return batchChange{}, err
+		}
+		lines, line, err := insertSection(doc, entry, position, heading, content)
+		if err != nil {
+			return batchChange{}, err
+		}
+		return spliceChange(doc.lines, lines, line), nil
+
+	case opAppend:
+		if len(content) == 0 {
+			return batchChange{}, fmt.Errorf(
+				"%w: content is empty",
+				ErrInvalidContent,
+			)
+		}
+		if position == "" {
+			position = positionBodyEnd
+		}
+		if err := validateSubheadings(
+			content,
+			entry.Name,
+			entry.Level,
+		); err != nil {
+			return batchChange{}, err
+		}
+		at, err := appendPoint(doc, entry, position)
+		if err != nil {
+			return batchChange{}, err
+		}
+		blankLine := op.BlankLine == nil || *op.BlankLine
+		lines, _ := doc.insertContent(at, content, blankLine)
+		return spliceChange(doc.lines, lines, entry.Line), nil
+
+	case opDelete:
+		keepChildren := op.KeepChildren != nil && *op.KeepChildren
+		lines, end, _, err := deleteSection(doc, entry, keepChildren)
+		if err != nil {
+			return batchChange{}, err
+		}
+		if end == entry.End {
+			return spliceChange(doc.lines, lines, 0), nil
+		}
+		// Promoted subsections keep their order
+		return batchChange{
+			lines:   lines,
+			line:    0,
+			removed: lineRange{From: entry.Line, To: end},
+			added:   lineRange{From: 0, To: -1},
+			moved:   lineRange{From: 0, To: -1},
+			movedTo: 0,
+		}, nil
+
+	case opMove:
+		if position == "" {
+			return batchChange{}, fmt.Errorf(
+				"%w: move requires position",
+				ErrInvalidOperation,
+			)
+		}
+		lines, line, err := moveSubtree(doc, entry, target, position)
+		if err != nil {
+			return batchChange{}, err
+		}
+		return batchChange{
+			lines:   lines,
+			line:    line,
+			removed: lineRange{From: 0, To: -1},
+			added:   lineRange{From: 0, To: -1},
+			moved:   lineRange{From: entry.Line, To: entry.End},
+			movedTo: line,
+		}, nil
+
+	case opRename:
+		if op.Heading == nil {
+			return batchChange{}, fmt.Errorf(
+				"%w: rename requires heading",
+				ErrInvalidOperation,
+			)
+		}
+		heading, err := headingText(*op.Heading, "heading")
+		if err != nil {
+			return batchChange{}, err
+		}
+		lines, renamed := doc.renameHeading(entry, heading)
+		lines, _ = rewriteAnchorLinks(
+			b.absPath,
+			lines,
+			b.absPath,
+			b.absPath,
+			changedSlugs(doc.entries, renamed.entries),
+		)
+		return orderedChange(lines, entry.Line), nil
+
+	case opRelevel:
+		if op.Shift == nil || *op.Shift == 0 {
+			return batchChange{}, fmt.Errorf(
+				"%w: relevel requires a non-zero shift",
+				ErrInvalidLevel,
+			)
+		}
+		releveled, err := doc.relevelRange(entry.Line, entry.End, *op.Shift)
+		if err != nil {
+			return batchChange{}, err
+		}
+		lines := spliceLines(doc.lines, entry.Line, entry.End, releveled)
+		return orderedChange(lines, entry.Line), nil
+
+	default:
+		return batchChange{}, fmt.Errorf(
+			"%w: %q (must be '%s', '%s', '%s', '%s', '%s', '%s' or '%s')",
+			ErrInvalidOperation,
+			op.Op,
+			opReplace,
+			opInsert,
+			opAppend,
+			opDelete,
+			opMove,
+			opRename,
+			opRelevel,
+		)
+	}
+}
+
+// spliceChange describes a change that replaced one range of before,
+// found by comparing the lines shared at the start and end.
+func spliceChange(before, after []string, line int) batchChange {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) &&
+		before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	return batchChange{
+		lines:   after,
+		line:    line,
+		removed: lineRange{From: prefix + 1, To: len(before) - suffix},
+		added:   lineRange{From: prefix + 1, To: len(after) - suffix},
+		moved:   lineRange{From: 0, To: -1},
+		movedTo: 0,
+	}
+}
+
+// orderedChange describes a change that rewrote headings in place, keeping
+// their number and order.
+func orderedChange(lines []string, line int) batchChange {
+	return batchChange{
+		lines:   lines,
+		line:    line,
+		removed: lineRange{From: 0, To: -1},
+		added:   lineRange{From: 0, To: -1},
+		moved:   lineRange{From: 0, To: -1},
+		movedTo: 0,
+	}
+}
+
+// advance makes change the current state of the batch and moves the
+// tracked sections to their new heading lines.
+//
+// Errors include: ErrInvalidContent.
+func (b *editBatch) advance(change batchChange) error {
+	updated := newDocument(
+		b.doc.filePath,
+		b.doc.render(change.lines),
+		b.doc.version,
+	)
+
+	before := headingLines(b.doc)
+	after := headingLines(updated)
+
+	var moved, kept []int
+	for _, line := range before {
+		switch {
+		case change.moved.contains(line):
+			moved = append(moved, line)
+		case !change.removed.contains(line):
+			kept = append(kept, line)
+		}
+	}
+
+	var arrived, remaining []int
+	from := slices.Index(after, change.movedTo)
+	for i, line := range after {
+		switch {
+		case from >= 0 && i >= from && i < from+len(moved):
+			arrived = append(arrived, line)
+		case !change.added.contains(line):
+			remaining = append(remaining, line)
+		}
+	}
+	if len(kept) != len(remaining) || len(moved) != len(arrived) {
+		return fmt.Errorf(
+			"%w: the operation changed headings outside its section",
+			ErrInvalidContent,
+		)
+	}
+
+	mapping := make(map[int]int, len(before))
+	for i, line := range kept {
+		mapping[line] = remaining[i]
+	}
+	for i, line := range moved {
+		mapping[line] = arrived[i]
+	}
+	for id, line := range b.tracked {
+		b.tracked[id] = mapping[line] // 0 if removed
+	}
+
+	b.doc = updated
+	return nil
+}
+
+// headingLines returns the first lines of the headings of d in order.
+func headingLines(d *document) []int {
+	lines := make([]int, 0, len(d.entries))
+	for _, entry := range d.entries {
+		lines = append(lines, entry.Line)
+	}
+	return lines
+}
@@ -0,0 +1,243 @@
+package tools
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// applyEditsDoc is the document edited by the batch tests.
+const applyEditsDoc = "# Plan\n" +
+	"\n" +
+	"## Phase 1\n" +
+	"\n" +
+	"Phase text.\n" +
+	"\n" +
+	"### Task 1.1\n" +
+	"\n" +
+	"Task text.\n" +
+	"\n" +
+	"### Task 1.2\n" +
+	"\n" +
+	"## Phase 2\n" +
+	"\n" +
+	"Phase two text.\n"
+
+// restructureOperations renames, moves, appends to, inserts and deletes
+// sections of applyEditsDoc, addressing each by its original heading.
+func restructureOperations() []EditOperation {
+	return []EditOperation{
+		{Op: opRename, SectionHeading: "Phase 1", Heading: ptr("Stage 1")},
+		{
+			Op:             opMove,
+			SectionHeading: "Task 1.2",
+			TargetHeading:  ptr("Phase 2"),
+			Position:       ptr(positionLastChild),
+		},
+		{
+			Op:             opAppend,
+			SectionHeading: "Phase 1",
+			Content:        ptr("More phase text."),
+		},
+		{
+			Op:             opInsert,
+			SectionHeading: "Phase 2",
+			Heading:        ptr("Phase 3"),
+			Position:       ptr(positionAfter),
+			Content:        ptr("Later."),
+		},
+		{Op: opDelete, SectionHeading: "Task 1.1"},
+	}
+}
+
+// applyEdits calls handleApplyEdits with operations on filePath.
+func applyEdits(
+	filePath, hash string,
+	operations ...EditOperation,
+) (interface{}, error) {
+	return handleApplyEdits(nil, MarkdownApplyEditsArgs{
+		FilePath:     filePath,
+		Operations:   operations,
+		ExpectedHash: &hash,
+	})
+}
+
+func TestHandleApplyEdits_SnapshotAddressing(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, applyEditsDoc)
+	response := runTool[MarkdownApplyEditsResponse](
+		t,
+		handleApplyEdits,
+		MarkdownApplyEditsArgs{
+			FilePath:     filePath,
+			Operations:   restructureOperations(),
+			ExpectedHash: &hash,
+		},
+	)
+
+	expected := "# Plan\n" +
+		"\n" +
+		"## Stage 1\n" +
+		"\n" +
+		"Phase text.\n" +
+		"\n" +
+		"More phase text.\n" +
+		"\n" +
+		"## Phase 2\n" +
+		"\n" +
+		"Phase two text.\n" +
+		"\n" +
+		"### Task 1.2\n" +
+		"\n" +
+		"## Phase 3\n" +
+		"\n" +
+		"Later.\n"
+	if got := readFixture(t, filePath); got != expected {
+		t.Errorf("Unexpected content:\n%s", got)
+	}
+
+	tests := []struct {
+		name      string
+		startLine int
+	}{
+		{name: "Stage 1", startLine: 3},
+		{name: "Task 1.2", startLine: 13},
+		{name: "Stage 1", startLine: 3},
+		{name: "Phase 3", startLine: 15},
+		{name: "", startLine: 0},
+	}
+	if len(response.Operations) != len(tests) {
+		t.Fatalf("Unexpected operations %+v", response.Operations)
+	}
+	for i, tt := range tests {
+		applied := response.Operations[i]
+		if applied.Index != i+1 || applied.SectionName != tt.name ||
+			applied.StartLine != tt.startLine {
+			t.Errorf("Operation %d: unexpected result %+v", i+1, applied)
+		}
+	}
+	if response.FileHash == "" || response.DryRun {
+		t.Errorf("Unexpected response %+v", response)
+	}
+}
+
+func TestHandleApplyEdits_DryRun(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, applyEditsDoc)
+	response := runTool[MarkdownApplyEditsResponse](
+		t,
+		handleApplyEdits,
+		MarkdownApplyEditsArgs{
+			FilePath: filePath,
+			Operations: []EditOperation{
+				{Op: opRelevel, SectionHeading: "Task 1.1", Shift: ptr(-1)},
+			},
+			DryRun:       ptr(true),
+			ExpectedHash: &hash,
+		},
+	)
+
+	if !strings.Contains(response.Diff, "-### Task 1.1\n+## Task 1.1\n") {
+		t.Errorf("Unexpected diff:\n%s", response.Diff)
+	}
+	if response.FileHash != "" || response.Operations[0].StartLine != 7 {
+		t.Errorf("Unexpected response %+v", response)
+	}
+	if got := readFixture(t, filePath); got != applyEditsDoc {
+		t.Errorf("Dry run changed the file:\n%s", got)
+	}
+}
+
+func TestHandleApplyEdits_RejectedBatch(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, applyEditsDoc)
+
+	replace := EditOperation{
+		Op:             opReplace,
+		SectionHeading: "Phase 2",
+		Content:        ptr("New text."),
+	}
+	_, err := applyEdits(
+		filePath,
+		hash,
+		replace,
+		EditOperation{Op: opDelete, SectionHeading: "Missing"},
+		EditOperation{Op: "split", SectionHeading: "Phase 1"},
+		EditOperation{Op: opDelete, SectionHeading: "Phase 1"},
+		EditOperation{Op: opDelete, SectionHeading: "Task 1.1"},
+		EditOperation{Op: opRelevel, SectionHeading: "Plan", Shift: ptr(-1)},
+	)
+	if !errors.Is(err, ErrBatchRejected) {
+		t.Fatalf("Expected ErrBatchRejected, got %v", err)
+	}
+	for _, want := range []string{
+		"4 of 6 operations failed",
+		"operation 2 (delete 'Missing'): section not found",
+		"operation 3 (split 'Phase 1'): invalid edit operation",
+		"operation 5 (delete 'Task 1.1'): section not found: " +
+			"'Task 1.1' was removed by an earlier operation",
+		"operation 6 (relevel 'Plan'): invalid heading level",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Error misses %q:\n%v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "operation 1 ") ||
+		strings.Contains(err.Error(), "operation 4 ") {
+		t.Errorf("Valid operations reported as failed:\n%v", err)
+	}
+
+	if got := readFixture(t, filePath); got != applyEditsDoc {
+		t.Errorf("Rejected batch changed the file:\n%s", got)
+	}
+
+	if _, err := applyEdits(filePath, "0000", replace); !errors.Is(
+		err,
+		ctags.ErrFileChanged,
+	) {
+		t.Errorf("Expected ErrFileChanged, got %v", err)
+	}
+
+	if _, err := applyEdits(filePath, hash); !errors.Is(
+		err,
+		ErrInvalidOperation,
+	) {
+		t.Errorf("Expected ErrInvalidOperation, got %v", err)
+	}
+}
+
+func TestHandleApplyEdits_UnclosedFence(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, applyEditsDoc)
+
+	_, err := applyEdits(
+		filePath,
+		hash,
+		EditOperation{
+			Op:             opReplace,
+			SectionHeading: "Task 1.1",
+			Content:        ptr("```\nunterminated"),
+		},
+		EditOperation{
+			Op:             opRename,
+			SectionHeading: "Phase 2",
+			Heading:        ptr("Stage 2"),
+		},
+	)
+	if !errors.Is(err, ErrBatchRejected) {
+		t.Fatalf("Expected ErrBatchRejected, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "operation 1 (replace 'Task 1.1'): "+
+		"invalid section content: content line 1 (\"```\") opens a code block") {
+		t.Errorf("Unexpected error:\n%v", err)
+	}
+	if got := readFixture(t, filePath); got != applyEditsDoc {
+		t.Errorf("Rejected batch changed the file:\n%s", got)
+	}
+}
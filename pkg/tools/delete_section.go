@@ -0,0 +1,156 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// MarkdownDeleteSectionArgs defines the input arguments.
+type MarkdownDeleteSectionArgs struct {
+	FilePath       string  `json:"file_path"                description:"Path to markdown file"                                                                                                                                  required:"true"`
+	SectionHeading string  `json:"section_heading"          description:"Section to delete: heading text (without # symbols), a heading path separated by ' > ' or an anchor such as '#task-21-testing'"                          required:"true"`
+	Occurrence     *int    `json:"occurrence,omitempty"     description:"Which match to use when several sections match (1=first). Default: 1"`
+	MatchMode      *string `json:"match_mode,omitempty"     description:"How heading names are compared: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive  *bool   `json:"case_sensitive,omitempty" description:"Compare heading names case-sensitively. Default: false"`
+	Strict         *bool   `json:"strict,omitempty"         description:"Fail with a list of candidate sections instead of using the first match when the heading is ambiguous. Default: false"`
+	KeepChildren   *bool   `json:"keep_children,omitempty"  description:"Delete only the heading and the text before the first subsection, and promote the subsections into the parent section. Default: false (delete the whole subtree)"`
+	DryRun         *bool   `json:"dry_run,omitempty"        description:"Return a unified diff of the change instead of writing it. Default: false"`
+	ExpectedHash   *string `json:"expected_hash,omitempty"  description:"file_hash returned by a prior markdown_read_section or markdown_section_bounds. The edit fails if the file changed since. expected_hash or expected_mtime is required"`
+	ExpectedMtime  *string `json:"expected_mtime,omitempty" description:"mtime returned by a prior markdown_read_section or markdown_section_bounds (RFC 3339). The edit fails if the file changed since"`
+}
+
+// MarkdownDeleteSectionResponse defines the response structure.
+type MarkdownDeleteSectionResponse struct {
+	SectionName    string   `json:"section_name"`
+	HeadingPath    []string `json:"heading_path"`
+	StartLine      int      `json:"start_line"` // Removed lines, before the edit
+	EndLine        int      `json:"end_line"`
+	RemovedContent string   `json:"removed_content"` // For undo
+	LinesRemoved   int      `json:"lines_removed"`
+	Promoted       int      `json:"promoted"`    // Subsections moved into the parent
+	LevelShift     int      `json:"level_shift"` // Added to every promoted heading
+	DryRun         bool     `json:"dry_run"`
+	Diff           string   `json:"diff,omitempty"`      // Dry run only
+	FileHash       string   `json:"file_hash,omitempty"` // Version after the edit
+	Mtime          string   `json:"mtime,omitempty"`
+}
+
+// RegisterMarkdownDeleteSection registers the markdown_delete_section tool.
+func RegisterMarkdownDeleteSection(srv server.Server) {
+	srv.Tool(
+		"markdown_delete_section",
+		"Delete a section with all its subsections, or with keep_children only its heading and own text, promoting the subsections into the parent. The removed text is returned for undo; dry_run returns a unified diff instead of writing. Requires expected_hash or expected_mtime from a prior read.",
+		handleDeleteSection,
+	)
+}
+
+// handleDeleteSection implements the markdown_delete_section tool logic.
+func handleDeleteSection(
+	_ *server.Context,
+	args MarkdownDeleteSectionArgs,
+) (interface{}, error) {
+	doc, err := loadDocument(args.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(
+		doc.version,
+		args.ExpectedHash,
+		args.ExpectedMtime,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(doc.entries) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoEntries, args.FilePath)
+	}
+
+	entry, err := resolveSection(doc.entries, sectionAddress{
+		Heading:       args.SectionHeading,
+		Occurrence:    args.Occurrence,
+		MatchMode:     args.MatchMode,
+		CaseSensitive: args.CaseSensitive,
+		Strict:        args.Strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keepChildren := args.KeepChildren != nil && *args.KeepChildren
+	lines, end, shift, err := deleteSection(doc, entry, keepChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	response := MarkdownDeleteSectionResponse{
+		SectionName:    entry.Name,
+		HeadingPath:    entry.HeadingPath(),
+		StartLine:      entry.Line,
+		EndLine:        end,
+		RemovedContent: strings.Join(doc.lines[entry.Line-1:end], "\n"),
+		LinesRemoved:   end - entry.Line + 1,
+		Promoted:       0,
+		LevelShift:     shift,
+		DryRun:         args.DryRun != nil && *args.DryRun,
+		Diff:           "",
+		FileHash:       "",
+		Mtime:          "",
+	}
+	if shift != 0 {
+		response.Promoted = len(ctags.Subtree(doc.entries, entry)) - 1
+	}
+
+	if response.DryRun {
+		response.Diff = unifiedDiff(
+			args.FilePath,
+			doc.lines,
+			diffLines(doc.lines, lines),
+		)
+		return response, nil
+	}
+
+	updated, err := doc.save("markdown_delete_section", lines)
+	if err != nil {
+		return nil, err
+	}
+	response.FileHash = updated.version.Hash
+	response.Mtime = formatModTime(updated.version.ModTime)
+
+	return response, nil
+}
+
+// deleteSection returns the lines of doc without the section entry, the
+// last removed line and the level shift of promoted subsections. Without
+// keepChildren, or if the section has no subsections, the whole subtree is
+// removed. Otherwise only the heading and the text before the first
+// subsection are removed, and the subsections are shifted so that the
+// shallowest of them takes the level of the removed section.
+//
+// Errors include: ErrInvalidLevel.
+func deleteSection(
+	doc *document,
+	entry *ctags.TagEntry,
+	keepChildren bool,
+) ([]string, int, int, error) {
+	bodyEnd := doc.bodyEnd(entry)
+	if !keepChildren || bodyEnd >= entry.End {
+		return removeLines(doc.lines, entry.Line, entry.End), entry.End, 0, nil
+	}
+
+	subsections := ctags.Subtree(doc.entries, entry)[1:]
+	shallowest := subsections[0].Level
+	for _, subsection := range subsections {
+		shallowest = min(shallowest, subsection.Level)
+	}
+	shift := entry.Level - shallowest
+
+	children, err := doc.relevelRange(bodyEnd+1, entry.End, shift)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return spliceLines(doc.lines, entry.Line, entry.End, children),
+		bodyEnd, shift, nil
+}
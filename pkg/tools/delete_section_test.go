@@ -0,0 +1,221 @@
+package tools
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// deleteSectionDoc is the document edited by the delete tests.
+const deleteSectionDoc = "# Plan\n" +
+	"\n" +
+	"## Phase 1\n" +
+	"\n" +
+	"Phase text.\n" +
+	"\n" +
+	"### Task 1.1\n" +
+	"\n" +
+	"#### Notes\n" +
+	"\n" +
+	"### Task 1.2\n" +
+	"\n" +
+	"## Phase 2\n"
+
+func TestHandleDeleteSection_Modes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		heading      string
+		keepChildren bool
+		expected     string
+		removed      string
+		promoted     int
+		shift        int
+	}{
+		{
+			name:    "whole subtree",
+			heading: "Phase 1",
+			expected: "# Plan\n" +
+				"\n" +
+				"## Phase 2\n",
+			removed: "## Phase 1\n\nPhase text.\n\n### Task 1.1\n\n" +
+				"#### Notes\n\n### Task 1.2\n",
+			promoted: 0,
+			shift:    0,
+		},
+		{
+			name:         "keep children",
+			heading:      "Phase 1",
+			keepChildren: true,
+			expected: "# Plan\n" +
+				"\n" +
+				"## Task 1.1\n" +
+				"\n" +
+				"### Notes\n" +
+				"\n" +
+				"## Task 1.2\n" +
+				"\n" +
+				"## Phase 2\n",
+			removed:  "## Phase 1\n\nPhase text.\n",
+			promoted: 3,
+			shift:    -1,
+		},
+		{
+			name:         "keep children without subsections",
+			heading:      "Task 1.2",
+			keepChildren: true,
+			expected: "# Plan\n" +
+				"\n" +
+				"## Phase 1\n" +
+				"\n" +
+				"Phase text.\n" +
+				"\n" +
+				"### Task 1.1\n" +
+				"\n" +
+				"#### Notes\n" +
+				"\n" +
+				"## Phase 2\n",
+			removed:  "### Task 1.2\n",
+			promoted: 0,
+			shift:    0,
+		},
+		{
+			name:    "last section",
+			heading: "Phase 2",
+			expected: "# Plan\n" +
+				"\n" +
+				"## Phase 1\n" +
+				"\n" +
+				"Phase text.\n" +
+				"\n" +
+				"### Task 1.1\n" +
+				"\n" +
+				"#### Notes\n" +
+				"\n" +
+				"### Task 1.2\n",
+			removed:  "## Phase 2",
+			promoted: 0,
+			shift:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, deleteSectionDoc)
+			response := runTool[MarkdownDeleteSectionResponse](
+				t,
+				handleDeleteSection,
+				MarkdownDeleteSectionArgs{
+					FilePath:       filePath,
+					SectionHeading: tt.heading,
+					KeepChildren:   &tt.keepChildren,
+					ExpectedHash:   &hash,
+				},
+			)
+
+			if got := readFixture(t, filePath); got != tt.expected {
+				t.Errorf("Unexpected content:\n%s", got)
+			}
+			if response.RemovedContent != tt.removed {
+				t.Errorf("Unexpected removed content %q", response.RemovedContent)
+			}
+			if response.LinesRemoved != strings.Count(
+				response.RemovedContent,
+				"\n",
+			)+1 {
+				t.Errorf("Unexpected lines removed %d", response.LinesRemoved)
+			}
+			if response.Promoted != tt.promoted || response.LevelShift != tt.shift {
+				t.Errorf(
+					"Expected %d promoted by %d, got %d by %d",
+					tt.promoted,
+					tt.shift,
+					response.Promoted,
+					response.LevelShift,
+				)
+			}
+			if response.FileHash == "" {
+				t.Error("Expected the new file hash")
+			}
+		})
+	}
+}
+
+func TestHandleDeleteSection_DryRun(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, deleteSectionDoc)
+	response := runTool[MarkdownDeleteSectionResponse](
+		t,
+		handleDeleteSection,
+		MarkdownDeleteSectionArgs{
+			FilePath:       filePath,
+			SectionHeading: "Task 1.1",
+			DryRun:         ptr(true),
+			ExpectedHash:   &hash,
+		},
+	)
+
+	want := "@@ -4,10 +4,6 @@\n" +
+		" \n" +
+		" Phase text.\n" +
+		" \n" +
+		"-### Task 1.1\n" +
+		"-\n" +
+		"-#### Notes\n" +
+		"-\n" +
+		" ### Task 1.2\n" +
+		" \n" +
+		" ## Phase 2\n"
+	if !strings.Contains(response.Diff, want) {
+		t.Errorf("Diff misses %q:\n%s", want, response.Diff)
+	}
+	if response.StartLine != 7 || response.EndLine != 10 ||
+		response.FileHash != "" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+	if got := readFixture(t, filePath); got != deleteSectionDoc {
+		t.Errorf("Dry run changed the file:\n%s", got)
+	}
+}
+
+func TestHandleDeleteSection_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		heading  string
+		hash     string
+		expected error
+	}{
+		{"missing section", "Missing", "", ErrSectionNotFound},
+		{"stale version", "Phase 1", "0000", ctags.ErrFileChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, deleteSectionDoc)
+			if tt.hash != "" {
+				hash = tt.hash
+			}
+			_, err := handleDeleteSection(nil, MarkdownDeleteSectionArgs{
+				FilePath:       filePath,
+				SectionHeading: tt.heading,
+				ExpectedHash:   &hash,
+			})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if got := readFixture(t, filePath); got != deleteSectionDoc {
+				t.Errorf("Failed delete changed the file:\n%s", got)
+			}
+		})
+	}
+}
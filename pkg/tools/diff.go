@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change in
+// a unified diff.
+const diffContext = 3
+
+// lineEdit replaces the 1-based, inclusive line range From..To of a file
+// with Lines. An empty range (To == From-1) inserts before From.
+type lineEdit struct {
+	From  int
+	To    int
+	Lines []string
+}
+
+// diffLines returns the edits that turn before into after. Lines shared at
+// the start and end are left out; if the rest has the same number of lines
+// on both sides, every run of changed lines is a separate edit, otherwise
+// the rest is a single edit.
+func diffLines(before, after []string) []lineEdit {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) &&
+		before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix &&
+		before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+
+	oldEnd, newEnd := len(before)-suffix, len(after)-suffix
+	if oldEnd == prefix && newEnd == prefix {
+		return nil
+	}
+	if oldEnd-prefix != newEnd-prefix {
+		return []lineEdit{{
+			From:  prefix + 1,
+			To:    oldEnd,
+			Lines: after[prefix:newEnd],
+		}}
+	}
+
+	var edits []lineEdit
+	for i := prefix; i < oldEnd; i++ {
+		if before[i] == after[i] {
+			continue
+		}
+		if n := len(edits); n > 0 && edits[n-1].To == i {
+			edits[n-1].To = i + 1
+			edits[n-1].Lines = append(edits[n-1].Lines, after[i])
+			continue
+		}
+		edits = append(edits, lineEdit{
+			From:  i + 1,
+			To:    i + 1,
+			Lines: []string{after[i]},
+		})
+	}
+	return edits
+}
+
+// unifiedDiff formats edits of lines (sorted, not overlapping) as a unified
+// diff of the file name, with diffContext lines of context. Edits closer
+// than twice the context share a hunk.
+func unifiedDiff(name string, lines []string, edits []lineEdit) string {
+	if len(edits) == 0 {
+		return ""
+	}
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- a/%s\n+++ b/%s\n", name, name)
+
+	shift := 0 // Lines added minus lines removed before the current hunk
+	for start := 0; start < len(edits); {
+		end := start + 1
+		for end < len(edits) &&
+			edits[end].From-edits[end-1].To-1 <= 2*diffContext {
+			end++
+		}
+		hunk := edits[start:end]
+
+		first := max(hunk[0].From-diffContext, 1)
+		last := min(hunk[len(hunk)-1].To+diffContext, len(lines))
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		line := first
+		for _, edit := range hunk {
+			for ; line < edit.From; line++ {
+				fmt.Fprintf(&body, " %s\n", lines[line-1])
+				oldCount++
+				newCount++
+			}
+			for ; line <= edit.To; line++ {
+				fmt.Fprintf(&body, "-%s\n", lines[line-1])
+				oldCount++
+			}
+			for _, added := range edit.Lines {
+				fmt.Fprintf(&body, "+%s\n", added)
+				newCount++
+			}
+		}
+		for ; line <= last; line++ {
+			fmt.Fprintf(&body, " %s\n", lines[line-1])
+			oldCount++
+			newCount++
+		}
+
+		fmt.Fprintf(
+			&diff,
+			"@@ -%s +%s @@\n",
+			hunkRange(first, oldCount),
+			hunkRange(first+shift, newCount),
+		)
+		diff.WriteString(body.String())
+
+		for _, edit := range hunk {
+			shift += len(edit.Lines) - (edit.To - edit.From + 1)
+		}
+		start = end
+	}
+
+	return diff.String()
+}
+
+// hunkRange formats the line range of one side of a hunk. An empty range
+// names the line before it, as diff does.
+func hunkRange(first, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", first-1)
+	}
+	return fmt.Sprintf("%d,%d", first, count)
+}
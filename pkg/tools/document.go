@@ -0,0 +1,349 @@
+package tools
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+const (
+	// maxHeadingLevel is the deepest markdown heading level.
+	maxHeadingLevel = 6
+
+	// setextMaxLevel is the deepest level a setext heading can express.
+	setextMaxLevel = 2
+)
+
+// document is a markdown file loaded for editing: its lines, the headings
+// parsed from exactly these lines and the version they were read at.
+// Headings are parsed with the native parser rather than taken from the
+// cache, so that edits never work on headings of another file version.
+type document struct {
+	filePath     string
+	lines        []string
+	entries      []*ctags.TagEntry
+	headings     map[int]ctags.Heading // By 1-based heading line
+	version      ctags.FileVersion
+	newline      string // Line ending used by the file
+	finalNewline bool   // The content ends with a line ending
+}
+
+// loadDocument reads and parses a markdown file for editing.
+//
+// Errors include: ctags.ErrFileNotFound.
+func loadDocument(filePath string) (*document, error) {
+	content, version, err := ctags.ReadFileVersion(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return newDocument(filePath, content, version), nil
+}
+
+// newDocument parses content read at version.
+func newDocument(
+	filePath string,
+	content []byte,
+	version ctags.FileVersion,
+) *document {
+	newline := "\n"
+	if i := bytes.IndexByte(content, '\n'); i > 0 && content[i-1] == '\r' {
+		newline = "\r\n"
+	}
+
+	lines := ctags.SplitContentLines(content)
+	headings := make(map[int]ctags.Heading)
+	for _, heading := range ctags.ScanHeadings(lines) {
+		headings[heading.Index+1] = heading
+	}
+
+	return &document{
+		filePath: filePath,
+		lines:    lines,
+		entries:  ctags.ParseMarkdown(content, filePath),
+		headings: headings,
+		version:  version,
+		newline:  newline,
+		// New content of an empty file ends with a line ending
+		finalNewline: len(content) == 0 ||
+			bytes.HasSuffix(content, []byte("\n")),
+	}
+}
+
+// headingEnd returns the last line of a section's heading: the underline
+// of a setext heading, else the heading line itself.
+func (d *document) headingEnd(entry *ctags.TagEntry) int {
+	if heading, ok := d.headings[entry.Line]; ok && heading.UnderlineIndex >= 0 {
+		return heading.UnderlineIndex + 1
+	}
+	return entry.Line
+}
+
+// bodyEnd returns the last line of a section's own body, before its first
+// subsection.
+func (d *document) bodyEnd(entry *ctags.TagEntry) int {
+	return calculateEndLineMaxSubsectionLevelsZero(
+		d.entries,
+		entry.Line,
+		entry.End,
+		entry.Level,
+	)
+}
+
+// render joins lines with the file's line ending.
+func (d *document) render(lines []string) []byte {
+	content := strings.Join(lines, d.newline)
+	if d.finalNewline && len(lines) > 0 {
+		content += d.newline
+	}
+	return []byte(content)
+}
+
+// save replaces the file with lines, unless it changed since it was loaded,
+// records the edit made by tool in the edit journal and returns the edited
+// document.
+//
+// Errors include: ctags.ErrFileChanged.
+func (d *document) save(tool string, lines []string) (*document, error) {
+	updated, err := d.write(lines)
+	if err != nil {
+		return nil, err
+	}
+	getJournal().record(d, updated, tool)
+	return updated, nil
+}
+
+// write replaces the file with lines, unless it changed since it was
+// loaded, and returns the edited document.
+//
+// Errors include: ctags.ErrFileChanged.
+func (d *document) write(lines []string) (*document, error) {
+	content := d.render(lines)
+	version, err := ctags.GetGlobalCache().ReplaceFile(
+		d.filePath,
+		content,
+		d.version.Hash,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save %s: %w", d.filePath, err)
+	}
+	return newDocument(d.filePath, content, version), nil
+}
+
+// sectionAt returns the section whose heading is on the given line.
+func (d *document) sectionAt(line int) (*ctags.TagEntry, bool) {
+	for _, entry := range d.entries {
+		if entry.Line == line {
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// checkVersion verifies that a file is still at the version a caller read,
+// given as a content hash or a modification time. At least one is required.
+//
+// Errors include: ErrMissingVersion, ErrInvalidFormat, ctags.ErrFileChanged.
+func checkVersion(
+	version ctags.FileVersion,
+	expectedHash *string,
+	expectedMtime *string,
+) error {
+	hasHash := expectedHash != nil && *expectedHash != ""
+	hasMtime := expectedMtime != nil && *expectedMtime != ""
+	if !hasHash && !hasMtime {
+		return fmt.Errorf(
+			"%w: pass expected_hash or expected_mtime from "+
+				"markdown_read_section or markdown_section_bounds",
+			ErrMissingVersion,
+		)
+	}
+
+	if hasHash && *expectedHash != version.Hash {
+		return fmt.Errorf(
+			"%w: expected hash %s, file has %s; read the section again",
+			ctags.ErrFileChanged,
+			*expectedHash,
+			version.Hash,
+		)
+	}
+
+	if hasMtime {
+		mtime, err := time.Parse(time.RFC3339Nano, *expectedMtime)
+		if err != nil {
+			return fmt.Errorf(
+				"%w: expected_mtime %q (must be RFC 3339): %w",
+				ErrInvalidFormat,
+				*expectedMtime,
+				err,
+			)
+		}
+		if !mtime.Equal(version.ModTime) {
+			return fmt.Errorf(
+				"%w: expected mtime %s, file has %s; read the section again",
+				ctags.ErrFileChanged,
+				*expectedMtime,
+				formatModTime(version.ModTime),
+			)
+		}
+	}
+
+	return nil
+}
+
+// formatModTime formats a modification time for tool responses and
+// expected_mtime arguments.
+func formatModTime(modTime time.Time) string {
+	return modTime.UTC().Format(time.RFC3339Nano)
+}
+
+// contentLines splits tool-supplied content into lines without surrounding
+// blank lines.
+func contentLines(content string) []string {
+	return trimBlankLines(ctags.SplitContentLines([]byte(content)))
+}
+
+// headingText returns tool-supplied heading text without surrounding
+// whitespace. The argument field must be a single non-empty line.
+//
+// Errors include: ErrInvalidContent.
+func headingText(text, field string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.ContainsAny(text, "\r\n") {
+		return "", fmt.Errorf(
+			"%w: %s must be a single non-empty line",
+			ErrInvalidContent,
+			field,
+		)
+	}
+	return text, nil
+}
+
+// trimBlankLines removes leading and trailing blank lines.
+func trimBlankLines(lines []string) []string {
+	start, end := 0, len(lines)
+	for start < end && isBlankLine(lines[start]) {
+		start++
+	}
+	for end > start && isBlankLine(lines[end-1]) {
+		end--
+	}
+	return lines[start:end]
+}
+
+// isBlankLine reports whether a line contains only whitespace.
+func isBlankLine(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// spliceLines returns lines with the 1-based, inclusive range from..to
+// replaced by replacement. An empty range (to == from-1) inserts before
+// from.
+func spliceLines(lines []string, from, to int, replacement []string) []string {
+	result := make([]string, 0, len(lines)-(to-from+1)+len(replacement))
+	result = append(result, lines[:from-1]...)
+	result = append(result, replacement...)
+	return append(result, lines[to:]...)
+}
+
+// insertBlock returns lines with block inserted before the 1-based line at
+// (len(lines)+1 appends). Blank lines at the edges of block are replaced by
+// exactly one blank line between it and adjacent non-blank lines. It also
+// returns the line at which the first line of block ends up.
+func insertBlock(lines []string, at int, block []string) ([]string, int) {
+	block = trimBlankLines(block)
+
+	var padded []string
+	first := at
+	if at > 1 && !isBlankLine(lines[at-2]) {
+		padded = append(padded, "")
+		first++
+	}
+	padded = append(padded, block...)
+	if at <= len(lines) && !isBlankLine(lines[at-1]) {
+		padded = append(padded, "")
+	}
+
+	return spliceLines(lines, at, at-1, padded), first
+}
+
+// relevelRange returns the lines from..to (1-based, inclusive) with the
+// level of every heading among them shifted by delta. ATX headings keep
+// their text and closing sequence; setext headings keep their form at
+// levels 1 and 2 and become ATX headings below that. All other lines are
+// returned unchanged.
+//
+// Errors include: ErrInvalidLevel.
+func (d *document) relevelRange(from, to, delta int) ([]string, error) {
+	lines := make([]string, 0, to-from+1)
+	for line := from; line <= to; line++ {
+		heading, ok := d.headings[line]
+		if !ok {
+			lines = append(lines, d.lines[line-1])
+			continue
+		}
+
+		level := heading.Level + delta
+		if level < 1 || level > maxHeadingLevel {
+			return nil, fmt.Errorf(
+				"%w: heading '%s' (H%d) would become H%d (must be H1-H%d)",
+				ErrInvalidLevel,
+				heading.Text,
+				heading.Level,
+				level,
+				maxHeadingLevel,
+			)
+		}
+
+		lines = append(lines, d.relevelHeading(heading, level)...)
+		if heading.UnderlineIndex >= 0 {
+			line = heading.UnderlineIndex + 1
+		}
+	}
+	return lines, nil
+}
+
+// relevelHeading returns the lines of a heading at a new level.
+func (d *document) relevelHeading(heading ctags.Heading, level int) []string {
+	if heading.UnderlineIndex < 0 {
+		raw := d.lines[heading.Index]
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		marker := len(raw[indent:]) - len(strings.TrimLeft(raw[indent:], "#"))
+		return []string{
+			raw[:indent] + strings.Repeat("#", level) + raw[indent+marker:],
+		}
+	}
+
+	text := d.lines[heading.Index:heading.UnderlineIndex]
+	if level > setextMaxLevel {
+		return []string{strings.Repeat("#", level) + " " + heading.Text}
+	}
+
+	underline := d.lines[heading.UnderlineIndex]
+	indent := len(underline) - len(strings.TrimLeft(underline, " "))
+	char := "="
+	if level == setextMaxLevel {
+		char = "-"
+	}
+	width := len(strings.TrimSpace(underline))
+	return append(
+		append([]string(nil), text...),
+		underline[:indent]+strings.Repeat(char, width),
+	)
+}
+
+// removeLines returns lines without the 1-based, inclusive range from..to.
+// When the range runs to the end of the file, blank lines left at the end
+// are removed as well.
+func removeLines(lines []string, from, to int) []string {
+	result := spliceLines(lines, from, to, nil)
+	if to < len(lines) {
+		return result
+	}
+	for len(result) > 0 && isBlankLine(result[len(result)-1]) {
+		result = result[:len(result)-1]
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// writeFixture writes content to a new file and returns its path and
+// content hash, as an expected_hash for the edit tools.
+func writeFixture(t *testing.T, content string) (string, string) {
+	t.Helper()
+
+	root := createWorkspace(t, map[string]string{"doc.md": content})
+	filePath := filepath.Join(root, "doc.md")
+	_, version, err := ctags.ReadFileVersion(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read version: %v", err)
+	}
+	return filePath, version.Hash
+}
+
+// readFixture returns the content of an edited file.
+func readFixture(t *testing.T, filePath string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	return string(content)
+}
+
+// runTool calls a tool handler with args and returns its response, which
+// must be of type R.
+func runTool[R, A any](
+	t *testing.T,
+	handler func(*server.Context, A) (interface{}, error),
+	args A,
+) R {
+	t.Helper()
+
+	result, err := handler(nil, args)
+	if err != nil {
+		t.Fatalf("Tool failed: %v", err)
+	}
+	response, ok := result.(R)
+	if !ok {
+		t.Fatalf("Unexpected response type %T", result)
+	}
+	return response
+}
+
+// ptr returns a pointer to value, for optional arguments.
+func ptr[T any](value T) *T {
+	return &value
+}
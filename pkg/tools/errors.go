@@ -4,8 +4,17 @@ import "errors"
 
 // Static errors for tool operations.
 var (
-	ErrNoEntries       = errors.New("no entries found")
-	ErrSectionNotFound = errors.New("section not found")
-	ErrInvalidLevel    = errors.New("invalid heading level")
-	ErrInvalidFormat   = errors.New("invalid format")
+	ErrNoEntries        = errors.New("no entries found")
+	ErrSectionNotFound  = errors.New("section not found")
+	ErrInvalidLevel     = errors.New("invalid heading level")
+	ErrInvalidFormat    = errors.New("invalid format")
+	ErrInvalidLine      = errors.New("invalid line number")
+	ErrInvalidLimit     = errors.New("invalid limit")
+	ErrInvalidTarget    = errors.New("invalid search target")
+	ErrMissingVersion   = errors.New("missing expected file version")
+	ErrInvalidContent   = errors.New("invalid section content")
+	ErrInvalidPosition  = errors.New("invalid section position")
+	ErrInvalidOperation = errors.New("invalid edit operation")
+	ErrBatchRejected    = errors.New("edit batch rejected")
+	ErrNoJournalEntry   = errors.New("no recorded edit")
 )
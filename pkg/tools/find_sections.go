@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// defaultFindLimit is the default maximum number of returned sections.
+const defaultFindLimit = 100
+
+// MarkdownFindSectionsArgs defines the input arguments.
+type MarkdownFindSectionsArgs struct {
+	Directory      string   `json:"directory"                description:"Path to the documentation directory to search"                                                                                                                                              required:"true"`
+	SectionHeading string   `json:"section_heading"          description:"Heading to find in every file: heading text, a path separated by ' > ' or a '#anchor'. Interpreted per match_mode, e.g. 'Rollback Plan' or 'Phase * > Rollback*' with 'glob'"               required:"true"`
+	Include        []string `json:"include,omitempty"        description:"Globs selecting files, relative to directory. '*' stays within a path segment, '**' spans directories; a glob without '/' matches file names at any depth. Default: ['*.md', '*.markdown']"`
+	Exclude        []string `json:"exclude,omitempty"        description:"Globs of files or directories to skip, e.g. 'archive/**'. Hidden directories are always skipped"`
+	MatchMode      *string  `json:"match_mode,omitempty"     description:"How section_heading is compared with heading names: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive  *bool    `json:"case_sensitive,omitempty" description:"Compare heading names case-sensitively. Default: false"`
+	MinLevel       *int     `json:"min_level,omitempty"      description:"Only match headings at this level or deeper (1-6). Default: 1"`
+	MaxDepth       *int     `json:"max_depth,omitempty"      description:"Only match headings up to this level (1-6, 0=all). Default: 0"`
+	Limit          *int     `json:"limit,omitempty"          description:"Maximum number of sections returned across all files. Default: 100"`
+}
+
+// FoundSection is a section matching a markdown_find_sections query.
+type FoundSection struct {
+	Name        string   `json:"name"`
+	Slug        string   `json:"slug"`
+	Level       string   `json:"level"`
+	HeadingPath []string `json:"heading_path"`
+	StartLine   int      `json:"start_line"`
+	EndLine     int      `json:"end_line"`
+}
+
+// FileSections groups the matching sections of one file.
+type FileSections struct {
+	Path     string         `json:"path"` // Relative to the directory
+	Sections []FoundSection `json:"sections"`
+}
+
+// MarkdownFindSectionsResponse defines the response structure.
+type MarkdownFindSectionsResponse struct {
+	Directory    string         `json:"directory"`     // Absolute directory path
+	FilesScanned int            `json:"files_scanned"` // Files searched
+	Matches      int            `json:"matches"`       // Sections found before the limit
+	Truncated    bool           `json:"truncated"`     // Results were cut at limit
+	Files        []FileSections `json:"files"`         // Files with matches, by path
+	Errors       []FileError    `json:"errors,omitempty"`
+}
+
+// RegisterMarkdownFindSections registers the markdown_find_sections tool.
+func RegisterMarkdownFindSections(srv server.Server) {
+	srv.Tool(
+		"markdown_find_sections",
+		"Find sections by heading across all markdown files in a directory. Returns matches grouped by file with heading paths and line ranges. Use to answer 'which document has the Rollback Plan section?' before reading it with markdown_read_section.",
+		handleFindSections,
+	)
+}
+
+// handleFindSections implements the markdown_find_sections tool logic.
+func handleFindSections(
+	_ *server.Context,
+	args MarkdownFindSectionsArgs,
+) (interface{}, error) {
+	// Note: gomcp's server.Context does not provide request-level context.
+	// Application-level cancellation is handled via signal handling in main.go.
+	reqCtx := context.Background()
+
+	query, err := buildSectionQuery(sectionAddress{
+		Heading:       args.SectionHeading,
+		Occurrence:    nil,
+		MatchMode:     args.MatchMode,
+		CaseSensitive: args.CaseSensitive,
+		Strict:        nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := query.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid section_heading: %w", err)
+	}
+
+	minLevel, maxDepth, limit, err := findSectionsFilters(args)
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := resolveWorkspace(
+		args.Directory,
+		args.Include,
+		args.Exclude,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	results := searchFiles(
+		reqCtx,
+		workspace.FilePaths,
+		func(entries []*ctags.TagEntry) []*ctags.TagEntry {
+			var matches []*ctags.TagEntry
+			for _, entry := range ctags.FindSections(entries, query) {
+				if entry.Level >= minLevel &&
+					(maxDepth == 0 || entry.Level <= maxDepth) {
+					matches = append(matches, entry)
+				}
+			}
+			return matches
+		},
+	)
+	if err := reqCtx.Err(); err != nil {
+		return nil, fmt.Errorf("search canceled: %w", err)
+	}
+
+	response := MarkdownFindSectionsResponse{
+		Directory:    workspace.Directory,
+		FilesScanned: len(workspace.FilePaths),
+		Matches:      0,
+		Truncated:    false,
+		Files:        []FileSections{},
+		Errors:       nil,
+	}
+
+	returned := 0
+	for i, result := range results {
+		if result.err != nil {
+			response.Errors = append(response.Errors, FileError{
+				Path:  workspace.RelPaths[i],
+				Error: result.err.Error(),
+			})
+			continue
+		}
+
+		// Count every match, but return at most limit sections
+		response.Matches += len(result.matches)
+		matches := result.matches
+		if remaining := limit - returned; len(matches) > remaining {
+			matches = matches[:remaining]
+			response.Truncated = true
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		returned += len(matches)
+
+		response.Files = append(response.Files, FileSections{
+			Path:     workspace.RelPaths[i],
+			Sections: newFoundSections(matches),
+		})
+	}
+
+	return response, nil
+}
+
+// findSectionsFilters validates the level, depth and limit arguments and
+// applies their defaults.
+func findSectionsFilters(
+	args MarkdownFindSectionsArgs,
+) (minLevel, maxDepth, limit int, err error) {
+	minLevel, maxDepth, limit = 1, 0, defaultFindLimit
+	if args.MinLevel != nil {
+		minLevel = *args.MinLevel
+	}
+	if args.MaxDepth != nil {
+		maxDepth = *args.MaxDepth
+	}
+	if args.Limit != nil {
+		limit = *args.Limit
+	}
+
+	switch {
+	case minLevel < 1 || minLevel > 6:
+		return 0, 0, 0, fmt.Errorf(
+			"%w: min_level %d (must be 1-6)",
+			ErrInvalidLevel,
+			minLevel,
+		)
+	case maxDepth < 0 || maxDepth > 6:
+		return 0, 0, 0, fmt.Errorf(
+			"%w: max_depth %d (must be 0-6, where 0 means all levels)",
+			ErrInvalidLevel,
+			maxDepth,
+		)
+	case maxDepth > 0 && maxDepth < minLevel:
+		return 0, 0, 0, fmt.Errorf(
+			"%w: max_depth %d is above min_level %d",
+			ErrInvalidLevel,
+			maxDepth,
+			minLevel,
+		)
+	case limit < 1:
+		return 0, 0, 0, fmt.Errorf(
+			"%w: %d (must be 1 or greater)",
+			ErrInvalidLimit,
+			limit,
+		)
+	}
+
+	return minLevel, maxDepth, limit, nil
+}
+
+// fileSearchResult holds the matches of one file searched by searchFiles.
+type fileSearchResult struct {
+	matches []*ctags.TagEntry
+	err     error
+}
+
+// searchFiles gets the headings of every file from the global cache using a
+// pool of workers and applies match to them. Results are returned in the
+// order of filePaths.
+func searchFiles(
+	ctx context.Context,
+	filePaths []string,
+	match func(entries []*ctags.TagEntry) []*ctags.TagEntry,
+) []fileSearchResult {
+	results := make([]fileSearchResult, len(filePaths))
+	cache := ctags.GetGlobalCache()
+
+	forEachFile(ctx, len(filePaths), func(i int) {
+		entries, err := cache.GetTags(ctx, filePaths[i])
+		if err != nil {
+			results[i].err = fmt.Errorf("failed to get tags: %w", err)
+			return
+		}
+		results[i].matches = match(entries)
+	})
+
+	return results
+}
+
+// newFoundSections converts entries to response sections.
+func newFoundSections(entries []*ctags.TagEntry) []FoundSection {
+	sections := make([]FoundSection, 0, len(entries))
+	for _, entry := range entries {
+		sections = append(sections, FoundSection{
+			Name:        entry.Name,
+			Slug:        entry.Slug,
+			Level:       fmt.Sprintf("H%d", entry.Level),
+			HeadingPath: entry.HeadingPath(),
+			StartLine:   entry.Line,
+			EndLine:     entry.End,
+		})
+	}
+	return sections
+}
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// findSectionsArgs returns arguments searching root for heading.
+func findSectionsArgs(root, heading string) MarkdownFindSectionsArgs {
+	return MarkdownFindSectionsArgs{
+		Directory:      root,
+		SectionHeading: heading,
+		Include:        nil,
+		Exclude:        nil,
+		MatchMode:      nil,
+		CaseSensitive:  nil,
+		MinLevel:       nil,
+		MaxDepth:       nil,
+		Limit:          nil,
+	}
+}
+
+// runFindSections calls handleFindSections and checks the response type.
+func runFindSections(
+	t *testing.T,
+	args MarkdownFindSectionsArgs,
+) MarkdownFindSectionsResponse {
+	t.Helper()
+
+	result, err := handleFindSections(nil, args)
+	if err != nil {
+		t.Fatalf("handleFindSections failed: %v", err)
+	}
+	response, ok := result.(MarkdownFindSectionsResponse)
+	if !ok {
+		t.Fatalf("Unexpected response type %T", result)
+	}
+	return response
+}
+
+func TestHandleFindSections_GroupedByFile(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{
+		"deploy.md":         "# Deploy\n## Steps\n## Rollback Plan\n",
+		"services/api.md":   "# API\n## Release\n### Rollback Plan\n",
+		"services/db.md":    "# DB\n## Migrations\n",
+		"archive/old.md":    "# Old\n## Rollback Plan\n",
+		"notes/rollback.md": "# Rollback plan\n",
+	})
+
+	args := findSectionsArgs(root, "Rollback Plan")
+	args.Exclude = []string{"archive"}
+	response := runFindSections(t, args)
+
+	if response.FilesScanned != 4 {
+		t.Errorf("Expected 4 files scanned, got %d", response.FilesScanned)
+	}
+	if response.Matches != 3 || response.Truncated {
+		t.Errorf("Expected 3 untruncated matches, got %d (truncated %v)",
+			response.Matches, response.Truncated)
+	}
+
+	var paths []string
+	for _, file := range response.Files {
+		paths = append(paths, file.Path)
+	}
+	expected := []string{"deploy.md", "notes/rollback.md", "services/api.md"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("Expected files %v, got %v", expected, paths)
+	}
+
+	api := response.Files[2].Sections[0]
+	if api.Level != "H3" || api.StartLine != 3 || api.Slug != "rollback-plan" {
+		t.Errorf("Unexpected section %+v", api)
+	}
+	if !reflect.DeepEqual(
+		api.HeadingPath,
+		[]string{"API", "Release", "Rollback Plan"},
+	) {
+		t.Errorf("Unexpected heading path %v", api.HeadingPath)
+	}
+}
+
+func TestHandleFindSections_FiltersAndLimit(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{
+		"a.md": "# Plan\n## Plan A\n### Plan A.1\n",
+		"b.md": "# Plan B\n## Plan C\n",
+	})
+
+	// Level filters
+	minLevel, maxDepth := 2, 2
+	args := findSectionsArgs(root, "Plan")
+	args.MinLevel = &minLevel
+	args.MaxDepth = &maxDepth
+	response := runFindSections(t, args)
+	if response.Matches != 2 {
+		t.Errorf("Expected 2 H2 matches, got %d", response.Matches)
+	}
+
+	// Heading paths and match modes
+	mode := "glob"
+	args = findSectionsArgs(root, "Plan > Plan ?")
+	args.MatchMode = &mode
+	response = runFindSections(t, args)
+	if response.Matches != 1 || response.Files[0].Sections[0].Name != "Plan A" {
+		t.Errorf("Expected only 'Plan A', got %+v", response.Files)
+	}
+
+	// The limit applies across files in path order
+	limit := 4
+	args = findSectionsArgs(root, "Plan")
+	args.Limit = &limit
+	response = runFindSections(t, args)
+	if response.Matches != 5 || !response.Truncated {
+		t.Errorf("Expected 5 matches, truncated, got %d (truncated %v)",
+			response.Matches, response.Truncated)
+	}
+	if len(response.Files) != 2 || len(response.Files[1].Sections) != 1 {
+		t.Errorf("Expected 3 + 1 sections, got %+v", response.Files)
+	}
+}
+
+func TestHandleFindSections_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{"a.md": "# A\n"})
+
+	mode := "regex"
+	args := findSectionsArgs(root, "(")
+	args.MatchMode = &mode
+	if _, err := handleFindSections(nil, args); !errors.Is(
+		err,
+		ctags.ErrInvalidPattern,
+	) {
+		t.Errorf("Expected ErrInvalidPattern, got %v", err)
+	}
+
+	level := 7
+	args = findSectionsArgs(root, "A")
+	args.MinLevel = &level
+	if _, err := handleFindSections(nil, args); !errors.Is(
+		err,
+		ErrInvalidLevel,
+	) {
+		t.Errorf("Expected ErrInvalidLevel, got %v", err)
+	}
+
+	limit := 0
+	args = findSectionsArgs(root, "A")
+	args.Limit = &limit
+	if _, err := handleFindSections(nil, args); !errors.Is(
+		err,
+		ErrInvalidLimit,
+	) {
+		t.Errorf("Expected ErrInvalidLimit, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// MarkdownHistoryArgs defines the input arguments.
+type MarkdownHistoryArgs struct {
+	FilePath string `json:"file_path" description:"Path to markdown file" required:"true"`
+}
+
+// JournalEdit is an edit of the file recorded in the edit journal.
+type JournalEdit struct {
+	ID           int    `json:"id"`
+	Tool         string `json:"tool"`
+	Time         string `json:"time"`
+	BeforeHash   string `json:"before_hash"`
+	AfterHash    string `json:"after_hash"`
+	LinesRemoved int    `json:"lines_removed"`
+	LinesAdded   int    `json:"lines_added"`
+	Undone       bool   `json:"undone"`
+}
+
+// MarkdownHistoryResponse defines the response structure.
+type MarkdownHistoryResponse struct {
+	Edits    []JournalEdit `json:"edits"` // Newest first
+	CanUndo  bool          `json:"can_undo"`
+	CanRedo  bool          `json:"can_redo"`
+	FileHash string        `json:"file_hash"` // Current version
+	Mtime    string        `json:"mtime"`
+}
+
+// RegisterMarkdownHistory registers the markdown_history tool.
+func RegisterMarkdownHistory(srv server.Server) {
+	srv.Tool(
+		"markdown_history",
+		"List the recent edits this server made to a file, newest first, with the tool, time and content hashes before and after each edit. can_undo and can_redo tell whether markdown_undo would succeed: they are false once the file was changed outside the server.",
+		handleHistory,
+	)
+}
+
+// handleHistory implements the markdown_history tool logic.
+func handleHistory(
+	_ *server.Context,
+	args MarkdownHistoryArgs,
+) (interface{}, error) {
+	_, version, err := ctags.ReadFileVersion(args.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := getJournal().entries(args.FilePath)
+	response := MarkdownHistoryResponse{
+		Edits:    make([]JournalEdit, 0, len(entries)),
+		CanUndo:  false,
+		CanRedo:  false,
+		FileHash: version.Hash,
+		Mtime:    formatModTime(version.ModTime),
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		edit := JournalEdit{
+			ID:           entry.id,
+			Tool:         entry.tool,
+			Time:         formatModTime(entry.time),
+			BeforeHash:   entry.beforeHash,
+			AfterHash:    entry.afterHash,
+			LinesRemoved: 0,
+			LinesAdded:   0,
+			Undone:       entry.undone,
+		}
+		for _, change := range entry.forward {
+			edit.LinesRemoved += change.To - change.From + 1
+			edit.LinesAdded += len(change.Lines)
+		}
+		response.Edits = append(response.Edits, edit)
+	}
+
+	if entry, ok := undoable(entries); ok {
+		response.CanUndo = entry.afterHash == version.Hash
+	}
+	if entry, ok := redoable(entries); ok {
+		response.CanRedo = entry.beforeHash == version.Hash
+	}
+
+	return response, nil
+}
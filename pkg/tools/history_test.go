@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"os"
+	"testing"
+)
+
+func TestHandleHistory(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, undoDoc)
+	history := func() MarkdownHistoryResponse {
+		t.Helper()
+		return runTool[MarkdownHistoryResponse](
+			t,
+			handleHistory,
+			MarkdownHistoryArgs{FilePath: filePath},
+		)
+	}
+	undo := func(redo bool) {
+		t.Helper()
+		runTool[MarkdownUndoResponse](
+			t,
+			handleUndo,
+			MarkdownUndoArgs{FilePath: filePath, Redo: &redo},
+		)
+	}
+
+	if response := history(); len(response.Edits) != 0 ||
+		response.CanUndo || response.CanRedo || response.FileHash != hash {
+		t.Errorf("Unexpected response %+v", response)
+	}
+
+	first := replaceBody(t, filePath, "Phase 1", "New\ntext.", hash)
+	undo(false)
+
+	response := history()
+	if len(response.Edits) != 1 || response.CanUndo || !response.CanRedo {
+		t.Fatalf("Unexpected response %+v", response)
+	}
+	edit := response.Edits[0]
+	if edit.ID != 1 || edit.Tool != "markdown_replace_section" ||
+		edit.BeforeHash != hash || edit.AfterHash != first.FileHash ||
+		edit.LinesRemoved != 1 || edit.LinesAdded != 2 || !edit.Undone {
+		t.Errorf("Unexpected edit %+v", edit)
+	}
+
+	undo(true)
+	if response := history(); !response.CanUndo ||
+		response.CanRedo || response.Edits[0].Undone {
+		t.Errorf("Unexpected response %+v", response)
+	}
+
+	if err := os.WriteFile(filePath, []byte(undoDoc), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if response := history(); response.CanUndo ||
+		response.FileHash != hash {
+		t.Errorf("Unexpected response after external change %+v", response)
+	}
+}
@@ -0,0 +1,202 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// Positions of an inserted section relative to the target section.
+const (
+	positionBefore     = "before"
+	positionAfter      = "after"
+	positionFirstChild = "first_child"
+	positionLastChild  = "last_child"
+)
+
+// MarkdownInsertSectionArgs defines the input arguments.
+type MarkdownInsertSectionArgs struct {
+	FilePath       string  `json:"file_path"                description:"Path to markdown file"                                                                                                                                                 required:"true"`
+	SectionHeading string  `json:"section_heading"          description:"Target section: heading text (without # symbols), a heading path separated by ' > ' or an anchor such as '#phase-4'"                                                   required:"true"`
+	Occurrence     *int    `json:"occurrence,omitempty"     description:"Which match to use when several sections match (1=first). Default: 1"`
+	MatchMode      *string `json:"match_mode,omitempty"     description:"How heading names are compared: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive  *bool   `json:"case_sensitive,omitempty" description:"Compare heading names case-sensitively. Default: false"`
+	Strict         *bool   `json:"strict,omitempty"         description:"Fail with a list of candidate sections instead of using the first match when the heading is ambiguous. Default: false"`
+	Position       string  `json:"position"                 description:"Where to insert relative to the target: 'before' or 'after' (a sibling, after the target's subsections) or 'first_child' or 'last_child' (a subsection)"               required:"true"`
+	Heading        string  `json:"heading"                  description:"Text of the new heading, without # symbols. The level is derived from the target and position"                                                                         required:"true"`
+	Content        *string `json:"content,omitempty"        description:"Body of the new section. Headings in it must be deeper than the new heading. Default: empty"`
+	ExpectedHash   *string `json:"expected_hash,omitempty"  description:"file_hash returned by a prior markdown_read_section or markdown_section_bounds. The edit fails if the file changed since. expected_hash or expected_mtime is required"`
+	ExpectedMtime  *string `json:"expected_mtime,omitempty" description:"mtime returned by a prior markdown_read_section or markdown_section_bounds (RFC 3339). The edit fails if the file changed since"`
+}
+
+// MarkdownInsertSectionResponse defines the response structure.
+type MarkdownInsertSectionResponse struct {
+	SectionName  string   `json:"section_name"`
+	Slug         string   `json:"slug"`
+	HeadingPath  []string `json:"heading_path"`
+	HeadingLevel string   `json:"heading_level"`
+	StartLine    int      `json:"start_line"`
+	EndLine      int      `json:"end_line"`
+	FileHash     string   `json:"file_hash"` // Version after the edit
+	Mtime        string   `json:"mtime"`
+}
+
+// RegisterMarkdownInsertSection registers the markdown_insert_section tool.
+func RegisterMarkdownInsertSection(srv server.Server) {
+	srv.Tool(
+		"markdown_insert_section",
+		"Insert a new section before or after a target section (as a sibling) or as its first or last subsection. The heading level is chosen from the target, blank lines around the new section are normalized and its bounds are returned. Requires expected_hash or expected_mtime from a prior read.",
+		handleInsertSection,
+	)
+}
+
+// handleInsertSection implements the markdown_insert_section tool logic.
+func handleInsertSection(
+	_ *server.Context,
+	args MarkdownInsertSectionArgs,
+) (interface{}, error) {
+	heading, err := headingText(args.Heading, "heading")
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := loadDocument(args.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(
+		doc.version,
+		args.ExpectedHash,
+		args.ExpectedMtime,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(doc.entries) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoEntries, args.FilePath)
+	}
+
+	target, err := resolveSection(doc.entries, sectionAddress{
+		Heading:       args.SectionHeading,
+		Occurrence:    args.Occurrence,
+		MatchMode:     args.MatchMode,
+		CaseSensitive: args.CaseSensitive,
+		Strict:        args.Strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var body []string
+	if args.Content != nil {
+		body = contentLines(*args.Content)
+	}
+	lines, line, err := insertSection(doc, target, args.Position, heading, body)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := doc.save("markdown_insert_section", lines)
+	if err != nil {
+		return nil, err
+	}
+
+	section, ok := updated.sectionAt(line)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: no heading at line %d after the edit",
+			ErrSectionNotFound,
+			line,
+		)
+	}
+
+	return MarkdownInsertSectionResponse{
+		SectionName:  section.Name,
+		Slug:         section.Slug,
+		HeadingPath:  section.HeadingPath(),
+		HeadingLevel: fmt.Sprintf("H%d", section.Level),
+		StartLine:    section.Line,
+		EndLine:      section.End,
+		FileHash:     updated.version.Hash,
+		Mtime:        formatModTime(updated.version.ModTime),
+	}, nil
+}
+
+// insertSection returns the lines of doc with a new section, made of
+// heading and body, inserted at position relative to target, and the line
+// of its heading.
+//
+// Errors include: ErrInvalidPosition, ErrInvalidLevel, ErrInvalidContent.
+func insertSection(
+	doc *document,
+	target *ctags.TagEntry,
+	position string,
+	heading string,
+	body []string,
+) ([]string, int, error) {
+	at, level, err := insertionPoint(doc, target, position)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := validateSubheadings(
+		body,
+		heading,
+		level,
+	); err != nil {
+		return nil, 0, err
+	}
+
+	block := []string{strings.Repeat("#", level) + " " + heading}
+	if len(body) > 0 {
+		block = append(block, "")
+		block = append(block, body...)
+	}
+
+	lines, line := insertBlock(doc.lines, at, block)
+	return lines, line, nil
+}
+
+// insertionPoint returns the line before which a section is inserted at
+// position relative to target, and the level of its heading. Siblings keep
+// the target's level and children are one level deeper; 'after' and
+// 'last_child' insert after the target's subsections, 'first_child' before
+// them.
+//
+// Errors include: ErrInvalidPosition, ErrInvalidLevel.
+func insertionPoint(
+	doc *document,
+	target *ctags.TagEntry,
+	position string,
+) (int, int, error) {
+	switch position {
+	case positionBefore:
+		return target.Line, target.Level, nil
+	case positionAfter:
+		return target.End + 1, target.Level, nil
+	case positionFirstChild, positionLastChild:
+		if target.Level >= maxHeadingLevel {
+			return 0, 0, fmt.Errorf(
+				"%w: '%s' is H%d, subsections would be deeper than H%d",
+				ErrInvalidLevel,
+				target.Name,
+				target.Level,
+				maxHeadingLevel,
+			)
+		}
+		if position == positionFirstChild {
+			return doc.bodyEnd(target) + 1, target.Level + 1, nil
+		}
+		return target.End + 1, target.Level + 1, nil
+	default:
+		return 0, 0, fmt.Errorf(
+			"%w: %q (must be '%s', '%s', '%s' or '%s')",
+			ErrInvalidPosition,
+			position,
+			positionBefore,
+			positionAfter,
+			positionFirstChild,
+			positionLastChild,
+		)
+	}
+}
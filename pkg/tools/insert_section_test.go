@@ -0,0 +1,229 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// insertSectionDoc is the document edited by the insert tests.
+const insertSectionDoc = "# Plan\n" +
+	"\n" +
+	"## Phase 4\n" +
+	"\n" +
+	"Phase text.\n" +
+	"\n" +
+	"### Task 4.1\n" +
+	"\n" +
+	"### Task 4.2\n" +
+	"Task text.\n" +
+	"## Phase 5\n" +
+	"Last."
+
+func TestHandleInsertSection_Positions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		position  string
+		expected  string
+		startLine int
+		level     string
+	}{
+		{
+			name:     "last child",
+			position: "last_child",
+			expected: "# Plan\n\n## Phase 4\n\nPhase text.\n\n" +
+				"### Task 4.1\n\n### Task 4.2\nTask text.\n\n" +
+				"### Task 4.3\n\nNew text.\n\n" +
+				"## Phase 5\nLast.",
+			startLine: 12,
+			level:     "H3",
+		},
+		{
+			name:     "first child",
+			position: "first_child",
+			expected: "# Plan\n\n## Phase 4\n\nPhase text.\n\n" +
+				"### Task 4.3\n\nNew text.\n\n" +
+				"### Task 4.1\n\n### Task 4.2\nTask text.\n" +
+				"## Phase 5\nLast.",
+			startLine: 7,
+			level:     "H3",
+		},
+		{
+			name:     "before",
+			position: "before",
+			expected: "# Plan\n\n" +
+				"## Task 4.3\n\nNew text.\n\n" +
+				"## Phase 4\n\nPhase text.\n\n" +
+				"### Task 4.1\n\n### Task 4.2\nTask text.\n" +
+				"## Phase 5\nLast.",
+			startLine: 3,
+			level:     "H2",
+		},
+		{
+			name:     "after",
+			position: "after",
+			expected: "# Plan\n\n## Phase 4\n\nPhase text.\n\n" +
+				"### Task 4.1\n\n### Task 4.2\nTask text.\n\n" +
+				"## Task 4.3\n\nNew text.\n\n" +
+				"## Phase 5\nLast.",
+			startLine: 12,
+			level:     "H2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, insertSectionDoc)
+			response := runTool[MarkdownInsertSectionResponse](
+				t,
+				handleInsertSection,
+				MarkdownInsertSectionArgs{
+					FilePath:       filePath,
+					SectionHeading: "Phase 4",
+					Position:       tt.position,
+					Heading:        "Task 4.3",
+					Content:        ptr("\n\nNew text.\n\n"),
+					ExpectedHash:   &hash,
+				},
+			)
+
+			if got := readFixture(t, filePath); got != tt.expected {
+				t.Errorf("Unexpected content:\n%s", got)
+			}
+			if response.StartLine != tt.startLine ||
+				response.EndLine != tt.startLine+3 ||
+				response.HeadingLevel != tt.level ||
+				response.Slug != "task-43" {
+				t.Errorf("Unexpected section %+v", response)
+			}
+		})
+	}
+}
+
+func TestHandleInsertSection_EndOfFile(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, insertSectionDoc)
+	response := runTool[MarkdownInsertSectionResponse](
+		t,
+		handleInsertSection,
+		MarkdownInsertSectionArgs{
+			FilePath:       filePath,
+			SectionHeading: "Phase 5",
+			Position:       "after",
+			Heading:        "Phase 6",
+			ExpectedHash:   &hash,
+		},
+	)
+
+	if got := readFixture(t, filePath); got != insertSectionDoc+
+		"\n\n## Phase 6" {
+		t.Errorf("Unexpected content %q", got)
+	}
+	if response.StartLine != 14 || response.EndLine != 14 {
+		t.Errorf("Unexpected bounds %d-%d",
+			response.StartLine, response.EndLine)
+	}
+	if len(response.HeadingPath) != 2 || response.HeadingPath[0] != "Plan" {
+		t.Errorf("Unexpected heading path %v", response.HeadingPath)
+	}
+}
+
+func TestHandleInsertSection_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	const doc = "# A\n## B\n### C\n#### D\n##### E\n###### F\n"
+	tests := []struct {
+		name     string
+		target   string
+		position string
+		heading  string
+		content  *string
+		hash     string
+		expected error
+	}{
+		{
+			name:     "child of H6",
+			target:   "F",
+			position: "first_child",
+			heading:  "G",
+			expected: ErrInvalidLevel,
+		},
+		{
+			name:     "invalid position",
+			target:   "B",
+			position: "inside",
+			heading:  "G",
+			expected: ErrInvalidPosition,
+		},
+		{
+			name:     "multi-line heading",
+			target:   "B",
+			position: "after",
+			heading:  "G\nH",
+			expected: ErrInvalidContent,
+		},
+		{
+			name:     "sibling heading in content",
+			target:   "B",
+			position: "after",
+			heading:  "G",
+			content:  ptr("## Sibling"),
+			expected: ErrInvalidContent,
+		},
+		{
+			name:     "open fence",
+			target:   "B",
+			position: "after",
+			heading:  "G",
+			content:  ptr("Steps:\n\n~~~sh\nmake"),
+			expected: ErrInvalidContent,
+		},
+		{
+			name:     "open comment",
+			target:   "C",
+			position: "last_child",
+			heading:  "G",
+			content:  ptr("<!-- TODO"),
+			expected: ErrInvalidContent,
+		},
+		{
+			name:     "stale version",
+			target:   "B",
+			position: "after",
+			heading:  "G",
+			hash:     "0000",
+			expected: ctags.ErrFileChanged,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, doc)
+			if tt.hash != "" {
+				hash = tt.hash
+			}
+			_, err := handleInsertSection(nil, MarkdownInsertSectionArgs{
+				FilePath:       filePath,
+				SectionHeading: tt.target,
+				Position:       tt.position,
+				Heading:        tt.heading,
+				Content:        tt.content,
+				ExpectedHash:   &hash,
+			})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if got := readFixture(t, filePath); got != doc {
+				t.Errorf("Rejected insert changed the file:\n%s", got)
+			}
+		})
+	}
+}
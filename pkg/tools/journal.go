@@ -0,0 +1,244 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// DefaultJournalEntries is the default number of edits kept per file for
+// markdown_undo.
+const DefaultJournalEntries = 20
+
+// journalEntry is one write made by an edit tool. The patches turn the
+// lines after the edit back into the lines before it and the other way
+// round.
+type journalEntry struct {
+	id         int // Per file, counting from 1
+	tool       string
+	time       time.Time
+	beforeHash string
+	afterHash  string
+	reverse    []lineEdit // Undo: applied to the lines after the edit
+	forward    []lineEdit // Redo: applied to the lines before the edit
+	undone     bool
+}
+
+// editJournal keeps the most recent edits of every file written by the
+// server, so that they can be undone and redone. Entries are kept per
+// file under the file's resolved absolute path.
+type editJournal struct {
+	mu      sync.Mutex
+	limit   int // Entries kept per file, 0 disables the journal
+	files   map[string][]*journalEntry
+	lastIDs map[string]int
+}
+
+// globalJournal records the edits of all edit tools, like the global tag
+// cache it is shared by all tool calls.
+var globalJournal = newEditJournal(DefaultJournalEntries) //nolint:gochecknoglobals // singleton journal pattern
+
+// getJournal returns the global edit journal.
+func getJournal() *editJournal {
+	return globalJournal
+}
+
+// SetJournalLimit sets how many edits are kept per file for markdown_undo.
+// 0 disables the journal; entries beyond a lower limit are dropped.
+//
+// Errors include: ErrInvalidLimit.
+func SetJournalLimit(limit int) error {
+	if limit < 0 {
+		return fmt.Errorf(
+			"%w: journal limit %d (must be >= 0)",
+			ErrInvalidLimit,
+			limit,
+		)
+	}
+	getJournal().setLimit(limit)
+	return nil
+}
+
+// newEditJournal returns an empty journal keeping limit entries per file.
+func newEditJournal(limit int) *editJournal {
+	return &editJournal{
+		mu:      sync.Mutex{},
+		limit:   limit,
+		files:   make(map[string][]*journalEntry),
+		lastIDs: make(map[string]int),
+	}
+}
+
+// setLimit changes the number of entries kept per file.
+func (j *editJournal) setLimit(limit int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.limit = limit
+	for key, entries := range j.files {
+		j.files[key] = entries[max(len(entries)-limit, 0):]
+	}
+}
+
+// record adds the edit from before to after made by tool. Undone entries
+// of the file are dropped: they can no longer be redone on top of the new
+// edit.
+func (j *editJournal) record(before, after *document, tool string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.limit == 0 {
+		return
+	}
+
+	key := journalKey(before.filePath)
+	entries := slices.DeleteFunc(j.files[key], func(entry *journalEntry) bool {
+		return entry.undone
+	})
+
+	j.lastIDs[key]++
+	entries = append(entries, &journalEntry{
+		id:         j.lastIDs[key],
+		tool:       tool,
+		time:       time.Now(),
+		beforeHash: before.version.Hash,
+		afterHash:  after.version.Hash,
+		reverse:    clonePatch(diffLines(after.lines, before.lines)),
+		forward:    clonePatch(diffLines(before.lines, after.lines)),
+		undone:     false,
+	})
+	j.files[key] = entries[max(len(entries)-j.limit, 0):]
+}
+
+// entries returns a copy of the entries of a file, oldest first.
+func (j *editJournal) entries(filePath string) []journalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := j.files[journalKey(filePath)]
+	result := make([]journalEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, *entry)
+	}
+	return result
+}
+
+// setUndone marks the entry id of a file as undone or redone.
+func (j *editJournal) setUndone(filePath string, id int, undone bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, entry := range j.files[journalKey(filePath)] {
+		if entry.id == id {
+			entry.undone = undone
+		}
+	}
+}
+
+// undoable returns the entry markdown_undo reverts: the latest edit that
+// was not undone.
+func undoable(entries []journalEntry) (journalEntry, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].undone {
+			return entries[i], true
+		}
+	}
+	return journalEntry{}, false
+}
+
+// redoable returns the entry markdown_undo with redo re-applies: the
+// oldest of the undone edits at the end of the journal.
+func redoable(entries []journalEntry) (journalEntry, bool) {
+	i := len(entries)
+	for i > 0 && entries[i-1].undone {
+		i--
+	}
+	if i == len(entries) {
+		return journalEntry{}, false
+	}
+	return entries[i], true
+}
+
+// journalKey returns the path a file is journaled under: absolute, with
+// symbolic links resolved, so that every way of naming the file shares
+// one history.
+func journalKey(filePath string) string {
+	path, err := filepath.Abs(filePath)
+	if err != nil {
+		path = filePath
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// clonePatch copies the lines of edits, so that a journal entry does not
+// keep the whole document it was computed from alive.
+func clonePatch(edits []lineEdit) []lineEdit {
+	result := make([]lineEdit, 0, len(edits))
+	for _, edit := range edits {
+		result = append(result, lineEdit{
+			From:  edit.From,
+			To:    edit.To,
+			Lines: slices.Clone(edit.Lines),
+		})
+	}
+	return result
+}
+
+// patchLines applies edits (sorted, not overlapping) to lines.
+func patchLines(lines []string, edits []lineEdit) []string {
+	for i := len(edits) - 1; i >= 0; i-- { // Keeps earlier ranges valid
+		lines = spliceLines(lines, edits[i].From, edits[i].To, edits[i].Lines)
+	}
+	return lines
+}
+
+// replay applies a patch of entry to d, which must have the content hash
+// the patch starts from, and writes the result unless it does not have the
+// hash the patch leads to.
+//
+// Errors include: ctags.ErrFileChanged.
+func (d *document) replay(
+	entry journalEntry,
+	redo bool,
+) (*document, error) {
+	from, to, patch := entry.afterHash, entry.beforeHash, entry.reverse
+	action := "undo"
+	if redo {
+		from, to, patch = entry.beforeHash, entry.afterHash, entry.forward
+		action = "redo"
+	}
+
+	if d.version.Hash != from {
+		return nil, fmt.Errorf(
+			"%w: %s changed outside the server since edit %d (%s); "+
+				"refusing to %s (expected hash %s, file has %s)",
+			ctags.ErrFileChanged,
+			d.filePath,
+			entry.id,
+			entry.tool,
+			action,
+			from,
+			d.version.Hash,
+		)
+	}
+
+	lines := patchLines(d.lines, patch)
+	if hash := ctags.ContentHash(d.render(lines)); hash != to {
+		return nil, fmt.Errorf(
+			"%w: %s of edit %d would give hash %s instead of %s",
+			ctags.ErrFileChanged,
+			action,
+			entry.id,
+			hash,
+			to,
+		)
+	}
+	return d.write(lines)
+}
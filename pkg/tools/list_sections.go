@@ -10,14 +10,20 @@ import (
 
 // MarkdownListSectionsArgs defines the input arguments.
 type MarkdownListSectionsArgs struct {
-	FilePath           string  `json:"file_path"                      description:"Path to markdown file to list sections from"                                                                            required:"true"`
+	FilePath           string  `json:"file_path"                      description:"Path to markdown file to list sections from"                                                                                                                                          required:"true"`
 	MaxDepth           *int    `json:"max_depth,omitempty"            description:"Maximum heading depth to show (1-6). Default: 2 (H1+H2). Use 0 for all levels. Example: 1=only H1, 2=H1+H2, 3=H1+H2+H3"`
-	SectionNamePattern *string `json:"section_name_pattern,omitempty" description:"Regex pattern to filter section names. Example: 'Task.*' matches sections starting with 'Task'"`
+	SectionNamePattern *string `json:"section_name_pattern,omitempty" description:"Filter section names. Interpreted per match_mode; e.g. 'Task.*' with match_mode 'regex' or 'Task *' with 'glob'. Default: case-insensitive substring"`
+	SectionHeading     *string `json:"section_heading,omitempty"      description:"List only this section and its subsections. Heading text or a path separated by ' > ', e.g. 'Phase 2 > Task 2.1'. max_depth then counts levels from that section"`
+	Occurrence         *int    `json:"occurrence,omitempty"           description:"Which match of section_heading to use when several sections match (1=first). Default: 1"`
+	MatchMode          *string `json:"match_mode,omitempty"           description:"How section_heading and section_name_pattern are compared with heading names: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive      *bool   `json:"case_sensitive,omitempty"       description:"Compare heading names case-sensitively. Default: false"`
+	Strict             *bool   `json:"strict,omitempty"               description:"Fail with a list of candidate sections instead of using the first match when section_heading is ambiguous. Default: false"`
 }
 
 // SectionInfo represents a single section in the list.
 type SectionInfo struct {
 	Name      string `json:"name"`
+	Slug      string `json:"slug"`
 	StartLine int    `json:"start_line"`
 	EndLine   int    `json:"end_line"`
 	Level     string `json:"level"`
@@ -66,15 +72,39 @@ func RegisterMarkdownListSections(srv server.Server) {
 				)
 			}
 
+			// Restrict to a section subtree if requested
+			filteredEntries, maxDepth, err = scopeToSection(
+				filteredEntries,
+				sectionAddressFromArgs(
+					args.SectionHeading,
+					args.Occurrence,
+					args.MatchMode,
+					args.CaseSensitive,
+					args.Strict,
+				),
+				maxDepth,
+			)
+			if err != nil {
+				return nil, err
+			}
+
 			// Apply depth filtering (0 means all levels, no filtering)
 			filteredEntries = ctags.FilterByDepth(filteredEntries, maxDepth)
 
 			// Filter by pattern if specified
 			if args.SectionNamePattern != nil &&
 				*args.SectionNamePattern != "" {
-				filteredEntries = ctags.FilterByPattern(
-					filteredEntries,
+				matcher, err := newNameMatcher(
 					*args.SectionNamePattern,
+					args.MatchMode,
+					args.CaseSensitive,
+				)
+				if err != nil {
+					return nil, err
+				}
+				filteredEntries = ctags.FilterByMatcher(
+					filteredEntries,
+					matcher,
 				)
 			}
 
@@ -83,6 +113,7 @@ func RegisterMarkdownListSections(srv server.Server) {
 			for _, entry := range filteredEntries {
 				sections = append(sections, SectionInfo{
 					Name:      entry.Name,
+					Slug:      entry.Slug,
 					StartLine: entry.Line,
 					EndLine:   entry.End,
 					Level:     fmt.Sprintf("H%d", entry.Level),
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
 	"testing"
 
@@ -657,3 +658,80 @@ func TestMarkdownListSections_AllLevelsWithPattern(t *testing.T) {
 		)
 	}
 }
+
+func TestMarkdownListSections_PatternMatchModes(t *testing.T) {
+	t.Parallel()
+
+	targetFile := filepath.Join("..", "..", "testdata", "sample.md")
+
+	cache := ctags.GetGlobalCache()
+	entries, err := cache.GetTags(context.Background(), targetFile)
+	if err != nil {
+		t.Fatalf("GetTags failed: %v", err)
+	}
+
+	regex := "regex"
+	glob := "glob"
+	caseSensitive := true
+
+	testCases := []struct {
+		pattern       string
+		matchMode     *string
+		caseSensitive *bool
+		expectedCount int
+	}{
+		{"Section.*", nil, nil, 0},                          // Substring by default
+		{"^Section [0-9]:", &regex, nil, 3},                 // Anchored regex
+		{"section.*testing", &regex, nil, 1},                // Case-insensitive regex
+		{"section.*testing", &regex, &caseSensitive, 0},     // Case-sensitive regex
+		{"Subsection 2.?: *", &glob, nil, 2},                // Whole-name glob
+		{"deep section *", &glob, nil, 2},                   // Case-insensitive glob
+		{"Deep*", nil, &caseSensitive, 0},                   // Substring, no glob
+		{"Subsection", nil, &caseSensitive, 4},              // Case-sensitive substring
+		{"subsection", nil, &caseSensitive, 0},              // Case-sensitive substring
+		{"Section [13]: *", &glob, nil, 2},                  // Glob character class
+		{"Conclusion$", &regex, nil, 1},                     // Regex end anchor
+		{"(Unit|Integration) Tests", &regex, nil, 2},        // Regex alternation
+		{"Integration Tests", &glob, nil, 0},                // Glob matches whole name
+		{"*Integration Tests", &glob, nil, 1},               // Leading wildcard
+		{"Section 2.2", &regex, nil, 3},                     // '.' matches any char
+		{"Subsection 2.2: Testing", nil, &caseSensitive, 1}, // Exact text
+	}
+
+	for _, tc := range testCases {
+		matcher, err := newNameMatcher(
+			tc.pattern,
+			tc.matchMode,
+			tc.caseSensitive,
+		)
+		if err != nil {
+			t.Fatalf("newNameMatcher(%q) failed: %v", tc.pattern, err)
+		}
+
+		filtered := ctags.FilterByMatcher(entries, matcher)
+		if len(filtered) != tc.expectedCount {
+			t.Errorf(
+				"Pattern %q: expected %d matches, got %d",
+				tc.pattern,
+				tc.expectedCount,
+				len(filtered),
+			)
+		}
+	}
+}
+
+func TestMarkdownListSections_InvalidPattern(t *testing.T) {
+	t.Parallel()
+
+	regex := "regex"
+	_, err := newNameMatcher("Task (", &regex, nil)
+	if !errors.Is(err, ctags.ErrInvalidPattern) {
+		t.Errorf("Expected ErrInvalidPattern, got %v", err)
+	}
+
+	invalidMode := "fuzzy"
+	_, err = newNameMatcher("Task", &invalidMode, nil)
+	if !errors.Is(err, ctags.ErrInvalidMatchMode) {
+		t.Errorf("Expected ErrInvalidMatchMode, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// MarkdownMoveSectionArgs defines the input arguments.
+type MarkdownMoveSectionArgs struct {
+	FilePath         string  `json:"file_path"                   description:"Path to markdown file"                                                                                                                                   required:"true"`
+	SectionHeading   string  `json:"section_heading"             description:"Section to move: heading text (without # symbols), a heading path separated by ' > ' or an anchor such as '#task-21-testing'"                             required:"true"`
+	Occurrence       *int    `json:"occurrence,omitempty"        description:"Which match of section_heading to use when several sections match (1=first). Default: 1"`
+	TargetHeading    string  `json:"target_heading"              description:"Section the moved section is placed relative to, addressed like section_heading. It must not be inside the moved section"                               required:"true"`
+	TargetOccurrence *int    `json:"target_occurrence,omitempty" description:"Which match of target_heading to use when several sections match (1=first). Default: 1"`
+	MatchMode        *string `json:"match_mode,omitempty"        description:"How heading names are compared, for both headings: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive    *bool   `json:"case_sensitive,omitempty"    description:"Compare heading names case-sensitively. Default: false"`
+	Strict           *bool   `json:"strict,omitempty"            description:"Fail with a list of candidate sections instead of using the first match when a heading is ambiguous. Default: false"`
+	Position         string  `json:"position"                    description:"Where to move relative to the target: 'before' or 'after' (a sibling, after the target's subsections) or 'first_child' or 'last_child' (a subsection)" required:"true"`
+	ExpectedHash     *string `json:"expected_hash,omitempty"     description:"file_hash returned by a prior markdown_read_section or markdown_section_bounds. The edit fails if the file changed since. expected_hash or expected_mtime is required"`
+	ExpectedMtime    *string `json:"expected_mtime,omitempty"    description:"mtime returned by a prior markdown_read_section or markdown_section_bounds (RFC 3339). The edit fails if the file changed since"`
+}
+
+// MarkdownMoveSectionResponse defines the response structure.
+type MarkdownMoveSectionResponse struct {
+	SectionName  string   `json:"section_name"`
+	Slug         string   `json:"slug"`
+	HeadingPath  []string `json:"heading_path"` // Path at the new position
+	HeadingLevel string   `json:"heading_level"`
+	LevelShift   int      `json:"level_shift"` // Added to every moved heading
+	StartLine    int      `json:"start_line"`  // Bounds after the edit
+	EndLine      int      `json:"end_line"`
+	FileHash     string   `json:"file_hash"` // Version after the edit
+	Mtime        string   `json:"mtime"`
+}
+
+// RegisterMarkdownMoveSection registers the markdown_move_section tool.
+func RegisterMarkdownMoveSection(srv server.Server) {
+	srv.Tool(
+		"markdown_move_section",
+		"Move a section with all its subsections before or after a target section (as a sibling) or into it as its first or last subsection. Every moved heading is re-leveled to fit the new position (e.g. H3 to H2 when promoted); body text is kept unchanged. The file is replaced atomically. Requires expected_hash or expected_mtime from a prior read.",
+		handleMoveSection,
+	)
+}
+
+// handleMoveSection implements the markdown_move_section tool logic.
+func handleMoveSection(
+	_ *server.Context,
+	args MarkdownMoveSectionArgs,
+) (interface{}, error) {
+	doc, err := loadDocument(args.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(
+		doc.version,
+		args.ExpectedHash,
+		args.ExpectedMtime,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(doc.entries) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoEntries, args.FilePath)
+	}
+
+	source, err := resolveSection(doc.entries, sectionAddress{
+		Heading:       args.SectionHeading,
+		Occurrence:    args.Occurrence,
+		MatchMode:     args.MatchMode,
+		CaseSensitive: args.CaseSensitive,
+		Strict:        args.Strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+	target, err := resolveSection(doc.entries, sectionAddress{
+		Heading:       args.TargetHeading,
+		Occurrence:    args.TargetOccurrence,
+		MatchMode:     args.MatchMode,
+		CaseSensitive: args.CaseSensitive,
+		Strict:        args.Strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lines, line, err := moveSubtree(doc, source, target, args.Position)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := doc.save("markdown_move_section", lines)
+	if err != nil {
+		return nil, err
+	}
+
+	section, ok := updated.sectionAt(line)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: no heading at line %d after the edit",
+			ErrSectionNotFound,
+			line,
+		)
+	}
+
+	return MarkdownMoveSectionResponse{
+		SectionName:  section.Name,
+		Slug:         section.Slug,
+		HeadingPath:  section.HeadingPath(),
+		HeadingLevel: fmt.Sprintf("H%d", section.Level),
+		LevelShift:   section.Level - source.Level,
+		StartLine:    section.Line,
+		EndLine:      section.End,
+		FileHash:     updated.version.Hash,
+		Mtime:        formatModTime(updated.version.ModTime),
+	}, nil
+}
+
+// moveSubtree returns the lines of doc with the subtree of source moved to
+// position relative to target and re-leveled to fit there, and the line
+// of the moved heading.
+//
+// Errors include: ErrInvalidPosition, ErrInvalidLevel.
+func moveSubtree(
+	doc *document,
+	source *ctags.TagEntry,
+	target *ctags.TagEntry,
+	position string,
+) ([]string, int, error) {
+	if target.Line >= source.Line && target.Line <= source.End {
+		return nil, 0, fmt.Errorf(
+			"%w: '%s' is inside the moved section '%s'",
+			ErrInvalidPosition,
+			target.Name,
+			source.Name,
+		)
+	}
+
+	at, level, err := insertionPoint(doc, target, position)
+	if err != nil {
+		return nil, 0, err
+	}
+	block, err := doc.relevelRange(source.Line, source.End, level-source.Level)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	lines := removeLines(doc.lines, source.Line, source.End)
+	if at > source.End {
+		at -= source.End - source.Line + 1
+	}
+	at = min(at, len(lines)+1)
+
+	lines, line := insertBlock(lines, at, block)
+	return lines, line, nil
+}
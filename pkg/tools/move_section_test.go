@@ -0,0 +1,231 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// moveSectionDoc is the document edited by the move tests.
+const moveSectionDoc = "# Plan\n" +
+	"\n" +
+	"## Phase 1\n" +
+	"\n" +
+	"### Task 1.1\n" +
+	"\n" +
+	"Task text.\n" +
+	"\n" +
+	"#### Notes\n" +
+	"\n" +
+	"```\n" +
+	"## Not a heading\n" +
+	"```\n" +
+	"\n" +
+	"## Phase 2\n" +
+	"\n" +
+	"Phase text.\n"
+
+func TestHandleMoveSection_Positions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		target    string
+		position  string
+		expected  string
+		startLine int
+		shift     int
+	}{
+		{
+			name:     "promote after sibling",
+			target:   "Phase 2",
+			position: "after",
+			expected: "# Plan\n\n## Phase 1\n\n## Phase 2\n\nPhase text.\n\n" +
+				"## Task 1.1\n\nTask text.\n\n### Notes\n\n" +
+				"```\n## Not a heading\n```\n",
+			startLine: 9,
+			shift:     -1,
+		},
+		{
+			name:     "promote before sibling",
+			target:   "Phase 1",
+			position: "before",
+			expected: "# Plan\n\n## Task 1.1\n\nTask text.\n\n### Notes\n\n" +
+				"```\n## Not a heading\n```\n\n" +
+				"## Phase 1\n\n## Phase 2\n\nPhase text.\n",
+			startLine: 3,
+			shift:     -1,
+		},
+		{
+			name:     "same level into other parent",
+			target:   "Phase 2",
+			position: "last_child",
+			expected: "# Plan\n\n## Phase 1\n\n## Phase 2\n\nPhase text.\n\n" +
+				"### Task 1.1\n\nTask text.\n\n#### Notes\n\n" +
+				"```\n## Not a heading\n```\n",
+			startLine: 9,
+			shift:     0,
+		},
+		{
+			name:     "first child",
+			target:   "Phase 2",
+			position: "first_child",
+			expected: "# Plan\n\n## Phase 1\n\n## Phase 2\n\nPhase text.\n\n" +
+				"### Task 1.1\n\nTask text.\n\n#### Notes\n\n" +
+				"```\n## Not a heading\n```\n",
+			startLine: 9,
+			shift:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, moveSectionDoc)
+			response := runTool[MarkdownMoveSectionResponse](
+				t,
+				handleMoveSection,
+				MarkdownMoveSectionArgs{
+					FilePath:       filePath,
+					SectionHeading: "Task 1.1",
+					TargetHeading:  tt.target,
+					Position:       tt.position,
+					ExpectedHash:   &hash,
+				},
+			)
+
+			if got := readFixture(t, filePath); got != tt.expected {
+				t.Errorf("Unexpected content:\n%s", got)
+			}
+			if response.StartLine != tt.startLine ||
+				response.LevelShift != tt.shift ||
+				response.SectionName != "Task 1.1" {
+				t.Errorf("Unexpected section %+v", response)
+			}
+		})
+	}
+}
+
+func TestHandleMoveSection_Demote(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(
+		t,
+		"# Plan\n\nPhase 1\n-------\n\nText.\n\n## Phase 2\n",
+	)
+	response := runTool[MarkdownMoveSectionResponse](
+		t,
+		handleMoveSection,
+		MarkdownMoveSectionArgs{
+			FilePath:       filePath,
+			SectionHeading: "Phase 1",
+			TargetHeading:  "Phase 2",
+			Position:       "first_child",
+			ExpectedHash:   &hash,
+		},
+	)
+
+	expected := "# Plan\n\n## Phase 2\n\n### Phase 1\n\nText.\n"
+	if got := readFixture(t, filePath); got != expected {
+		t.Errorf("Unexpected content %q", got)
+	}
+	if response.HeadingLevel != "H3" || response.StartLine != 5 ||
+		response.EndLine != 7 {
+		t.Errorf("Unexpected section %+v", response)
+	}
+	if len(response.HeadingPath) != 3 ||
+		response.HeadingPath[1] != "Phase 2" {
+		t.Errorf("Unexpected heading path %v", response.HeadingPath)
+	}
+}
+
+func TestHandleMoveSection_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	const deepDoc = "# A\n## B\n### C\n#### D\n##### E\n## F\n### G\n"
+	tests := []struct {
+		name     string
+		doc      string
+		heading  string
+		target   string
+		position string
+		hash     string
+		expected error
+	}{
+		{
+			name:     "into own subtree",
+			doc:      moveSectionDoc,
+			heading:  "Phase 1",
+			target:   "Notes",
+			position: "last_child",
+			expected: ErrInvalidPosition,
+		},
+		{
+			name:     "relative to itself",
+			doc:      moveSectionDoc,
+			heading:  "Task 1.1",
+			target:   "Task 1.1",
+			position: "after",
+			expected: ErrInvalidPosition,
+		},
+		{
+			name:     "unknown position",
+			doc:      moveSectionDoc,
+			heading:  "Task 1.1",
+			target:   "Phase 2",
+			position: "inside",
+			expected: ErrInvalidPosition,
+		},
+		{
+			name:     "stale version",
+			doc:      moveSectionDoc,
+			heading:  "Task 1.1",
+			target:   "Phase 2",
+			position: "after",
+			hash:     "0000",
+			expected: ctags.ErrFileChanged,
+		},
+		{
+			name:     "own subtree below H6",
+			doc:      deepDoc,
+			heading:  "C",
+			target:   "E",
+			position: "last_child",
+			expected: ErrInvalidPosition,
+		},
+		{
+			name:     "subtree deeper than H6",
+			doc:      deepDoc,
+			heading:  "F",
+			target:   "E",
+			position: "last_child",
+			expected: ErrInvalidLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, tt.doc)
+			if tt.hash != "" {
+				hash = tt.hash
+			}
+			_, err := handleMoveSection(nil, MarkdownMoveSectionArgs{
+				FilePath:       filePath,
+				SectionHeading: tt.heading,
+				TargetHeading:  tt.target,
+				Position:       tt.position,
+				ExpectedHash:   &hash,
+			})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if got := readFixture(t, filePath); got != tt.doc {
+				t.Errorf("Rejected move changed the file:\n%s", got)
+			}
+		})
+	}
+}
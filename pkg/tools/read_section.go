@@ -1,11 +1,8 @@
 package tools
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"os"
-	"regexp"
 	"strings"
 
 	"github.com/localrivet/gomcp/server"
@@ -14,18 +11,26 @@ import (
 
 // MarkdownReadSectionArgs defines the input arguments.
 type MarkdownReadSectionArgs struct {
-	FilePath            string `json:"file_path"                       description:"Path to markdown file"                                                                                                                                                                  required:"true"`
-	SectionHeading      string `json:"section_heading"                 description:"Exact heading text to find (case-sensitive, without # symbols). Example: 'Task 2: Implementation' not '## Task 2: Implementation'"                                                      required:"true"`
-	MaxSubsectionLevels *int   `json:"max_subsection_levels,omitempty" description:"Limit subsection depth. Omit to read entire section (recommended). 0=no subsections, 1=immediate children only, 2=children+grandchildren. Warning: This LIMITS content, not expands it"`
+	FilePath            string  `json:"file_path"                       description:"Path to markdown file"                                                                                                                                                                                                                                                                 required:"true"`
+	SectionHeading      string  `json:"section_heading"                 description:"Heading text to find (without # symbols), or a heading path separated by ' > ' to address nested sections. An anchor such as '#task-21-testing' selects the section by its GitHub slug. Example: 'Task 2: Implementation', 'Phase 2 > Task 2.1 > Testing' or '#task-2-implementation'" required:"true"`
+	Occurrence          *int    `json:"occurrence,omitempty"            description:"Which match to use when several sections match (1=first). Default: 1"`
+	MatchMode           *string `json:"match_mode,omitempty"            description:"How heading names are compared: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive       *bool   `json:"case_sensitive,omitempty"        description:"Compare heading names case-sensitively. Default: false"`
+	Strict              *bool   `json:"strict,omitempty"                description:"Fail with a list of candidate sections (heading path, level, lines) instead of using the first match when the heading is ambiguous. Default: false"`
+	MaxSubsectionLevels *int    `json:"max_subsection_levels,omitempty" description:"Limit subsection depth. Omit to read entire section (recommended). 0=no subsections, 1=immediate children only, 2=children+grandchildren. Warning: This LIMITS content, not expands it"`
 }
 
 // MarkdownReadSectionResponse defines the response structure.
 type MarkdownReadSectionResponse struct {
-	Content     string `json:"content"`
-	SectionName string `json:"section_name"`
-	StartLine   int    `json:"start_line"`
-	EndLine     int    `json:"end_line"`
-	LinesRead   int    `json:"lines_read"`
+	Content     string   `json:"content"`
+	SectionName string   `json:"section_name"`
+	Slug        string   `json:"slug"`
+	HeadingPath []string `json:"heading_path"`
+	StartLine   int      `json:"start_line"`
+	EndLine     int      `json:"end_line"`
+	LinesRead   int      `json:"lines_read"`
+	FileHash    string   `json:"file_hash"` // Pass as expected_hash to edit
+	Mtime       string   `json:"mtime"`     // Or as expected_mtime
 }
 
 // RegisterMarkdownReadSection registers the markdown_read_section tool.
@@ -58,48 +63,49 @@ func handleReadSection(
 	}
 
 	// Find section bounds
-	startLine, endLine, sectionName, found := ctags.FindSectionBounds(
-		entries,
-		args.SectionHeading,
-	)
-	if !found {
-		return nil, fmt.Errorf(
-			"%w: '%s'",
-			ErrSectionNotFound,
-			args.SectionHeading,
-		)
+	entry, err := resolveSection(entries, sectionAddress{
+		Heading:       args.SectionHeading,
+		Occurrence:    args.Occurrence,
+		MatchMode:     args.MatchMode,
+		CaseSensitive: args.CaseSensitive,
+		Strict:        args.Strict,
+	})
+	if err != nil {
+		return nil, err
 	}
+	startLine, endLine := entry.Line, entry.End
 
 	// Read the full section content (without depth filtering at boundary level)
-	content, linesRead, err := readFileLines(
-		args.FilePath,
-		startLine,
-		endLine,
-	)
+	fileContent, version, err := ctags.ReadFileVersion(args.FilePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
+	content, linesRead := sectionLines(
+		ctags.SplitContentLines(fileContent),
+		startLine,
+		endLine,
+	)
 
 	// Apply depth filtering if maxSubsectionLevels parameter is provided
 	filteredContent := content
 	if args.MaxSubsectionLevels != nil {
-		// Find the root section level
-		rootLevel := findSectionLevel(entries, startLine)
-		if rootLevel > 0 {
-			filteredContent = filterContentByMaxSubsectionLevels(
-				rootLevel,
-				*args.MaxSubsectionLevels,
-				content,
-			)
-		}
+		filteredContent = filterContentByMaxSubsectionLevels(
+			entry.Level,
+			*args.MaxSubsectionLevels,
+			content,
+		)
 	}
 
 	return MarkdownReadSectionResponse{
 		Content:     filteredContent,
-		SectionName: sectionName,
+		SectionName: entry.Name,
+		Slug:        entry.Slug,
+		HeadingPath: entry.HeadingPath(),
 		StartLine:   startLine,
 		EndLine:     endLine,
 		LinesRead:   linesRead,
+		FileHash:    version.Hash,
+		Mtime:       formatModTime(version.ModTime),
 	}, nil
 }
 
@@ -126,14 +132,11 @@ func filterContentByMaxSubsectionLevels(
 	var result strings.Builder
 	inSkipMode := false
 
-	headingRegex := regexp.MustCompile(`^(#{1,6})\s+`)
-
 	lines := strings.Split(content, "\n")
-	for _, line := range lines {
+	headingLevels := headingLevelsByIndex(lines)
+	for i, line := range lines {
 		// Check if this line is a heading
-		if matches := headingRegex.FindStringSubmatch(line); matches != nil {
-			headingLevel := len(matches[1]) // Count the #'s
-
+		if headingLevel, isHeading := headingLevels[i]; isHeading {
 			if headingLevel > maxAllowedLevel {
 				inSkipMode = true // Too deep, start skipping
 			} else if headingLevel > rootLevel {
@@ -157,16 +160,14 @@ func filterContentByMaxSubsectionLevels(
 // the root section content without any subsections.
 func filterMaxSubsectionLevelsZero(rootLevel int, content string) string {
 	var result strings.Builder
-	headingRegex := regexp.MustCompile(`^(#{1,6})\s+`)
 
 	lines := strings.Split(content, "\n")
+	headingLevels := headingLevelsByIndex(lines)
 	firstLine := true
 
-	for _, line := range lines {
+	for i, line := range lines {
 		// Check if this line is a heading
-		if matches := headingRegex.FindStringSubmatch(line); matches != nil {
-			headingLevel := len(matches[1]) // Count the #'s
-
+		if headingLevel, isHeading := headingLevels[i]; isHeading {
 			// Include the root heading (first heading encountered)
 			if firstLine {
 				result.WriteString(line)
@@ -189,6 +190,16 @@ func filterMaxSubsectionLevelsZero(rootLevel int, content string) string {
 	return strings.TrimRight(result.String(), "\n")
 }
 
+// headingLevelsByIndex maps the zero-based index of every heading line to
+// its level. Lines inside code blocks and HTML comments are not headings.
+func headingLevelsByIndex(lines []string) map[int]int {
+	levels := make(map[int]int)
+	for _, heading := range ctags.ScanHeadings(lines) {
+		levels[heading.Index] = heading.Level
+	}
+	return levels
+}
+
 // calculateEndLine determines the actual end line based on maxSubsectionLevels parameter.
 // maxSubsectionLevels=nil: unlimited depth (read all subsections)
 // maxSubsectionLevels=0: no subsections (only section content)
@@ -324,37 +335,18 @@ func findSectionLevel(entries []*ctags.TagEntry, line int) int {
 	return 0
 }
 
-// readFileLines reads lines from a file between startLine and endLine (inclusive)
-// If endLine is 0, reads to EOF.
-func readFileLines(
-	filePath string,
-	startLine, endLine int,
-) (string, int, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", 0, fmt.Errorf("failed to open file: %w", err)
+// sectionLines returns the lines between startLine and endLine (1-based,
+// inclusive) joined with newlines, and their number. If endLine is 0, the
+// lines run to the end of the file.
+func sectionLines(lines []string, startLine, endLine int) (string, int) {
+	if endLine <= 0 || endLine > len(lines) {
+		endLine = len(lines)
 	}
-	defer file.Close()
-
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	currentLine := 1
-	linesRead := 0
-
-	for scanner.Scan() {
-		if currentLine >= startLine {
-			if endLine > 0 && currentLine > endLine {
-				break
-			}
-			lines = append(lines, scanner.Text())
-			linesRead++
-		}
-		currentLine++
-	}
-
-	if err := scanner.Err(); err != nil {
-		return "", 0, fmt.Errorf("failed to scan file: %w", err)
+	startLine = max(startLine, 1)
+	if startLine > endLine {
+		return "", 0
 	}
 
-	return strings.Join(lines, "\n"), linesRead, nil
+	selected := lines[startLine-1 : endLine]
+	return strings.Join(selected, "\n"), len(selected)
 }
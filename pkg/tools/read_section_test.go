@@ -860,3 +860,66 @@ Test data requirements and scenarios.`
 		)
 	}
 }
+
+// TestFilterContentByMaxSubsectionLevels_IgnoresCodeBlocks tests that
+// "# comment" lines inside fenced code are not treated as headings.
+func TestFilterContentByMaxSubsectionLevels_IgnoresCodeBlocks(t *testing.T) {
+	t.Parallel()
+
+	content := `## Setup
+Run:
+` + "```bash" + `
+# install
+make deps
+` + "```" + `
+### Details
+More details.`
+
+	result := filterContentByMaxSubsectionLevels(2, 0, content)
+
+	expected := `## Setup
+Run:
+` + "```bash" + `
+# install
+make deps
+` + "```"
+
+	if result != expected {
+		t.Errorf("Expected:\n%s\n\nGot:\n%s", expected, result)
+	}
+}
+
+// TestFilterContentByMaxSubsectionLevels_Setext tests that setext headings
+// are recognised by depth filtering and content trimming.
+func TestFilterContentByMaxSubsectionLevels_Setext(t *testing.T) {
+	t.Parallel()
+
+	content := `Guide
+=====
+Intro.
+
+Setup
+-----
+Setup text.
+
+### Details
+Details text.`
+
+	zero := filterContentByMaxSubsectionLevels(1, 0, content)
+	if zero != "Guide\n=====\nIntro." {
+		t.Errorf("Expected root content only, got:\n%s", zero)
+	}
+
+	one := filterContentByMaxSubsectionLevels(1, 1, content)
+	expected := `Guide
+=====
+Intro.
+
+Setup
+-----
+Setup text.
+`
+	if one != strings.TrimRight(expected, "\n") {
+		t.Errorf("Expected:\n%s\n\nGot:\n%s", expected, one)
+	}
+}
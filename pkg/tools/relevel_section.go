@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// MarkdownRelevelSectionArgs defines the input arguments.
+type MarkdownRelevelSectionArgs struct {
+	FilePath       string  `json:"file_path"                description:"Path to markdown file"                                                                                                                              required:"true"`
+	SectionHeading string  `json:"section_heading"          description:"Section to re-level: heading text (without # symbols), a heading path separated by ' > ' or an anchor such as '#task-21-testing'"                   required:"true"`
+	Occurrence     *int    `json:"occurrence,omitempty"     description:"Which match to use when several sections match (1=first). Default: 1"`
+	MatchMode      *string `json:"match_mode,omitempty"     description:"How heading names are compared: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive  *bool   `json:"case_sensitive,omitempty" description:"Compare heading names case-sensitively. Default: false"`
+	Strict         *bool   `json:"strict,omitempty"         description:"Fail with a list of candidate sections instead of using the first match when the heading is ambiguous. Default: false"`
+	Shift          int     `json:"shift"                    description:"Levels added to every heading of the section and its subsections: negative promotes (-1 turns H3 into H2), positive demotes. Must keep every heading within H1-H6" required:"true"`
+	ExpectedHash   *string `json:"expected_hash,omitempty"  description:"file_hash returned by a prior markdown_read_section or markdown_section_bounds. The edit fails if the file changed since. expected_hash or expected_mtime is required"`
+	ExpectedMtime  *string `json:"expected_mtime,omitempty" description:"mtime returned by a prior markdown_read_section or markdown_section_bounds (RFC 3339). The edit fails if the file changed since"`
+}
+
+// MarkdownRelevelSectionResponse defines the response structure.
+type MarkdownRelevelSectionResponse struct {
+	SectionName     string          `json:"section_name"`
+	Slug            string          `json:"slug"`
+	HeadingPath     []string        `json:"heading_path"` // Path after the edit
+	HeadingLevel    string          `json:"heading_level"`
+	HeadingKind     string          `json:"heading_kind"` // ctags kind, e.g. "section" for H2
+	LevelShift      int             `json:"level_shift"`
+	HeadingsChanged int             `json:"headings_changed"`
+	StartLine       int             `json:"start_line"` // Bounds after the edit
+	EndLine         int             `json:"end_line"`
+	Tree            *ctags.TreeNode `json:"tree"`      // Subtree after the edit
+	FileHash        string          `json:"file_hash"` // Version after the edit
+	Mtime           string          `json:"mtime"`
+}
+
+// RegisterMarkdownRelevelSection registers the markdown_relevel_section tool.
+func RegisterMarkdownRelevelSection(srv server.Server) {
+	srv.Tool(
+		"markdown_relevel_section",
+		"Promote or demote a section and all its subsections by shift levels (e.g. -1 turns an H3 subtree into H2s). Shifts that would move any heading outside H1-H6 are rejected; lines other than headings are kept byte-identical. Returns the section's tree after the edit. Requires expected_hash or expected_mtime from a prior read.",
+		handleRelevelSection,
+	)
+}
+
+// handleRelevelSection implements the markdown_relevel_section tool logic.
+func handleRelevelSection(
+	_ *server.Context,
+	args MarkdownRelevelSectionArgs,
+) (interface{}, error) {
+	if args.Shift == 0 {
+		return nil, fmt.Errorf("%w: shift must not be 0", ErrInvalidLevel)
+	}
+
+	doc, err := loadDocument(args.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(
+		doc.version,
+		args.ExpectedHash,
+		args.ExpectedMtime,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(doc.entries) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoEntries, args.FilePath)
+	}
+
+	entry, err := resolveSection(doc.entries, sectionAddress{
+		Heading:       args.SectionHeading,
+		Occurrence:    args.Occurrence,
+		MatchMode:     args.MatchMode,
+		CaseSensitive: args.CaseSensitive,
+		Strict:        args.Strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	releveled, err := doc.relevelRange(entry.Line, entry.End, args.Shift)
+	if err != nil {
+		return nil, err
+	}
+	lines := spliceLines(doc.lines, entry.Line, entry.End, releveled)
+	updated, err := doc.save("markdown_relevel_section", lines)
+	if err != nil {
+		return nil, err
+	}
+
+	section, ok := updated.sectionAt(entry.Line)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: no heading at line %d after the edit",
+			ErrSectionNotFound,
+			entry.Line,
+		)
+	}
+
+	subtree := ctags.Subtree(updated.entries, section)
+	return MarkdownRelevelSectionResponse{
+		SectionName:     section.Name,
+		Slug:            section.Slug,
+		HeadingPath:     section.HeadingPath(),
+		HeadingLevel:    fmt.Sprintf("H%d", section.Level),
+		HeadingKind:     section.Kind,
+		LevelShift:      args.Shift,
+		HeadingsChanged: len(ctags.Subtree(doc.entries, entry)),
+		StartLine:       section.Line,
+		EndLine:         section.End,
+		Tree:            ctags.BuildTreeJSON(subtree),
+		FileHash:        updated.version.Hash,
+		Mtime:           formatModTime(updated.version.ModTime),
+	}, nil
+}
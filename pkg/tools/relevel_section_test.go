@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// relevelSectionDoc is the document edited by the relevel tests.
+const relevelSectionDoc = "# Plan\n" +
+	"\n" +
+	"Setext Phase\n" +
+	"------------\n" +
+	"\n" +
+	"### Task 1.1 ###\n" +
+	"\n" +
+	"  Indented text, # not a heading.\n" +
+	"\n" +
+	"```\n" +
+	"## Not a heading\n" +
+	"```\n" +
+	"\n" +
+	"# Appendix\n"
+
+func TestHandleRelevelSection_Demote(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, relevelSectionDoc)
+	response := runTool[MarkdownRelevelSectionResponse](
+		t,
+		handleRelevelSection,
+		MarkdownRelevelSectionArgs{
+			FilePath:       filePath,
+			SectionHeading: "Plan",
+			Shift:          1,
+			ExpectedHash:   &hash,
+		},
+	)
+
+	// The setext H2 becomes an ATX H3; lines other than headings stay
+	expected := "## Plan\n" +
+		"\n" +
+		"### Setext Phase\n" +
+		"\n" +
+		"#### Task 1.1 ###\n" +
+		"\n" +
+		"  Indented text, # not a heading.\n" +
+		"\n" +
+		"```\n" +
+		"## Not a heading\n" +
+		"```\n" +
+		"\n" +
+		"# Appendix\n"
+	if got := readFixture(t, filePath); got != expected {
+		t.Errorf("Unexpected content:\n%s", got)
+	}
+
+	if response.HeadingLevel != "H2" || response.HeadingKind != "section" ||
+		response.HeadingsChanged != 3 || response.StartLine != 1 ||
+		response.EndLine != 12 || response.FileHash == "" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+
+	if response.Tree == nil || len(response.Tree.Children) != 1 {
+		t.Fatalf("Unexpected tree %+v", response.Tree)
+	}
+	plan := response.Tree.Children[0]
+	if plan.Name != "Plan" || plan.Level != "H2" || len(plan.Children) != 1 {
+		t.Fatalf("Unexpected subtree %+v", plan)
+	}
+	phase := plan.Children[0]
+	if phase.Level != "H3" || len(phase.Children) != 1 ||
+		phase.Children[0].Level != "H4" || phase.Children[0].StartLine != 5 {
+		t.Errorf("Unexpected subsection %+v", phase)
+	}
+}
+
+func TestHandleRelevelSection_Promote(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, relevelSectionDoc)
+	response := runTool[MarkdownRelevelSectionResponse](
+		t,
+		handleRelevelSection,
+		MarkdownRelevelSectionArgs{
+			FilePath:       filePath,
+			SectionHeading: "Setext Phase",
+			Shift:          -1,
+			ExpectedHash:   &hash,
+		},
+	)
+
+	// The setext heading keeps its form with a matching underline
+	expected := "# Plan\n" +
+		"\n" +
+		"Setext Phase\n" +
+		"============\n" +
+		"\n" +
+		"## Task 1.1 ###\n" +
+		"\n" +
+		"  Indented text, # not a heading.\n" +
+		"\n" +
+		"```\n" +
+		"## Not a heading\n" +
+		"```\n" +
+		"\n" +
+		"# Appendix\n"
+	if got := readFixture(t, filePath); got != expected {
+		t.Errorf("Unexpected content:\n%s", got)
+	}
+	if response.HeadingLevel != "H1" || response.HeadingKind != "chapter" ||
+		response.HeadingsChanged != 2 || response.LevelShift != -1 ||
+		response.HeadingPath[0] != "Setext Phase" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+}
+
+func TestHandleRelevelSection_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		heading  string
+		shift    int
+		hash     string
+		expected error
+	}{
+		{"zero shift", "Plan", 0, "", ErrInvalidLevel},
+		{"above H1", "Setext Phase", -2, "", ErrInvalidLevel},
+		{"below H6", "Plan", 4, "", ErrInvalidLevel},
+		{"missing section", "Missing", 1, "", ErrSectionNotFound},
+		{"stale version", "Plan", 1, "0000", ctags.ErrFileChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, relevelSectionDoc)
+			if tt.hash != "" {
+				hash = tt.hash
+			}
+			_, err := handleRelevelSection(nil, MarkdownRelevelSectionArgs{
+				FilePath:       filePath,
+				SectionHeading: tt.heading,
+				Shift:          tt.shift,
+				ExpectedHash:   &hash,
+			})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if got := readFixture(t, filePath); got != relevelSectionDoc {
+				t.Errorf("Rejected shift changed the file:\n%s", got)
+			}
+		})
+	}
+}
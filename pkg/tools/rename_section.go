@@ -0,0 +1,406 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// MarkdownRenameSectionArgs defines the input arguments.
+type MarkdownRenameSectionArgs struct {
+	FilePath       string   `json:"file_path"                description:"Path to markdown file"                                                                                                                                          required:"true"`
+	SectionHeading string   `json:"section_heading"          description:"Section to rename: heading text (without # symbols), a heading path separated by ' > ' or an anchor such as '#task-21-testing'"                                  required:"true"`
+	Occurrence     *int     `json:"occurrence,omitempty"     description:"Which match to use when several sections match (1=first). Default: 1"`
+	MatchMode      *string  `json:"match_mode,omitempty"     description:"How heading names are compared: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive  *bool    `json:"case_sensitive,omitempty" description:"Compare heading names case-sensitively. Default: false"`
+	Strict         *bool    `json:"strict,omitempty"         description:"Fail with a list of candidate sections instead of using the first match when the heading is ambiguous. Default: false"`
+	NewHeading     string   `json:"new_heading"              description:"New heading text, without # symbols. The level and form of the heading are kept"                                                                             required:"true"`
+	LinkDirectory  *string  `json:"link_directory,omitempty" description:"Also rewrite links to the old anchor in the markdown files of this directory (e.g. 'guide.md#old-slug'). Default: only links within file_path are rewritten"`
+	Include        []string `json:"include,omitempty"        description:"Globs selecting files in link_directory, as in markdown_workspace_tree. Default: ['*.md', '*.markdown']"`
+	Exclude        []string `json:"exclude,omitempty"        description:"Globs of files or directories in link_directory to skip"`
+	DryRun         *bool    `json:"dry_run,omitempty"        description:"Return a unified diff of the changes instead of writing them. Default: false"`
+	ExpectedHash   *string  `json:"expected_hash,omitempty"  description:"file_hash returned by a prior markdown_read_section or markdown_section_bounds. The edit fails if the file changed since. expected_hash or expected_mtime is required"`
+	ExpectedMtime  *string  `json:"expected_mtime,omitempty" description:"mtime returned by a prior markdown_read_section or markdown_section_bounds (RFC 3339). The edit fails if the file changed since"`
+}
+
+// RewrittenLink is a link whose anchor was changed by a rename.
+type RewrittenLink struct {
+	File      string `json:"file"` // As given, or relative to link_directory
+	Line      int    `json:"line"`
+	OldTarget string `json:"old_target"`
+	NewTarget string `json:"new_target"`
+}
+
+// MarkdownRenameSectionResponse defines the response structure.
+type MarkdownRenameSectionResponse struct {
+	SectionName string          `json:"section_name"`
+	Slug        string          `json:"slug"`
+	OldSlug     string          `json:"old_slug"`
+	HeadingPath []string        `json:"heading_path"`
+	StartLine   int             `json:"start_line"` // Bounds after the edit
+	EndLine     int             `json:"end_line"`
+	Links       []RewrittenLink `json:"links"`
+	Errors      []FileError     `json:"errors,omitempty"` // Unreadable linking files, left unchanged
+	DryRun      bool            `json:"dry_run"`
+	Diff        string          `json:"diff,omitempty"`      // Dry run only
+	FileHash    string          `json:"file_hash,omitempty"` // Version after the edit
+	Mtime       string          `json:"mtime,omitempty"`
+}
+
+// RegisterMarkdownRenameSection registers the markdown_rename_section tool.
+func RegisterMarkdownRenameSection(srv server.Server) {
+	srv.Tool(
+		"markdown_rename_section",
+		"Rename a section heading and rewrite the links to its old anchor (e.g. '[x](#old-slug)') in the same file and, with link_directory, in other markdown files. All files are written or none: if any of them changed since it was read, nothing is written. Returns every rewritten link; dry_run returns a unified diff instead of writing. Requires expected_hash or expected_mtime from a prior read.",
+		handleRenameSection,
+	)
+}
+
+// renamedFile is a file edited by a rename.
+type renamedFile struct {
+	name  string // As reported in the response
+	doc   *document
+	lines []string // Content after the rename
+}
+
+// handleRenameSection implements the markdown_rename_section tool logic.
+func handleRenameSection(
+	_ *server.Context,
+	args MarkdownRenameSectionArgs,
+) (interface{}, error) {
+	newHeading, err := headingText(args.NewHeading, "new_heading")
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := loadDocument(args.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(
+		doc.version,
+		args.ExpectedHash,
+		args.ExpectedMtime,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(doc.entries) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoEntries, args.FilePath)
+	}
+
+	entry, err := resolveSection(doc.entries, sectionAddress{
+		Heading:       args.SectionHeading,
+		Occurrence:    args.Occurrence,
+		MatchMode:     args.MatchMode,
+		CaseSensitive: args.CaseSensitive,
+		Strict:        args.Strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lines, renamed := doc.renameHeading(entry, newHeading)
+	slugs := changedSlugs(doc.entries, renamed.entries)
+
+	response := MarkdownRenameSectionResponse{
+		SectionName: "",
+		Slug:        "",
+		OldSlug:     entry.Slug,
+		HeadingPath: nil,
+		StartLine:   0,
+		EndLine:     0,
+		Links:       []RewrittenLink{},
+		Errors:      nil,
+		DryRun:      args.DryRun != nil && *args.DryRun,
+		Diff:        "",
+		FileHash:    "",
+		Mtime:       "",
+	}
+
+	target, err := filepath.Abs(args.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve file path: %w", err)
+	}
+	lines, response.Links = rewriteAnchorLinks(
+		args.FilePath,
+		lines,
+		target,
+		target,
+		slugs,
+	)
+	files := []renamedFile{{name: args.FilePath, doc: doc, lines: lines}}
+
+	if args.LinkDirectory != nil {
+		linking, links, errs, err := rewriteLinkingFiles(
+			*args.LinkDirectory,
+			args.Include,
+			args.Exclude,
+			target,
+			slugs,
+		)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, linking...)
+		response.Links = append(response.Links, links...)
+		response.Errors = errs
+	}
+
+	section, ok := renamed.sectionAt(entry.Line)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: no heading at line %d after the edit",
+			ErrSectionNotFound,
+			entry.Line,
+		)
+	}
+	response.SectionName = section.Name
+	response.Slug = section.Slug
+	response.HeadingPath = section.HeadingPath()
+	response.StartLine = section.Line
+	response.EndLine = section.End
+
+	if response.DryRun {
+		var diff strings.Builder
+		for _, file := range files {
+			diff.WriteString(unifiedDiff(
+				file.name,
+				file.doc.lines,
+				diffLines(file.doc.lines, file.lines),
+			))
+		}
+		response.Diff = diff.String()
+		return response, nil
+	}
+
+	updated, err := saveRenamedFiles(files)
+	if err != nil {
+		return nil, err
+	}
+	response.FileHash = updated[0].version.Hash
+	response.Mtime = formatModTime(updated[0].version.ModTime)
+
+	return response, nil
+}
+
+// saveRenamedFiles writes all files edited by a rename or none of them and
+// returns the edited documents. Every file must still have the version it
+// was read with before the first one is written; if a write fails anyway,
+// the files written before it are restored. The edits are journaled only
+// once all files are written.
+//
+// Errors include: ctags.ErrFileChanged.
+func saveRenamedFiles(files []renamedFile) ([]*document, error) {
+	for _, file := range files {
+		_, version, err := ctags.ReadFileVersion(file.doc.filePath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w; nothing was written", file.name, err)
+		}
+		if version.Hash != file.doc.version.Hash {
+			return nil, fmt.Errorf(
+				"%w: %s (expected hash %s, found %s); nothing was written",
+				ctags.ErrFileChanged,
+				file.name,
+				file.doc.version.Hash,
+				version.Hash,
+			)
+		}
+	}
+
+	updated := make([]*document, 0, len(files))
+	for _, file := range files {
+		doc, err := file.doc.write(file.lines)
+		if err != nil {
+			return nil, errors.Join(
+				fmt.Errorf(
+					"%w; files written before it were restored: %d",
+					err,
+					len(updated),
+				),
+				restoreRenamedFiles(files[:len(updated)], updated),
+			)
+		}
+		updated = append(updated, doc)
+	}
+
+	for i, file := range files {
+		getJournal().record(file.doc, updated[i], "markdown_rename_section")
+	}
+	return updated, nil
+}
+
+// restoreRenamedFiles writes the content files had before the rename back
+// over the written documents, last written first.
+func restoreRenamedFiles(files []renamedFile, written []*document) error {
+	var errs []error
+	for i := len(written) - 1; i >= 0; i-- {
+		if _, err := written[i].write(files[i].doc.lines); err != nil {
+			errs = append(errs, fmt.Errorf(
+				"failed to restore %s: %w",
+				files[i].name,
+				err,
+			))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// renameHeading returns the lines of d with the heading of entry changed to
+// text, and the document they form. ATX headings keep their marker and
+// closing sequence; the text of a setext heading becomes a single line
+// above its underline.
+func (d *document) renameHeading(
+	entry *ctags.TagEntry,
+	text string,
+) ([]string, *document) {
+	heading := d.headings[entry.Line]
+
+	var replacement string
+	to := entry.Line
+	raw := d.lines[heading.Index]
+	if heading.UnderlineIndex < 0 {
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		marker := indent + entry.Level
+		at := marker + strings.Index(raw[marker:], heading.Text)
+		replacement = raw[:at] + text + raw[at+len(heading.Text):]
+	} else {
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		replacement = raw[:indent] + text
+		to = heading.UnderlineIndex
+	}
+
+	lines := spliceLines(d.lines, entry.Line, to, []string{replacement})
+	return lines, newDocument(d.filePath, d.render(lines), d.version)
+}
+
+// changedSlugs maps the slugs of before to the slugs of the same headings
+// in after where they differ. Renaming one heading can also change the
+// numbered slugs of headings with the same text.
+func changedSlugs(before, after []*ctags.TagEntry) map[string]string {
+	slugs := make(map[string]string)
+	for i := range min(len(before), len(after)) {
+		if before[i].Slug != after[i].Slug {
+			slugs[before[i].Slug] = after[i].Slug
+		}
+	}
+	return slugs
+}
+
+// rewriteAnchorLinks returns lines with the anchors of links to the file
+// target replaced according to slugs, and the rewritten links. Links are
+// resolved relative to the file at source; links without a path refer to
+// source itself.
+func rewriteAnchorLinks(
+	name string,
+	lines []string,
+	source string,
+	target string,
+	slugs map[string]string,
+) ([]string, []RewrittenLink) {
+	rewritten := make([]RewrittenLink, 0)
+	if len(slugs) == 0 {
+		return lines, rewritten
+	}
+
+	result := lines
+	links := ctags.ScanAnchorLinks(lines)
+	for i := len(links) - 1; i >= 0; i-- { // Keeps earlier offsets valid
+		link := links[i]
+		slug, ok := slugs[ctags.AnchorKey(link.Anchor)]
+		if !ok || linkTarget(source, link.Path) != target {
+			continue
+		}
+
+		if len(rewritten) == 0 {
+			result = append([]string(nil), lines...)
+		}
+		line := result[link.Index]
+		result[link.Index] = line[:link.Start] + slug + line[link.End:]
+		rewritten = append(rewritten, RewrittenLink{
+			File:      name,
+			Line:      link.Index + 1,
+			OldTarget: link.Path + "#" + link.Anchor,
+			NewTarget: link.Path + "#" + slug,
+		})
+	}
+
+	// Report in document order
+	for i, j := 0, len(rewritten)-1; i < j; i, j = i+1, j-1 {
+		rewritten[i], rewritten[j] = rewritten[j], rewritten[i]
+	}
+	return result, rewritten
+}
+
+// linkTarget returns the absolute path of the file a link destination path
+// in the file at source refers to.
+func linkTarget(source, path string) string {
+	if path == "" {
+		return source
+	}
+	if unescaped, err := url.PathUnescape(path); err == nil {
+		path = unescaped
+	}
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(filepath.Dir(source), filepath.FromSlash(path))
+}
+
+// rewriteLinkingFiles rewrites the links to target in the markdown files of
+// directory other than target. It returns the files with rewritten links,
+// the links and the files that could not be read.
+func rewriteLinkingFiles(
+	directory string,
+	include []string,
+	exclude []string,
+	target string,
+	slugs map[string]string,
+) ([]renamedFile, []RewrittenLink, []FileError, error) {
+	ws, err := resolveWorkspace(directory, include, exclude)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var files []renamedFile
+	var links []RewrittenLink
+	var errs []FileError
+	for i, filePath := range ws.FilePaths {
+		if filePath == target {
+			continue
+		}
+
+		doc, err := loadDocument(filePath)
+		if err != nil {
+			errs = append(errs, FileError{
+				Path:  ws.RelPaths[i],
+				Error: err.Error(),
+			})
+			continue
+		}
+
+		lines, rewritten := rewriteAnchorLinks(
+			ws.RelPaths[i],
+			doc.lines,
+			filePath,
+			target,
+			slugs,
+		)
+		if len(rewritten) == 0 {
+			continue
+		}
+		files = append(files, renamedFile{
+			name:  ws.RelPaths[i],
+			doc:   doc,
+			lines: lines,
+		})
+		links = append(links, rewritten...)
+	}
+
+	return files, links, errs, nil
+}
@@ -0,0 +1,232 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// renameSectionDoc is the document edited by the rename tests.
+const renameSectionDoc = "# Guide\n" +
+	"\n" +
+	"See [setup](#setup) and [again](#Setup \"title\").\n" +
+	"Code `[x](#setup)` stays.\n" +
+	"\n" +
+	"## Setup ##\n" +
+	"\n" +
+	"## Setup\n" +
+	"\n" +
+	"Second one is [here](#setup-1).\n"
+
+func TestHandleRenameSection_SameFileLinks(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, renameSectionDoc)
+	response := runTool[MarkdownRenameSectionResponse](
+		t,
+		handleRenameSection,
+		MarkdownRenameSectionArgs{
+			FilePath:       filePath,
+			SectionHeading: "#setup",
+			NewHeading:     "Installation",
+			ExpectedHash:   &hash,
+		},
+	)
+
+	// The second "Setup" loses its numbered slug
+	expected := "# Guide\n" +
+		"\n" +
+		"See [setup](#installation) and [again](#installation \"title\").\n" +
+		"Code `[x](#setup)` stays.\n" +
+		"\n" +
+		"## Installation ##\n" +
+		"\n" +
+		"## Setup\n" +
+		"\n" +
+		"Second one is [here](#setup).\n"
+	if got := readFixture(t, filePath); got != expected {
+		t.Errorf("Unexpected content:\n%s", got)
+	}
+
+	if response.OldSlug != "setup" || response.Slug != "installation" ||
+		response.StartLine != 6 || response.FileHash == "" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+	if len(response.Links) != 3 {
+		t.Fatalf("Expected 3 rewritten links, got %+v", response.Links)
+	}
+	if link := response.Links[2]; link.Line != 10 ||
+		link.OldTarget != "#setup-1" || link.NewTarget != "#setup" {
+		t.Errorf("Unexpected link %+v", link)
+	}
+}
+
+func TestHandleRenameSection_CrossFileDryRun(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{
+		"guide.md":       "# Guide\n\nSetext Title\n============\n",
+		"index.md":       "[a](guide.md#setext-title) [b](#setext-title)\n",
+		"sub/deep.md":    "[c](../guide.md#setext-title)\n",
+		"sub/unrelat.md": "[d](other.md#setext-title)\n",
+	})
+	filePath := filepath.Join(root, "guide.md")
+	_, version, err := ctags.ReadFileVersion(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read version: %v", err)
+	}
+
+	args := MarkdownRenameSectionArgs{
+		FilePath:       filePath,
+		SectionHeading: "Setext Title",
+		NewHeading:     "New Title",
+		LinkDirectory:  &root,
+		DryRun:         ptr(true),
+		ExpectedHash:   &version.Hash,
+	}
+	response := runTool[MarkdownRenameSectionResponse](
+		t,
+		handleRenameSection,
+		args,
+	)
+
+	if len(response.Links) != 2 || response.Links[0].File != "index.md" ||
+		response.Links[1].File != "sub/deep.md" ||
+		response.Links[1].NewTarget != "../guide.md#new-title" {
+		t.Errorf("Unexpected links %+v", response.Links)
+	}
+	for _, want := range []string{
+		"-Setext Title\n+New Title\n",
+		"--- a/index.md\n",
+		"-[a](guide.md#setext-title) [b](#setext-title)\n" +
+			"+[a](guide.md#new-title) [b](#setext-title)\n",
+		"@@ -1,1 +1,1 @@\n-[c](../guide.md#setext-title)\n",
+	} {
+		if !strings.Contains(response.Diff, want) {
+			t.Errorf("Diff misses %q:\n%s", want, response.Diff)
+		}
+	}
+	if response.FileHash != "" {
+		t.Errorf("Dry run reported a new version %s", response.FileHash)
+	}
+
+	// Nothing was written
+	if got := readFixture(t, filePath); got != "# Guide\n\nSetext Title\n"+
+		"============\n" {
+		t.Errorf("Dry run changed the file:\n%s", got)
+	}
+
+	args.DryRun = nil
+	runTool[MarkdownRenameSectionResponse](t, handleRenameSection, args)
+	if got := readFixture(t, filePath); got != "# Guide\n\nNew Title\n"+
+		"============\n" {
+		t.Errorf("Unexpected content:\n%s", got)
+	}
+	if got := readFixture(t, filepath.Join(root, "sub", "deep.md")); got !=
+		"[c](../guide.md#new-title)\n" {
+		t.Errorf("Unexpected linking file:\n%s", got)
+	}
+}
+
+func TestHandleRenameSection_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		heading    string
+		newHeading string
+		hash       string
+		expected   error
+	}{
+		{"blank heading", "Guide", " ", "", ErrInvalidContent},
+		{"missing section", "Missing", "New", "", ErrSectionNotFound},
+		{"stale version", "Guide", "New", "0000", ctags.ErrFileChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, renameSectionDoc)
+			if tt.hash != "" {
+				hash = tt.hash
+			}
+			_, err := handleRenameSection(nil, MarkdownRenameSectionArgs{
+				FilePath:       filePath,
+				SectionHeading: tt.heading,
+				NewHeading:     tt.newHeading,
+				ExpectedHash:   &hash,
+			})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if got := readFixture(t, filePath); got != renameSectionDoc {
+				t.Errorf("Rejected rename changed the file:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestSaveRenamedFiles_AllOrNothing(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{
+		"guide.md": "# Old\n",
+		"index.md": "[a](guide.md#old)\n",
+	})
+	guide, err := loadDocument(filepath.Join(root, "guide.md"))
+	if err != nil {
+		t.Fatalf("loadDocument failed: %v", err)
+	}
+	index, err := loadDocument(filepath.Join(root, "index.md"))
+	if err != nil {
+		t.Fatalf("loadDocument failed: %v", err)
+	}
+	renamed := renamedFile{
+		name:  "guide.md",
+		doc:   guide,
+		lines: []string{"# New"},
+	}
+
+	// A linking file changed since it was read: nothing is written
+	if err := os.WriteFile(
+		index.filePath,
+		[]byte("[b](guide.md#old)\n"),
+		0o644,
+	); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	_, err = saveRenamedFiles([]renamedFile{renamed, {
+		name:  "index.md",
+		doc:   index,
+		lines: []string{"[a](guide.md#new)"},
+	}})
+	if !errors.Is(err, ctags.ErrFileChanged) {
+		t.Errorf("Expected ErrFileChanged, got %v", err)
+	}
+	if got := readFixture(t, guide.filePath); got != "# Old\n" {
+		t.Errorf("Renamed file was written:\n%s", got)
+	}
+
+	// A write that fails after the first one restores the first file: the
+	// second write of the same file finds the content of the first
+	_, err = saveRenamedFiles([]renamedFile{renamed, {
+		name:  "guide.md",
+		doc:   guide,
+		lines: []string{"# Other"},
+	}})
+	if !errors.Is(err, ctags.ErrFileChanged) ||
+		!strings.Contains(err.Error(), "were restored: 1") {
+		t.Errorf("Expected ErrFileChanged with restore, got %v", err)
+	}
+	if got := readFixture(t, guide.filePath); got != "# Old\n" {
+		t.Errorf("Renamed file was not restored:\n%s", got)
+	}
+	if entries := getJournal().entries(guide.filePath); len(entries) != 0 {
+		t.Errorf("Failed rename was journaled: %+v", entries)
+	}
+}
@@ -0,0 +1,247 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// MarkdownReplaceSectionArgs defines the input arguments.
+type MarkdownReplaceSectionArgs struct {
+	FilePath        string  `json:"file_path"                  description:"Path to markdown file"                                                                                                                                                 required:"true"`
+	SectionHeading  string  `json:"section_heading"            description:"Heading text to find (without # symbols), a heading path separated by ' > ' or an anchor such as '#task-21-testing'"                                                   required:"true"`
+	Occurrence      *int    `json:"occurrence,omitempty"       description:"Which match to use when several sections match (1=first). Default: 1"`
+	MatchMode       *string `json:"match_mode,omitempty"       description:"How heading names are compared: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive   *bool   `json:"case_sensitive,omitempty"   description:"Compare heading names case-sensitively. Default: false"`
+	Strict          *bool   `json:"strict,omitempty"           description:"Fail with a list of candidate sections instead of using the first match when the heading is ambiguous. Default: false"`
+	Content         string  `json:"content"                    description:"New content. Without the heading line unless keep_heading is false, in which case it must start with a heading of the section's level"                                 required:"true"`
+	KeepHeading     *bool   `json:"keep_heading,omitempty"     description:"Keep the existing heading line and replace only what follows it. Default: true"`
+	KeepSubsections *bool   `json:"keep_subsections,omitempty" description:"Keep the subsections and replace only the text before the first one. false replaces the whole section including its subsections. Default: true"`
+	ExpectedHash    *string `json:"expected_hash,omitempty"    description:"file_hash returned by a prior markdown_read_section or markdown_section_bounds. The edit fails if the file changed since. expected_hash or expected_mtime is required"`
+	ExpectedMtime   *string `json:"expected_mtime,omitempty"   description:"mtime returned by a prior markdown_read_section or markdown_section_bounds (RFC 3339). The edit fails if the file changed since"`
+}
+
+// MarkdownReplaceSectionResponse defines the response structure.
+type MarkdownReplaceSectionResponse struct {
+	SectionName  string   `json:"section_name"`
+	Slug         string   `json:"slug"`
+	HeadingPath  []string `json:"heading_path"`
+	StartLine    int      `json:"start_line"` // Bounds after the edit
+	EndLine      int      `json:"end_line"`
+	LinesRemoved int      `json:"lines_removed"`
+	LinesAdded   int      `json:"lines_added"`
+	FileHash     string   `json:"file_hash"` // Version after the edit
+	Mtime        string   `json:"mtime"`
+}
+
+// RegisterMarkdownReplaceSection registers the markdown_replace_section tool.
+func RegisterMarkdownReplaceSection(srv server.Server) {
+	srv.Tool(
+		"markdown_replace_section",
+		"Replace the content of a section, keeping its heading line and subsections by default. Requires expected_hash or expected_mtime from a prior markdown_read_section so that concurrent edits are detected. The file is replaced atomically; the response carries the new bounds and file_hash for follow-up edits.",
+		handleReplaceSection,
+	)
+}
+
+// handleReplaceSection implements the markdown_replace_section tool logic.
+func handleReplaceSection(
+	_ *server.Context,
+	args MarkdownReplaceSectionArgs,
+) (interface{}, error) {
+	doc, err := loadDocument(args.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(
+		doc.version,
+		args.ExpectedHash,
+		args.ExpectedMtime,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(doc.entries) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoEntries, args.FilePath)
+	}
+
+	entry, err := resolveSection(doc.entries, sectionAddress{
+		Heading:       args.SectionHeading,
+		Occurrence:    args.Occurrence,
+		MatchMode:     args.MatchMode,
+		CaseSensitive: args.CaseSensitive,
+		Strict:        args.Strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keepHeading := args.KeepHeading == nil || *args.KeepHeading
+	keepSubsections := args.KeepSubsections == nil || *args.KeepSubsections
+
+	body := contentLines(args.Content)
+	if err := validateReplacement(entry, body, keepHeading); err != nil {
+		return nil, err
+	}
+
+	lines, line, from, to := replaceSection(
+		doc,
+		entry,
+		body,
+		keepHeading,
+		keepSubsections,
+	)
+	updated, err := doc.save("markdown_replace_section", lines)
+	if err != nil {
+		return nil, err
+	}
+
+	section, ok := updated.sectionAt(line)
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: no heading at line %d after the edit",
+			ErrSectionNotFound,
+			line,
+		)
+	}
+
+	return MarkdownReplaceSectionResponse{
+		SectionName:  section.Name,
+		Slug:         section.Slug,
+		HeadingPath:  section.HeadingPath(),
+		StartLine:    section.Line,
+		EndLine:      section.End,
+		LinesRemoved: to - from + 1,
+		LinesAdded:   len(lines) - len(doc.lines) + to - from + 1,
+		FileHash:     updated.version.Hash,
+		Mtime:        formatModTime(updated.version.ModTime),
+	}, nil
+}
+
+// replaceSection returns the lines of doc with the content of entry
+// replaced by body, the line of the section's heading afterwards and the
+// replaced range. Body must have been checked by validateReplacement.
+func replaceSection(
+	doc *document,
+	entry *ctags.TagEntry,
+	body []string,
+	keepHeading bool,
+	keepSubsections bool,
+) ([]string, int, int, int) {
+	// Replaced range: after the heading or from it, up to the first
+	// subsection or the end of the section
+	from, to := entry.Line, entry.End
+	if keepHeading {
+		from = doc.headingEnd(entry) + 1
+	}
+	if keepSubsections {
+		to = doc.bodyEnd(entry)
+	}
+
+	// Content is separated from its neighbours by one blank line, like an
+	// inserted section; a kept heading without content is still separated
+	// from the heading that follows
+	lines := spliceLines(doc.lines, from, to, nil)
+	line := entry.Line
+	switch {
+	case len(body) > 0 && keepHeading:
+		lines, _ = insertBlock(lines, from, body)
+	case len(body) > 0:
+		lines, line = insertBlock(lines, from, body)
+	case keepHeading && from <= len(lines):
+		lines = spliceLines(lines, from, from-1, []string{""})
+	}
+
+	return lines, line, from, to
+}
+
+// validateReplacement checks that replacement content keeps the section
+// structure: below a kept heading, content headings must be subsections;
+// without it, content must start with a heading of the section's level and
+// contain no other heading at that level or above. Code blocks and HTML
+// comments must be closed.
+func validateReplacement(
+	entry *ctags.TagEntry,
+	body []string,
+	keepHeading bool,
+) error {
+	if err := validateClosedBlocks(body); err != nil {
+		return err
+	}
+	headings := ctags.ScanHeadings(body)
+
+	if !keepHeading {
+		if len(headings) == 0 || headings[0].Index != 0 {
+			return fmt.Errorf(
+				"%w: content must start with the section heading when "+
+					"keep_heading is false",
+				ErrInvalidContent,
+			)
+		}
+		if headings[0].Level != entry.Level {
+			return fmt.Errorf(
+				"%w: heading '%s' is H%d, section '%s' is H%d; use "+
+					"markdown_relevel_section to change levels",
+				ErrInvalidContent,
+				headings[0].Text,
+				headings[0].Level,
+				entry.Name,
+				entry.Level,
+			)
+		}
+		headings = headings[1:]
+	}
+
+	return validateHeadingLevels(headings, entry.Name, entry.Level)
+}
+
+// validateSubheadings checks that content placed in the body of a section
+// with the given name and level closes its code blocks and HTML comments
+// and that its headings are subsections of the section.
+func validateSubheadings(content []string, name string, level int) error {
+	if err := validateClosedBlocks(content); err != nil {
+		return err
+	}
+	return validateHeadingLevels(ctags.ScanHeadings(content), name, level)
+}
+
+// validateHeadingLevels checks that headings placed in the body of a section
+// with the given name and level are deeper than the section.
+func validateHeadingLevels(
+	headings []ctags.Heading,
+	name string,
+	level int,
+) error {
+	for _, heading := range headings {
+		if heading.Level <= level {
+			return fmt.Errorf(
+				"%w: heading '%s' (H%d) would end section '%s' (H%d); "+
+					"content headings must be deeper",
+				ErrInvalidContent,
+				heading.Text,
+				heading.Level,
+				name,
+				level,
+			)
+		}
+	}
+
+	return nil
+}
+
+// validateClosedBlocks checks that content closes every fenced code block
+// and HTML comment it opens. An open block would run to the end of the file
+// and hide all headings after the content.
+func validateClosedBlocks(content []string) error {
+	if index, open := ctags.UnclosedBlock(content); open {
+		return fmt.Errorf(
+			"%w: content line %d (%q) opens a code block or HTML comment "+
+				"that is never closed; it would hide every heading after it",
+			ErrInvalidContent,
+			index+1,
+			content[index],
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// replaceSectionDoc is the document edited by the replace tests.
+const replaceSectionDoc = "# Plan\n" +
+	"\n" +
+	"Intro.\n" +
+	"\n" +
+	"## Phase 1\n" +
+	"\n" +
+	"Old text.\n" +
+	"More old text.\n" +
+	"\n" +
+	"### Task 1.1\n" +
+	"\n" +
+	"Task text.\n" +
+	"\n" +
+	"## Phase 2\n" +
+	"\n" +
+	"Later.\n"
+
+func TestHandleReplaceSection_KeepsHeadingAndSubsections(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, replaceSectionDoc)
+	response := runTool[MarkdownReplaceSectionResponse](
+		t,
+		handleReplaceSection,
+		MarkdownReplaceSectionArgs{
+			FilePath:       filePath,
+			SectionHeading: "Phase 1",
+			Content:        "\n\nNew text.\n\n",
+			ExpectedHash:   &hash,
+		},
+	)
+
+	expected := "# Plan\n\nIntro.\n\n" +
+		"## Phase 1\n\nNew text.\n\n" +
+		"### Task 1.1\n\nTask text.\n\n" +
+		"## Phase 2\n\nLater.\n"
+	if got := readFixture(t, filePath); got != expected {
+		t.Errorf("Unexpected content:\n%s", got)
+	}
+
+	if response.StartLine != 5 || response.EndLine != 12 ||
+		response.SectionName != "Phase 1" {
+		t.Errorf("Unexpected bounds %+v", response)
+	}
+	if response.LinesRemoved != 4 || response.LinesAdded != 3 {
+		t.Errorf("Expected 4 lines removed and 3 added, got %d and %d",
+			response.LinesRemoved, response.LinesAdded)
+	}
+
+	_, version, err := ctags.ReadFileVersion(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read version: %v", err)
+	}
+	if response.FileHash != version.Hash ||
+		response.Mtime != formatModTime(version.ModTime) {
+		t.Errorf("Response version %s/%s does not match the file",
+			response.FileHash, response.Mtime)
+	}
+}
+
+func TestHandleReplaceSection_WholeSection(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, replaceSectionDoc)
+	response := runTool[MarkdownReplaceSectionResponse](
+		t,
+		handleReplaceSection,
+		MarkdownReplaceSectionArgs{
+			FilePath:        filePath,
+			SectionHeading:  "Phase 1",
+			Content:         "## Phase One\nRewritten.\n### Task A\nDone.",
+			KeepHeading:     ptr(false),
+			KeepSubsections: ptr(false),
+			ExpectedHash:    &hash,
+		},
+	)
+
+	expected := "# Plan\n\nIntro.\n\n" +
+		"## Phase One\nRewritten.\n### Task A\nDone.\n\n" +
+		"## Phase 2\n\nLater.\n"
+	if got := readFixture(t, filePath); got != expected {
+		t.Errorf("Unexpected content:\n%s", got)
+	}
+	if response.SectionName != "Phase One" || response.Slug != "phase-one" ||
+		response.EndLine != 9 {
+		t.Errorf("Unexpected section %+v", response)
+	}
+}
+
+func TestHandleReplaceSection_EmptyContent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		keepSubsections bool
+		expected        string
+	}{
+		{
+			name:            "whole body",
+			keepSubsections: false,
+			expected:        "# Plan\n\nIntro.\n\n## Phase 1\n\n## Phase 2\n\nLater.\n",
+		},
+		{
+			name:            "own body",
+			keepSubsections: true,
+			expected: "# Plan\n\nIntro.\n\n## Phase 1\n\n" +
+				"### Task 1.1\n\nTask text.\n\n## Phase 2\n\nLater.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, replaceSectionDoc)
+			response := runTool[MarkdownReplaceSectionResponse](
+				t,
+				handleReplaceSection,
+				MarkdownReplaceSectionArgs{
+					FilePath:        filePath,
+					SectionHeading:  "Phase 1",
+					KeepSubsections: &tt.keepSubsections,
+					ExpectedHash:    &hash,
+				},
+			)
+
+			if got := readFixture(t, filePath); got != tt.expected {
+				t.Errorf("Unexpected content:\n%s", got)
+			}
+			if response.LinesAdded != 1 {
+				t.Errorf("Unexpected lines added %d", response.LinesAdded)
+			}
+		})
+	}
+}
+
+func TestHandleReplaceSection_LastSectionAndLineEndings(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, "# A\r\nold\r\n## B\r\nold")
+	runTool[MarkdownReplaceSectionResponse](
+		t,
+		handleReplaceSection,
+		MarkdownReplaceSectionArgs{
+			FilePath:       filePath,
+			SectionHeading: "B",
+			Content:        "new",
+			ExpectedHash:   &hash,
+		},
+	)
+
+	if got := readFixture(t, filePath); got != "# A\r\nold\r\n## B\r\n\r\nnew" {
+		t.Errorf("Unexpected content %q", got)
+	}
+}
+
+func TestHandleReplaceSection_Conflicts(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, replaceSectionDoc)
+	args := MarkdownReplaceSectionArgs{
+		FilePath:       filePath,
+		SectionHeading: "Phase 1",
+		Content:        "x",
+	}
+
+	// A version is required
+	if _, err := handleReplaceSection(nil, args); !errors.Is(
+		err,
+		ErrMissingVersion,
+	) {
+		t.Errorf("Expected ErrMissingVersion, got %v", err)
+	}
+
+	// The file changed since it was read
+	if err := os.WriteFile(
+		filePath,
+		[]byte(replaceSectionDoc+"\nEdited elsewhere.\n"),
+		0o644,
+	); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	args.ExpectedHash = &hash
+	if _, err := handleReplaceSection(nil, args); !errors.Is(
+		err,
+		ctags.ErrFileChanged,
+	) {
+		t.Errorf("Expected ErrFileChanged, got %v", err)
+	}
+
+	args.ExpectedHash = nil
+	args.ExpectedMtime = ptr("2001-02-03T04:05:06Z")
+	if _, err := handleReplaceSection(nil, args); !errors.Is(
+		err,
+		ctags.ErrFileChanged,
+	) {
+		t.Errorf("Expected ErrFileChanged for a stale mtime, got %v", err)
+	}
+
+	if got := readFixture(t, filePath); got != replaceSectionDoc+
+		"\nEdited elsewhere.\n" {
+		t.Errorf("Rejected edits must not change the file:\n%s", got)
+	}
+}
+
+func TestHandleReplaceSection_ExpectedMtime(t *testing.T) {
+	t.Parallel()
+
+	filePath, _ := writeFixture(t, replaceSectionDoc)
+	_, version, err := ctags.ReadFileVersion(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read version: %v", err)
+	}
+
+	runTool[MarkdownReplaceSectionResponse](
+		t,
+		handleReplaceSection,
+		MarkdownReplaceSectionArgs{
+			FilePath:       filePath,
+			SectionHeading: "Phase 2",
+			Content:        "Sooner.",
+			ExpectedMtime:  ptr(formatModTime(version.ModTime)),
+		},
+	)
+
+	if got := readFixture(t, filePath); got[len(got)-8:] != "Sooner.\n" {
+		t.Errorf("Unexpected content:\n%s", got)
+	}
+}
+
+func TestHandleReplaceSection_InvalidContent(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		heading     string
+		content     string
+		keepHeading *bool
+		expected    error
+	}{
+		{
+			name:     "heading not deeper",
+			heading:  "Phase 1",
+			content:  "## Phase 3\nx",
+			expected: ErrInvalidContent,
+		},
+		{
+			name:        "replaced heading changes level",
+			heading:     "Phase 1",
+			content:     "### Phase 1\nx",
+			keepHeading: ptr(false),
+			expected:    ErrInvalidContent,
+		},
+		{
+			name:     "open fence",
+			heading:  "Phase 1",
+			content:  "```\nunterminated",
+			expected: ErrInvalidContent,
+		},
+		{
+			name:     "open comment",
+			heading:  "Phase 1",
+			content:  "Text.\n\n<!-- draft\nnotes",
+			expected: ErrInvalidContent,
+		},
+		{
+			name:     "fence closed by shorter fence",
+			heading:  "Phase 1",
+			content:  "````\ncode\n```",
+			expected: ErrInvalidContent,
+		},
+		{
+			name:     "missing section",
+			heading:  "Missing",
+			content:  "x",
+			expected: ErrSectionNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath, hash := writeFixture(t, replaceSectionDoc)
+			_, err := handleReplaceSection(nil, MarkdownReplaceSectionArgs{
+				FilePath:       filePath,
+				SectionHeading: tt.heading,
+				Content:        tt.content,
+				KeepHeading:    tt.keepHeading,
+				ExpectedHash:   &hash,
+			})
+			if !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+			if got := readFixture(t, filePath); got != replaceSectionDoc {
+				t.Errorf("Rejected replacement changed the file:\n%s", got)
+			}
+		})
+	}
+}
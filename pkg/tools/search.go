@@ -0,0 +1,349 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+const (
+	// defaultSearchContext is the default number of snippet lines around a
+	// hit.
+	defaultSearchContext = 2
+
+	// maxSearchContext bounds the snippet window.
+	maxSearchContext = 50
+
+	// defaultSearchLimit is the default maximum number of returned hits.
+	defaultSearchLimit = 50
+)
+
+// Hit orderings for markdown_search.
+const (
+	rankByPosition = "position"
+	rankBySection  = "section"
+)
+
+// MarkdownSearchArgs defines the input arguments.
+type MarkdownSearchArgs struct {
+	Query         string   `json:"query"                    description:"Text to find, or an RE2 regular expression when regex is true"                                                                                                       required:"true"`
+	FilePath      *string  `json:"file_path,omitempty"      description:"Markdown file to search. Give either file_path or directory"`
+	Directory     *string  `json:"directory,omitempty"      description:"Directory whose markdown files are searched. Give either file_path or directory"`
+	Include       []string `json:"include,omitempty"        description:"Globs selecting files in directory, as in markdown_workspace_tree. Default: ['*.md', '*.markdown']"`
+	Exclude       []string `json:"exclude,omitempty"        description:"Globs of files or directories in directory to skip"`
+	Regex         *bool    `json:"regex,omitempty"          description:"Interpret query as an RE2 regular expression. Default: false (literal text)"`
+	CaseSensitive *bool    `json:"case_sensitive,omitempty" description:"Match case-sensitively. Default: false"`
+	ContextLines  *int     `json:"context_lines,omitempty"  description:"Lines of context before and after each hit in the snippet (0-50). Default: 2"`
+	RankBy        *string  `json:"rank_by,omitempty"        description:"Hit order: 'position' (file, then line) or 'section' (hits in the smallest enclosing sections first, so the most specific section can be read). Default: 'position'"`
+	MaxResults    *int     `json:"max_results,omitempty"    description:"Maximum number of hits returned. Default: 50"`
+}
+
+// SearchSection is the section enclosing a search hit.
+type SearchSection struct {
+	Name        string   `json:"name"`
+	Slug        string   `json:"slug"`
+	Level       string   `json:"level"`
+	HeadingPath []string `json:"heading_path"`
+	StartLine   int      `json:"start_line"`
+	EndLine     int      `json:"end_line"`
+	Lines       int      `json:"lines"` // Section length, the ranking key
+}
+
+// SearchHit is a line matching a markdown_search query.
+type SearchHit struct {
+	File         string         `json:"file"`   // As given, or relative to directory
+	Line         int            `json:"line"`   // 1-based line number
+	Column       int            `json:"column"` // 1-based byte offset of the first match
+	Text         string         `json:"text"`   // The matching line
+	Snippet      string         `json:"snippet"`
+	SnippetStart int            `json:"snippet_start"`
+	SnippetEnd   int            `json:"snippet_end"`
+	Section      *SearchSection `json:"section"` // Nil before the first heading
+}
+
+// MarkdownSearchResponse defines the response structure.
+type MarkdownSearchResponse struct {
+	Hits         []SearchHit `json:"hits"`
+	TotalHits    int         `json:"total_hits"` // Hits found before max_results
+	Truncated    bool        `json:"truncated"`
+	FilesScanned int         `json:"files_scanned"`
+	RankBy       string      `json:"rank_by"`
+	Errors       []FileError `json:"errors,omitempty"`
+}
+
+// RegisterMarkdownSearch registers the markdown_search tool.
+func RegisterMarkdownSearch(srv server.Server) {
+	srv.Tool(
+		"markdown_search",
+		"Search text or a regex in one markdown file or a whole directory. Each hit returns the line, a snippet and the enclosing section with its heading path and line range, replacing grep followed by markdown_read_section. Use rank_by 'section' to get hits in the smallest sections first.",
+		handleSearch,
+	)
+}
+
+// searchOptions holds validated markdown_search arguments.
+type searchOptions struct {
+	pattern      *regexp.Regexp
+	contextLines int
+	rankBy       string
+	limit        int
+}
+
+// handleSearch implements the markdown_search tool logic.
+func handleSearch(
+	_ *server.Context,
+	args MarkdownSearchArgs,
+) (interface{}, error) {
+	// Note: gomcp's server.Context does not provide request-level context.
+	// Application-level cancellation is handled via signal handling in main.go.
+	reqCtx := context.Background()
+
+	options, err := newSearchOptions(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files to search and the names reported for them
+	filePaths, names, err := searchTargets(
+		args.FilePath,
+		args.Directory,
+		args.Include,
+		args.Exclude,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hitsByFile := make([][]SearchHit, len(filePaths))
+	errs := make([]error, len(filePaths))
+	forEachFile(reqCtx, len(filePaths), func(i int) {
+		hitsByFile[i], errs[i] = searchFile(
+			reqCtx,
+			filePaths[i],
+			names[i],
+			options,
+		)
+	})
+	if err := reqCtx.Err(); err != nil {
+		return nil, fmt.Errorf("search canceled: %w", err)
+	}
+
+	response := MarkdownSearchResponse{
+		Hits:         []SearchHit{},
+		TotalHits:    0,
+		Truncated:    false,
+		FilesScanned: len(filePaths),
+		RankBy:       options.rankBy,
+		Errors:       nil,
+	}
+
+	var hits []SearchHit
+	for i, fileHits := range hitsByFile {
+		if errs[i] != nil {
+			// A single file is the whole request: report its error
+			if args.FilePath != nil {
+				return nil, errs[i]
+			}
+			response.Errors = append(response.Errors, FileError{
+				Path:  names[i],
+				Error: errs[i].Error(),
+			})
+			continue
+		}
+		hits = append(hits, fileHits...)
+	}
+
+	if options.rankBy == rankBySection {
+		rankHitsBySection(hits)
+	}
+
+	response.TotalHits = len(hits)
+	if len(hits) > options.limit {
+		hits = hits[:options.limit]
+		response.Truncated = true
+	}
+	if len(hits) > 0 {
+		response.Hits = hits
+	}
+
+	return response, nil
+}
+
+// newSearchOptions validates the query, context, ranking and limit
+// arguments and applies their defaults.
+func newSearchOptions(args MarkdownSearchArgs) (searchOptions, error) {
+	if args.Query == "" {
+		return searchOptions{}, fmt.Errorf(
+			"%w: query must not be empty",
+			ctags.ErrInvalidPattern,
+		)
+	}
+
+	expr := args.Query
+	if args.Regex == nil || !*args.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if args.CaseSensitive == nil || !*args.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return searchOptions{}, fmt.Errorf(
+			"%w: query %q: %w",
+			ctags.ErrInvalidPattern,
+			args.Query,
+			err,
+		)
+	}
+
+	options := searchOptions{
+		pattern:      pattern,
+		contextLines: defaultSearchContext,
+		rankBy:       rankByPosition,
+		limit:        defaultSearchLimit,
+	}
+	if args.ContextLines != nil {
+		options.contextLines = *args.ContextLines
+	}
+	if args.RankBy != nil && *args.RankBy != "" {
+		options.rankBy = *args.RankBy
+	}
+	if args.MaxResults != nil {
+		options.limit = *args.MaxResults
+	}
+
+	switch {
+	case options.contextLines < 0 || options.contextLines > maxSearchContext:
+		return searchOptions{}, fmt.Errorf(
+			"%w: context_lines %d (must be 0-%d)",
+			ErrInvalidLimit,
+			options.contextLines,
+			maxSearchContext,
+		)
+	case options.limit < 1:
+		return searchOptions{}, fmt.Errorf(
+			"%w: max_results %d (must be 1 or greater)",
+			ErrInvalidLimit,
+			options.limit,
+		)
+	case options.rankBy != rankByPosition && options.rankBy != rankBySection:
+		return searchOptions{}, fmt.Errorf(
+			"%w: rank_by %q (must be '%s' or '%s')",
+			ErrInvalidFormat,
+			options.rankBy,
+			rankByPosition,
+			rankBySection,
+		)
+	}
+
+	return options, nil
+}
+
+// searchTargets returns the files to search and the names to report them
+// under: the file path as given, or paths relative to the directory.
+func searchTargets(
+	filePath *string,
+	directory *string,
+	include []string,
+	exclude []string,
+) ([]string, []string, error) {
+	hasFile := filePath != nil && *filePath != ""
+	hasDirectory := directory != nil && *directory != ""
+
+	switch {
+	case hasFile && hasDirectory, !hasFile && !hasDirectory:
+		return nil, nil, fmt.Errorf(
+			"%w: give either file_path or directory",
+			ErrInvalidTarget,
+		)
+	case hasFile:
+		return []string{*filePath}, []string{*filePath}, nil
+	default:
+		workspace, err := resolveWorkspace(*directory, include, exclude)
+		if err != nil {
+			return nil, nil, err
+		}
+		return workspace.FilePaths, workspace.RelPaths, nil
+	}
+}
+
+// searchFile returns the hits for the pattern in one file, each with its
+// snippet and enclosing section.
+func searchFile(
+	ctx context.Context,
+	filePath string,
+	name string,
+	options searchOptions,
+) ([]SearchHit, error) {
+	entries, err := ctags.GetGlobalCache().GetTags(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := ctags.SplitContentLines(content)
+
+	var hits []SearchHit
+	for i, line := range lines {
+		location := options.pattern.FindStringIndex(line)
+		if location == nil {
+			continue
+		}
+
+		lineNumber := i + 1
+		start := max(0, i-options.contextLines)
+		end := min(len(lines), i+options.contextLines+1)
+
+		hit := SearchHit{
+			File:         name,
+			Line:         lineNumber,
+			Column:       location[0] + 1,
+			Text:         line,
+			Snippet:      strings.Join(lines[start:end], "\n"),
+			SnippetStart: start + 1,
+			SnippetEnd:   end,
+			Section:      nil,
+		}
+		if entry, ok := ctags.SectionAtLine(entries, lineNumber); ok {
+			hit.Section = &SearchSection{
+				Name:        entry.Name,
+				Slug:        entry.Slug,
+				Level:       fmt.Sprintf("H%d", entry.Level),
+				HeadingPath: entry.HeadingPath(),
+				StartLine:   entry.Line,
+				EndLine:     entry.End,
+				Lines:       entry.End - entry.Line + 1,
+			}
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, nil
+}
+
+// rankHitsBySection orders hits so that those in the smallest enclosing
+// sections come first. Hits in the same section stay together in line
+// order; hits before the first heading come last.
+func rankHitsBySection(hits []SearchHit) {
+	sort.SliceStable(hits, func(i, j int) bool {
+		a, b := hits[i].Section, hits[j].Section
+		switch {
+		case a == nil || b == nil:
+			return a != nil && b == nil
+		case a.Lines != b.Lines:
+			return a.Lines < b.Lines
+		case hits[i].File != hits[j].File:
+			return hits[i].File < hits[j].File
+		default:
+			return a.StartLine < b.StartLine
+		}
+	})
+}
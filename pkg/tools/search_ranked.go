@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// defaultRankedLimit is the default number of sections returned by
+// markdown_search_ranked.
+const defaultRankedLimit = 10
+
+// MarkdownSearchRankedArgs defines the input arguments.
+type MarkdownSearchRankedArgs struct {
+	Query      string   `json:"query"                 description:"Free-text description of what to find, e.g. 'cache invalidation on file changes'"                   required:"true"`
+	FilePath   *string  `json:"file_path,omitempty"   description:"Markdown file to search. Give either file_path or directory"`
+	Directory  *string  `json:"directory,omitempty"   description:"Directory whose markdown files are searched. Give either file_path or directory"`
+	Include    []string `json:"include,omitempty"     description:"Globs selecting files in directory, as in markdown_workspace_tree. Default: ['*.md', '*.markdown']"`
+	Exclude    []string `json:"exclude,omitempty"     description:"Globs of files or directories in directory to skip"`
+	MaxResults *int     `json:"max_results,omitempty" description:"Number of best sections returned. Default: 10"`
+}
+
+// RankedSection is a section returned by markdown_search_ranked.
+type RankedSection struct {
+	File         string   `json:"file"` // As given, or relative to directory
+	Name         string   `json:"name"`
+	Slug         string   `json:"slug"`
+	Level        string   `json:"level"`
+	HeadingPath  []string `json:"heading_path"`
+	StartLine    int      `json:"start_line"`
+	EndLine      int      `json:"end_line"`
+	Score        float64  `json:"score"`
+	MatchedTerms []string `json:"matched_terms"`
+}
+
+// MarkdownSearchRankedResponse defines the response structure.
+type MarkdownSearchRankedResponse struct {
+	Sections         []RankedSection `json:"sections"`
+	Terms            []string        `json:"terms"`             // Query terms after normalization
+	Matched          int             `json:"matched"`           // Sections containing a query term
+	SectionsSearched int             `json:"sections_searched"` // Sections scored against the query
+	FilesScanned     int             `json:"files_scanned"`
+	Errors           []FileError     `json:"errors,omitempty"`
+}
+
+// RegisterMarkdownSearchRanked registers the markdown_search_ranked tool.
+func RegisterMarkdownSearchRanked(srv server.Server) {
+	srv.Tool(
+		"markdown_search_ranked",
+		"Find the sections most relevant to a free-text query in one markdown file or a whole directory, ranked by BM25 relevance of each section's own text rather than by exact matches. Returns the top sections with scores, heading paths and line ranges; read them with markdown_read_section. Use markdown_search for exact text or regex matches.",
+		handleSearchRanked,
+	)
+}
+
+// handleSearchRanked implements the markdown_search_ranked tool logic.
+func handleSearchRanked(
+	_ *server.Context,
+	args MarkdownSearchRankedArgs,
+) (interface{}, error) {
+	// Note: gomcp's server.Context does not provide request-level context.
+	// Application-level cancellation is handled via signal handling in main.go.
+	reqCtx := context.Background()
+
+	limit := defaultRankedLimit
+	if args.MaxResults != nil {
+		limit = *args.MaxResults
+	}
+	if limit < 1 {
+		return nil, fmt.Errorf(
+			"%w: max_results %d (must be 1 or greater)",
+			ErrInvalidLimit,
+			limit,
+		)
+	}
+
+	filePaths, names, err := searchTargets(
+		args.FilePath,
+		args.Directory,
+		args.Include,
+		args.Exclude,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index changed or uncached files; unchanged files keep their postings
+	cache := ctags.GetGlobalCache()
+	errs := make([]error, len(filePaths))
+	forEachFile(reqCtx, len(filePaths), func(i int) {
+		errs[i] = cache.IndexSections(reqCtx, filePaths[i])
+	})
+	if err := reqCtx.Err(); err != nil {
+		return nil, fmt.Errorf("search canceled: %w", err)
+	}
+
+	response := MarkdownSearchRankedResponse{
+		Sections:         []RankedSection{},
+		Terms:            nil,
+		Matched:          0,
+		SectionsSearched: 0,
+		FilesScanned:     len(filePaths),
+		Errors:           nil,
+	}
+
+	nameByPath := make(map[string]string, len(filePaths))
+	for i, filePath := range filePaths {
+		if errs[i] != nil {
+			// A single file is the whole request: report its error
+			if args.FilePath != nil {
+				return nil, fmt.Errorf("failed to index sections: %w", errs[i])
+			}
+			response.Errors = append(response.Errors, FileError{
+				Path:  names[i],
+				Error: errs[i].Error(),
+			})
+			continue
+		}
+		nameByPath[filePath] = names[i]
+	}
+
+	ranked := cache.RankSections(filePaths, args.Query, limit)
+	if len(ranked.Terms) == 0 {
+		return nil, fmt.Errorf(
+			"%w: query %q contains no words",
+			ctags.ErrInvalidPattern,
+			args.Query,
+		)
+	}
+
+	response.Terms = ranked.Terms
+	response.Matched = ranked.Matched
+	response.SectionsSearched = ranked.Searched
+	for _, section := range ranked.Sections {
+		entry := section.Entry
+		response.Sections = append(response.Sections, RankedSection{
+			File:         nameByPath[section.FilePath],
+			Name:         entry.Name,
+			Slug:         entry.Slug,
+			Level:        fmt.Sprintf("H%d", entry.Level),
+			HeadingPath:  entry.HeadingPath(),
+			StartLine:    entry.Line,
+			EndLine:      entry.End,
+			Score:        math.Round(section.Score*1000) / 1000,
+			MatchedTerms: section.Terms,
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,144 @@
+package tools
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// searchRankedArgs returns arguments ranking the sections of directory root.
+func searchRankedArgs(root, query string) MarkdownSearchRankedArgs {
+	return MarkdownSearchRankedArgs{
+		Query:      query,
+		FilePath:   nil,
+		Directory:  &root,
+		Include:    nil,
+		Exclude:    nil,
+		MaxResults: nil,
+	}
+}
+
+// runSearchRanked calls handleSearchRanked and checks the response type.
+func runSearchRanked(
+	t *testing.T,
+	args MarkdownSearchRankedArgs,
+) MarkdownSearchRankedResponse {
+	t.Helper()
+
+	result, err := handleSearchRanked(nil, args)
+	if err != nil {
+		t.Fatalf("handleSearchRanked failed: %v", err)
+	}
+	response, ok := result.(MarkdownSearchRankedResponse)
+	if !ok {
+		t.Fatalf("Unexpected response type %T", result)
+	}
+	return response
+}
+
+func TestHandleSearchRanked_TopSections(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{
+		"design.md": "# Design\n" +
+			"## Storage\n" +
+			"Files are stored on disk.\n" +
+			"## Cache\n" +
+			"### Invalidation\n" +
+			"Cached entries are invalidated when files change.\n" +
+			"Invalidation uses the mtime.\n",
+		"ops/runbook.md": "# Runbook\n" +
+			"Clear the cache after deploying.\n",
+	})
+
+	response := runSearchRanked(t, searchRankedArgs(
+		root,
+		"caching invalidation",
+	))
+
+	if response.FilesScanned != 2 || response.SectionsSearched != 5 {
+		t.Errorf("Expected 5 sections in 2 files, got %d in %d",
+			response.SectionsSearched, response.FilesScanned)
+	}
+	if !reflect.DeepEqual(response.Terms, []string{"cach", "invalidat"}) {
+		t.Errorf("Unexpected terms %v", response.Terms)
+	}
+	if response.Matched != 3 || len(response.Sections) != 3 {
+		t.Fatalf("Expected 3 matching sections, got %+v", response.Sections)
+	}
+
+	best := response.Sections[0]
+	if best.File != "design.md" || best.Name != "Invalidation" ||
+		best.Level != "H3" || best.StartLine != 5 || best.EndLine != 7 {
+		t.Errorf("Unexpected best section %+v", best)
+	}
+	if !reflect.DeepEqual(
+		best.HeadingPath,
+		[]string{"Design", "Cache", "Invalidation"},
+	) {
+		t.Errorf("Unexpected heading path %v", best.HeadingPath)
+	}
+	for i := 1; i < len(response.Sections); i++ {
+		if response.Sections[i].Score > response.Sections[i-1].Score {
+			t.Errorf("Sections not sorted by score: %+v", response.Sections)
+		}
+	}
+
+	limit := 1
+	args := searchRankedArgs(root, "cache")
+	args.MaxResults = &limit
+	response = runSearchRanked(t, args)
+	if len(response.Sections) != 1 || response.Matched != 3 {
+		t.Errorf("Expected 1 of 3 sections, got %d of %d",
+			len(response.Sections), response.Matched)
+	}
+}
+
+func TestHandleSearchRanked_SingleFile(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{
+		"a.md": "# A\n## Retry\nRetry with backoff.\n## Other\nNothing.\n",
+	})
+	filePath := filepath.Join(root, "a.md")
+
+	args := searchRankedArgs(root, "backoff")
+	args.Directory = nil
+	args.FilePath = &filePath
+	response := runSearchRanked(t, args)
+
+	if len(response.Sections) != 1 || response.Sections[0].File != filePath ||
+		response.Sections[0].Slug != "retry" {
+		t.Errorf("Unexpected sections %+v", response.Sections)
+	}
+}
+
+func TestHandleSearchRanked_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{"a.md": "# A\n"})
+
+	args := searchRankedArgs(root, " ?! ")
+	if _, err := handleSearchRanked(nil, args); !errors.Is(
+		err,
+		ctags.ErrInvalidPattern,
+	) {
+		t.Errorf("Expected ErrInvalidPattern, got %v", err)
+	}
+
+	limit := 0
+	args = searchRankedArgs(root, "a")
+	args.MaxResults = &limit
+	if _, err := handleSearchRanked(nil, args); !errors.Is(err, ErrInvalidLimit) {
+		t.Errorf("Expected ErrInvalidLimit, got %v", err)
+	}
+
+	args = searchRankedArgs(root, "a")
+	args.Directory = nil
+	if _, err := handleSearchRanked(nil, args); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("Expected ErrInvalidTarget, got %v", err)
+	}
+}
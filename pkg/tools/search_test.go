@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// searchArgs returns arguments searching query in directory root.
+func searchArgs(root, query string) MarkdownSearchArgs {
+	return MarkdownSearchArgs{
+		Query:         query,
+		FilePath:      nil,
+		Directory:     &root,
+		Include:       nil,
+		Exclude:       nil,
+		Regex:         nil,
+		CaseSensitive: nil,
+		ContextLines:  nil,
+		RankBy:        nil,
+		MaxResults:    nil,
+	}
+}
+
+// runSearch calls handleSearch and checks the response type.
+func runSearch(t *testing.T, args MarkdownSearchArgs) MarkdownSearchResponse {
+	t.Helper()
+
+	result, err := handleSearch(nil, args)
+	if err != nil {
+		t.Fatalf("handleSearch failed: %v", err)
+	}
+	response, ok := result.(MarkdownSearchResponse)
+	if !ok {
+		t.Fatalf("Unexpected response type %T", result)
+	}
+	return response
+}
+
+func TestHandleSearch_HitsWithSections(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{
+		"guide.md": "Intro mentions TIMEOUT.\n" +
+			"# Guide\n" +
+			"## Config\n" +
+			"Set the timeout here.\n" +
+			"### Advanced\n" +
+			"Retry after timeout.\n",
+		"notes/todo.md": "# Todo\nNothing here.\n",
+	})
+
+	response := runSearch(t, searchArgs(root, "timeout"))
+
+	if response.FilesScanned != 2 || response.TotalHits != 3 {
+		t.Fatalf("Expected 3 hits in 2 files, got %d in %d",
+			response.TotalHits, response.FilesScanned)
+	}
+	if response.RankBy != "position" || response.Truncated {
+		t.Errorf("Unexpected rank_by %q (truncated %v)",
+			response.RankBy, response.Truncated)
+	}
+
+	// Lines before the first heading have no section
+	first := response.Hits[0]
+	if first.Line != 1 || first.Column != 16 || first.Section != nil {
+		t.Errorf("Unexpected first hit %+v", first)
+	}
+
+	config := response.Hits[1]
+	if config.File != "guide.md" || config.Line != 4 {
+		t.Fatalf("Unexpected second hit %+v", config)
+	}
+	if config.SnippetStart != 2 || config.SnippetEnd != 6 {
+		t.Errorf("Expected snippet lines 2-6, got %d-%d",
+			config.SnippetStart, config.SnippetEnd)
+	}
+	section := config.Section
+	if section == nil || section.Name != "Config" || section.Level != "H2" ||
+		section.StartLine != 3 || section.EndLine != 6 {
+		t.Fatalf("Unexpected section %+v", section)
+	}
+	if !reflect.DeepEqual(section.HeadingPath, []string{"Guide", "Config"}) {
+		t.Errorf("Unexpected heading path %v", section.HeadingPath)
+	}
+
+	if advanced := response.Hits[2].Section; advanced == nil ||
+		advanced.Slug != "advanced" || advanced.Lines != 2 {
+		t.Errorf("Unexpected innermost section %+v", advanced)
+	}
+}
+
+func TestHandleSearch_SingleFileAndOptions(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{
+		"doc.md": "# Doc\n" +
+			"Error code E42 and e43.\n" +
+			"## Details\n" +
+			"E44 again.\n",
+	})
+	filePath := filepath.Join(root, "doc.md")
+
+	regex, caseSensitive, contextLines := true, true, 0
+	args := searchArgs(root, `E4\d`)
+	args.Directory = nil
+	args.FilePath = &filePath
+	args.Regex = &regex
+	args.CaseSensitive = &caseSensitive
+	args.ContextLines = &contextLines
+	response := runSearch(t, args)
+
+	if response.TotalHits != 2 {
+		t.Fatalf("Expected 2 case-sensitive hits, got %+v", response.Hits)
+	}
+	hit := response.Hits[0]
+	if hit.File != filePath || hit.Snippet != hit.Text ||
+		hit.SnippetStart != 2 || hit.SnippetEnd != 2 {
+		t.Errorf("Unexpected hit %+v", hit)
+	}
+
+	// Regex metacharacters are literal unless regex is set
+	response = runSearch(t, searchArgs(root, `E4\d`))
+	if response.TotalHits != 0 || response.Hits == nil {
+		t.Errorf("Expected an empty hit list, got %+v", response.Hits)
+	}
+}
+
+func TestHandleSearch_RankBySectionAndLimit(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{
+		"a.md": "# Overview\ncache\n\n\n\n## Cache\ncache\ncache\n",
+		"b.md": "cache first\n# Small\ncache\n",
+	})
+
+	rankBy := "section"
+	args := searchArgs(root, "cache")
+	args.RankBy = &rankBy
+	response := runSearch(t, args)
+
+	var order []string
+	for _, hit := range response.Hits {
+		name := ""
+		if hit.Section != nil {
+			name = hit.Section.Name
+		}
+		order = append(order, hit.File+":"+name)
+	}
+	expected := []string{
+		"b.md:Small",
+		"a.md:Cache",
+		"a.md:Cache",
+		"a.md:Cache",
+		"a.md:Overview",
+		"b.md:",
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected order %v, got %v", expected, order)
+	}
+
+	limit := 2
+	args.MaxResults = &limit
+	response = runSearch(t, args)
+	if len(response.Hits) != 2 || response.TotalHits != 6 ||
+		!response.Truncated {
+		t.Errorf("Expected 2 of 6 hits, got %d of %d (truncated %v)",
+			len(response.Hits), response.TotalHits, response.Truncated)
+	}
+}
+
+func TestHandleSearch_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{"a.md": "# A\n"})
+
+	regex := true
+	args := searchArgs(root, "(")
+	args.Regex = &regex
+	if _, err := handleSearch(nil, args); !errors.Is(
+		err,
+		ctags.ErrInvalidPattern,
+	) {
+		t.Errorf("Expected ErrInvalidPattern, got %v", err)
+	}
+
+	args = searchArgs(root, "A")
+	args.Directory = nil
+	if _, err := handleSearch(nil, args); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("Expected ErrInvalidTarget, got %v", err)
+	}
+
+	rankBy := "score"
+	args = searchArgs(root, "A")
+	args.RankBy = &rankBy
+	if _, err := handleSearch(nil, args); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Expected ErrInvalidFormat, got %v", err)
+	}
+
+	contextLines := -1
+	args = searchArgs(root, "A")
+	args.ContextLines = &contextLines
+	if _, err := handleSearch(nil, args); !errors.Is(err, ErrInvalidLimit) {
+		t.Errorf("Expected ErrInvalidLimit, got %v", err)
+	}
+}
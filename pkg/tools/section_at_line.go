@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// MarkdownSectionAtLineArgs defines the input arguments.
+type MarkdownSectionAtLineArgs struct {
+	FilePath string `json:"file_path" description:"Path to markdown file"                                                       required:"true"`
+	Line     int    `json:"line"      description:"1-based line number, e.g. from grep output, a compiler error or a diff hunk" required:"true"`
+}
+
+// SectionRef identifies a section and its line range.
+type SectionRef struct {
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	Level     string `json:"level"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// MarkdownSectionAtLineResponse defines the response structure.
+type MarkdownSectionAtLineResponse struct {
+	Line            int          `json:"line"`
+	Section         *SectionRef  `json:"section"`          // Innermost section, nil before the first heading
+	HeadingPath     []string     `json:"heading_path"`     // Names from the outermost ancestor to the section
+	Ancestors       []SectionRef `json:"ancestors"`        // Enclosing sections, outermost first
+	PreviousSibling *SectionRef  `json:"previous_sibling"` // Previous section with the same parent
+	NextSibling     *SectionRef  `json:"next_sibling"`     // Next section with the same parent
+}
+
+// RegisterMarkdownSectionAtLine registers the markdown_section_at_line tool.
+func RegisterMarkdownSectionAtLine(srv server.Server) {
+	srv.Tool(
+		"markdown_section_at_line",
+		"Find the section containing a line number. Returns the innermost section, its ancestor chain with bounds, and the previous and next sibling sections. Use when you have a line number from grep, an error message or a diff hunk and need its context.",
+		handleSectionAtLine,
+	)
+}
+
+// handleSectionAtLine implements the markdown_section_at_line tool logic.
+func handleSectionAtLine(
+	_ *server.Context,
+	args MarkdownSectionAtLineArgs,
+) (interface{}, error) {
+	// Note: gomcp's server.Context does not provide request-level context.
+	// Application-level cancellation is handled via signal handling in main.go.
+	reqCtx := context.Background()
+
+	if args.Line < 1 {
+		return nil, fmt.Errorf(
+			"%w: %d (must be 1 or greater)",
+			ErrInvalidLine,
+			args.Line,
+		)
+	}
+
+	// Get tags from cache with context
+	cache := ctags.GetGlobalCache()
+	entries, err := cache.GetTags(reqCtx, args.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNoEntries, args.FilePath)
+	}
+
+	response := MarkdownSectionAtLineResponse{
+		Line:            args.Line,
+		Section:         nil,
+		HeadingPath:     []string{},
+		Ancestors:       []SectionRef{},
+		PreviousSibling: nil,
+		NextSibling:     nil,
+	}
+
+	entry, found := ctags.SectionAtLine(entries, args.Line)
+	if !found {
+		// Lines before the first heading belong to no section
+		if args.Line < entries[0].Line {
+			response.NextSibling = newSectionRef(entries[0])
+			return response, nil
+		}
+
+		return nil, fmt.Errorf(
+			"%w: %d is past the last section of %s",
+			ErrInvalidLine,
+			args.Line,
+			args.FilePath,
+		)
+	}
+
+	response.Section = newSectionRef(entry)
+	response.HeadingPath = entry.HeadingPath()
+	for _, ancestor := range ctags.Ancestors(entries, entry) {
+		response.Ancestors = append(response.Ancestors, *newSectionRef(ancestor))
+	}
+
+	previous, next := ctags.Siblings(entries, entry)
+	if previous != nil {
+		response.PreviousSibling = newSectionRef(previous)
+	}
+	if next != nil {
+		response.NextSibling = newSectionRef(next)
+	}
+
+	return response, nil
+}
+
+// newSectionRef describes an entry as a SectionRef.
+func newSectionRef(entry *ctags.TagEntry) *SectionRef {
+	return &SectionRef{
+		Name:      entry.Name,
+		Slug:      entry.Slug,
+		Level:     fmt.Sprintf("H%d", entry.Level),
+		StartLine: entry.Line,
+		EndLine:   entry.End,
+	}
+}
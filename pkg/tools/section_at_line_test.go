@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleSectionAtLine_DeepSection(t *testing.T) {
+	t.Parallel()
+
+	targetFile := filepath.Join("..", "..", "testdata", "sample.md")
+
+	result, err := handleSectionAtLine(nil, MarkdownSectionAtLineArgs{
+		FilePath: targetFile,
+		Line:     38,
+	})
+	if err != nil {
+		t.Fatalf("handleSectionAtLine failed: %v", err)
+	}
+	response, ok := result.(MarkdownSectionAtLineResponse)
+	if !ok {
+		t.Fatalf("Unexpected response type %T", result)
+	}
+
+	if response.Section == nil ||
+		response.Section.Name != "Deep Section 2.2.1: Unit Tests" {
+		t.Fatalf("Expected Deep Section 2.2.1, got %+v", response.Section)
+	}
+	if response.Section.StartLine != 36 || response.Section.EndLine != 39 {
+		t.Errorf(
+			"Expected bounds 36-39, got %d-%d",
+			response.Section.StartLine,
+			response.Section.EndLine,
+		)
+	}
+
+	expectedAncestors := []string{
+		"Test Document",
+		"Section 2: Implementation",
+		"Subsection 2.2: Testing",
+	}
+	if len(response.Ancestors) != len(expectedAncestors) {
+		t.Fatalf(
+			"Expected %d ancestors, got %d",
+			len(expectedAncestors),
+			len(response.Ancestors),
+		)
+	}
+	for i, name := range expectedAncestors {
+		if response.Ancestors[i].Name != name {
+			t.Errorf("Ancestor %d: expected %q, got %q",
+				i, name, response.Ancestors[i].Name)
+		}
+	}
+	if response.Ancestors[2].StartLine != 32 {
+		t.Errorf("Expected parent to start at 32, got %d",
+			response.Ancestors[2].StartLine)
+	}
+	if len(response.HeadingPath) != 4 {
+		t.Errorf("Expected heading path of 4, got %v", response.HeadingPath)
+	}
+
+	if response.PreviousSibling != nil {
+		t.Errorf("Expected no previous sibling, got %+v",
+			response.PreviousSibling)
+	}
+	if response.NextSibling == nil ||
+		response.NextSibling.Name != "Deep Section 2.2.2: Integration Tests" {
+		t.Errorf("Expected Deep Section 2.2.2 as next sibling, got %+v",
+			response.NextSibling)
+	}
+}
+
+func TestHandleSectionAtLine_HeadingLine(t *testing.T) {
+	t.Parallel()
+
+	targetFile := filepath.Join("..", "..", "testdata", "sample.md")
+
+	result, err := handleSectionAtLine(nil, MarkdownSectionAtLineArgs{
+		FilePath: targetFile,
+		Line:     21,
+	})
+	if err != nil {
+		t.Fatalf("handleSectionAtLine failed: %v", err)
+	}
+	response, _ := result.(MarkdownSectionAtLineResponse)
+
+	if response.Section == nil ||
+		response.Section.Name != "Section 2: Implementation" {
+		t.Fatalf("Expected Section 2, got %+v", response.Section)
+	}
+	if response.Section.Slug != "section-2-implementation" {
+		t.Errorf("Unexpected slug %q", response.Section.Slug)
+	}
+	if response.PreviousSibling == nil ||
+		response.PreviousSibling.Name != "Section 1: Introduction" {
+		t.Errorf("Expected Section 1 as previous sibling, got %+v",
+			response.PreviousSibling)
+	}
+	if response.NextSibling == nil ||
+		response.NextSibling.Name != "Section 3: Conclusion" {
+		t.Errorf("Expected Section 3 as next sibling, got %+v",
+			response.NextSibling)
+	}
+}
+
+func TestHandleSectionAtLine_InvalidLine(t *testing.T) {
+	t.Parallel()
+
+	targetFile := filepath.Join("..", "..", "testdata", "sample.md")
+
+	for _, line := range []int{0, -3, 1000} {
+		_, err := handleSectionAtLine(nil, MarkdownSectionAtLineArgs{
+			FilePath: targetFile,
+			Line:     line,
+		})
+		if !errors.Is(err, ErrInvalidLine) {
+			t.Errorf("Line %d: expected ErrInvalidLine, got %v", line, err)
+		}
+	}
+}
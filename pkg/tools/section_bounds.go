@@ -10,17 +10,25 @@ import (
 
 // MarkdownSectionBoundsArgs defines the input arguments.
 type MarkdownSectionBoundsArgs struct {
-	FilePath       string `json:"file_path"       description:"Path to markdown file"                                                                                                   required:"true"`
-	SectionHeading string `json:"section_heading" description:"Exact heading text to find (case-sensitive, without # symbols). Example: 'Executive Summary' not '## Executive Summary'" required:"true"`
+	FilePath       string  `json:"file_path"                description:"Path to markdown file"                                                                                                                                                                                                                                    required:"true"`
+	SectionHeading string  `json:"section_heading"          description:"Heading text to find (without # symbols), or a heading path separated by ' > ' to address nested sections. An anchor such as '#executive-summary' selects the section by its GitHub slug. Example: 'Executive Summary' or 'Phase 2 > Task 2.1 > Testing'" required:"true"`
+	Occurrence     *int    `json:"occurrence,omitempty"     description:"Which match to use when several sections match (1=first). Default: 1"`
+	MatchMode      *string `json:"match_mode,omitempty"     description:"How heading names are compared: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive  *bool   `json:"case_sensitive,omitempty" description:"Compare heading names case-sensitively. Default: false"`
+	Strict         *bool   `json:"strict,omitempty"         description:"Fail with a list of candidate sections (heading path, level, lines) instead of using the first match when the heading is ambiguous. Default: false"`
 }
 
 // MarkdownSectionBoundsResponse defines the response structure.
 type MarkdownSectionBoundsResponse struct {
-	SectionName  string `json:"section_name"`
-	StartLine    int    `json:"start_line"`
-	EndLine      int    `json:"end_line"`
-	HeadingLevel string `json:"heading_level"`
-	TotalLines   int    `json:"total_lines"`
+	SectionName  string   `json:"section_name"`
+	Slug         string   `json:"slug"`
+	HeadingPath  []string `json:"heading_path"`
+	StartLine    int      `json:"start_line"`
+	EndLine      int      `json:"end_line"`
+	HeadingLevel string   `json:"heading_level"`
+	TotalLines   int      `json:"total_lines"`
+	FileHash     string   `json:"file_hash"` // Pass as expected_hash to edit
+	Mtime        string   `json:"mtime"`     // Or as expected_mtime
 }
 
 // RegisterMarkdownSectionBounds registers the markdown_section_bounds tool.
@@ -45,16 +53,22 @@ func RegisterMarkdownSectionBounds(srv server.Server) {
 			}
 
 			// Find section bounds
-			startLine, endLine, sectionName, found := ctags.FindSectionBounds(
-				entries,
-				args.SectionHeading,
-			)
-			if !found {
-				return nil, fmt.Errorf(
-					"%w: '%s'",
-					ErrSectionNotFound,
-					args.SectionHeading,
-				)
+			entry, err := resolveSection(entries, sectionAddress{
+				Heading:       args.SectionHeading,
+				Occurrence:    args.Occurrence,
+				MatchMode:     args.MatchMode,
+				CaseSensitive: args.CaseSensitive,
+				Strict:        args.Strict,
+			})
+			if err != nil {
+				return nil, err
+			}
+			startLine, endLine := entry.Line, entry.End
+
+			// Version for edits based on these bounds
+			_, version, err := ctags.ReadFileVersion(args.FilePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read file: %w", err)
 			}
 
 			// Calculate total lines
@@ -67,21 +81,16 @@ func RegisterMarkdownSectionBounds(srv server.Server) {
 				totalLines = -1 // Special value indicating "to EOF"
 			}
 
-			// Find the entry to get the heading level
-			var headingLevel string
-			for _, entry := range entries {
-				if entry.Line == startLine {
-					headingLevel = fmt.Sprintf("H%d", entry.Level)
-					break
-				}
-			}
-
 			return MarkdownSectionBoundsResponse{
-				SectionName:  sectionName,
+				SectionName:  entry.Name,
+				Slug:         entry.Slug,
+				HeadingPath:  entry.HeadingPath(),
 				StartLine:    startLine,
 				EndLine:      endLine,
-				HeadingLevel: headingLevel,
+				HeadingLevel: fmt.Sprintf("H%d", entry.Level),
 				TotalLines:   totalLines,
+				FileHash:     version.Hash,
+				Mtime:        formatModTime(version.ModTime),
 			}, nil
 		},
 	)
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// sectionAddress holds the section addressing arguments shared by all tools.
+type sectionAddress struct {
+	Heading       string  // Heading text or "Parent > Child" path
+	Occurrence    *int    // 1-based match index (optional)
+	MatchMode     *string // See ctags.MatchMode (optional)
+	CaseSensitive *bool   // Compare names case-sensitively (optional)
+	Strict        *bool   // Fail on ambiguous matches (optional)
+}
+
+// sectionAddressFromArgs builds a sectionAddress from optional tool
+// arguments. A nil heading yields an empty address (no section scoping).
+func sectionAddressFromArgs(
+	heading *string,
+	occurrence *int,
+	matchMode *string,
+	caseSensitive *bool,
+	strict *bool,
+) sectionAddress {
+	address := sectionAddress{
+		Heading:       "",
+		Occurrence:    occurrence,
+		MatchMode:     matchMode,
+		CaseSensitive: caseSensitive,
+		Strict:        strict,
+	}
+	if heading != nil {
+		address.Heading = *heading
+	}
+	return address
+}
+
+// resolveSection finds the section addressed by the section arguments.
+// The heading may be plain heading text or a path such as
+// "Phase 2 > Task 2.1 > Testing"; occurrence (1-based) selects among several
+// matches and defaults to the first one. In strict mode an ambiguous query
+// fails with a *ctags.AmbiguousSectionError listing every candidate.
+func resolveSection(
+	entries []*ctags.TagEntry,
+	address sectionAddress,
+) (*ctags.TagEntry, error) {
+	query, err := buildSectionQuery(address)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := ctags.ResolveSection(entries, query)
+	if errors.Is(err, ctags.ErrSectionNotFound) {
+		return nil, fmt.Errorf("%w: '%s'", ErrSectionNotFound, address.Heading)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve section: %w", err)
+	}
+
+	return entry, nil
+}
+
+// buildSectionQuery validates the section arguments and converts them to a
+// ctags.SectionQuery.
+func buildSectionQuery(address sectionAddress) (ctags.SectionQuery, error) {
+	occurrence := 0
+	if address.Occurrence != nil {
+		if *address.Occurrence < 1 {
+			return ctags.SectionQuery{}, fmt.Errorf(
+				"%w: occurrence %d (must be 1 or greater)",
+				ctags.ErrOccurrenceOutOfRange,
+				*address.Occurrence,
+			)
+		}
+		occurrence = *address.Occurrence
+	}
+
+	mode, err := parseMatchMode(address.MatchMode)
+	if err != nil {
+		return ctags.SectionQuery{}, err
+	}
+
+	query := ctags.ParseSectionQuery(address.Heading, occurrence)
+	query.Mode = mode
+	query.CaseSensitive = address.CaseSensitive != nil &&
+		*address.CaseSensitive
+	query.Strict = address.Strict != nil && *address.Strict
+
+	return query, nil
+}
+
+// parseMatchMode converts the optional match_mode argument, defaulting to
+// substring matching.
+func parseMatchMode(matchMode *string) (ctags.MatchMode, error) {
+	if matchMode == nil {
+		return ctags.MatchSubstring, nil
+	}
+
+	mode, err := ctags.ParseMatchMode(*matchMode)
+	if err != nil {
+		return "", fmt.Errorf("invalid match_mode: %w", err)
+	}
+	return mode, nil
+}
+
+// newNameMatcher builds a matcher for the section_name_pattern argument
+// using the optional match_mode and case_sensitive arguments.
+func newNameMatcher(
+	pattern string,
+	matchMode *string,
+	caseSensitive *bool,
+) (*ctags.NameMatcher, error) {
+	mode, err := parseMatchMode(matchMode)
+	if err != nil {
+		return nil, err
+	}
+
+	matcher, err := ctags.NewNameMatcher(
+		mode,
+		pattern,
+		caseSensitive != nil && *caseSensitive,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid section_name_pattern: %w", err)
+	}
+	return matcher, nil
+}
+
+// scopeToSection restricts entries to the subtree of the addressed section
+// when a section heading is given. It returns the (possibly restricted)
+// entries and the maximum depth adjusted to count levels below the section,
+// so that depth 1 shows the section itself, 2 its children, and so on.
+func scopeToSection(
+	entries []*ctags.TagEntry,
+	address sectionAddress,
+	depth int,
+) ([]*ctags.TagEntry, int, error) {
+	if address.Heading == "" {
+		return entries, depth, nil
+	}
+
+	root, err := resolveSection(entries, address)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if depth > 0 {
+		depth += root.Level - 1
+	}
+
+	return ctags.Subtree(entries, root), depth, nil
+}
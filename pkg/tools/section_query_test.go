@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// createRepeatedEntries returns entries with repeated "Testing" headings.
+func createRepeatedEntries() []*ctags.TagEntry {
+	content := `# Plan
+## Phase 1
+### Testing
+## Phase 2
+### Task 2.1
+#### Testing
+### Task 2.2
+#### Testing
+`
+	return ctags.ParseMarkdown([]byte(content), "plan.md")
+}
+
+func TestResolveSection_PathAndOccurrence(t *testing.T) {
+	t.Parallel()
+
+	entries := createRepeatedEntries()
+
+	tests := []struct {
+		name       string
+		heading    string
+		occurrence *int
+		wantLine   int
+	}{
+		{"first match by default", "Testing", nil, 3},
+		{"occurrence selects match", "Testing", intPtr(2), 6},
+		{"path narrows match", "Phase 2 > Task 2.2 > Testing", nil, 8},
+		{"path with occurrence", "Phase 2 > Testing", intPtr(2), 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry, err := resolveSection(entries, sectionAddress{
+				Heading:    tt.heading,
+				Occurrence: tt.occurrence,
+				MatchMode:  nil,
+				Strict:     nil,
+			})
+			if err != nil {
+				t.Fatalf("resolveSection failed: %v", err)
+			}
+			if entry.Line != tt.wantLine {
+				t.Errorf("Expected line %d, got %d", tt.wantLine, entry.Line)
+			}
+		})
+	}
+}
+
+func TestResolveSection_Errors(t *testing.T) {
+	t.Parallel()
+
+	entries := createRepeatedEntries()
+
+	_, err := resolveSection(entries, sectionAddress{
+		Heading: "Phase 3 > Testing", Occurrence: nil, MatchMode: nil, Strict: nil,
+	})
+	if !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound, got %v", err)
+	}
+
+	_, err = resolveSection(entries, sectionAddress{
+		Heading: "Testing", Occurrence: intPtr(0), MatchMode: nil, Strict: nil,
+	})
+	if !errors.Is(err, ctags.ErrOccurrenceOutOfRange) {
+		t.Errorf("Expected ErrOccurrenceOutOfRange, got %v", err)
+	}
+
+	_, err = resolveSection(entries, sectionAddress{
+		Heading: "Testing", Occurrence: intPtr(5), MatchMode: nil, Strict: nil,
+	})
+	if !errors.Is(err, ctags.ErrOccurrenceOutOfRange) {
+		t.Errorf("Expected ErrOccurrenceOutOfRange, got %v", err)
+	}
+
+	invalidMode := "fuzzy"
+	_, err = resolveSection(entries, sectionAddress{
+		Heading: "Testing", Occurrence: nil, MatchMode: &invalidMode, Strict: nil,
+	})
+	if !errors.Is(err, ctags.ErrInvalidMatchMode) {
+		t.Errorf("Expected ErrInvalidMatchMode, got %v", err)
+	}
+}
+
+func TestScopeToSection(t *testing.T) {
+	t.Parallel()
+
+	entries := createRepeatedEntries()
+	heading := "Phase 2"
+
+	scoped, depth, err := scopeToSection(
+		entries,
+		sectionAddressFromArgs(&heading, nil, nil, nil, nil),
+		2,
+	)
+	if err != nil {
+		t.Fatalf("scopeToSection failed: %v", err)
+	}
+
+	if len(scoped) != 5 || scoped[0].Name != "Phase 2" {
+		t.Fatalf("Expected Phase 2 subtree with 5 entries, got %d", len(scoped))
+	}
+
+	// Depth 2 relative to an H2 section shows H2 and H3
+	if depth != 3 {
+		t.Errorf("Expected adjusted depth 3, got %d", depth)
+	}
+
+	unscoped, depth, err := scopeToSection(
+		entries,
+		sectionAddressFromArgs(nil, nil, nil, nil, nil),
+		2,
+	)
+	if err != nil || len(unscoped) != len(entries) || depth != 2 {
+		t.Errorf("Expected entries unchanged without section_heading")
+	}
+}
+
+func TestResolveSection_StrictAmbiguity(t *testing.T) {
+	t.Parallel()
+
+	entries := createRepeatedEntries()
+	strict := true
+
+	_, err := resolveSection(entries, sectionAddress{
+		Heading: "Testing", Occurrence: nil, MatchMode: nil, Strict: &strict,
+	})
+
+	var ambiguous *ctags.AmbiguousSectionError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("Expected AmbiguousSectionError, got %v", err)
+	}
+	if len(ambiguous.Candidates) != 3 {
+		t.Errorf("Expected 3 candidates, got %d", len(ambiguous.Candidates))
+	}
+
+	// An explicit occurrence resolves the ambiguity
+	entry, err := resolveSection(entries, sectionAddress{
+		Heading: "Testing", Occurrence: intPtr(2), MatchMode: nil, Strict: &strict,
+	})
+	if err != nil || entry.Line != 6 {
+		t.Errorf("Expected occurrence 2 at line 6, got %v (%v)", entry, err)
+	}
+}
+
+func TestResolveSection_ExactMatchMode(t *testing.T) {
+	t.Parallel()
+
+	entries := ctags.ParseMarkdown(
+		[]byte("# Test Plan\n## Test\n## Testing\n"),
+		"plan.md",
+	)
+	exact := "exact"
+	strict := true
+
+	entry, err := resolveSection(entries, sectionAddress{
+		Heading: "test", Occurrence: nil, MatchMode: &exact, Strict: &strict,
+	})
+	if err != nil {
+		t.Fatalf("resolveSection failed: %v", err)
+	}
+	if entry.Name != "Test" {
+		t.Errorf("Expected exact match 'Test', got %q", entry.Name)
+	}
+}
+
+func TestResolveSection_Anchor(t *testing.T) {
+	t.Parallel()
+
+	entries := ctags.ParseMarkdown(
+		[]byte("# Plan\n## Task 2.1: Add JSON output format\n## Setup\n## Setup\n"),
+		"plan.md",
+	)
+
+	entry, err := resolveSection(entries, sectionAddress{
+		Heading: "#task-21-add-json-output-format", Occurrence: nil,
+		MatchMode: nil, CaseSensitive: nil, Strict: nil,
+	})
+	if err != nil {
+		t.Fatalf("resolveSection failed: %v", err)
+	}
+	if entry.Line != 2 {
+		t.Errorf("Expected line 2, got %d", entry.Line)
+	}
+
+	entry, err = resolveSection(entries, sectionAddress{
+		Heading: "#setup-1", Occurrence: nil,
+		MatchMode: nil, CaseSensitive: nil, Strict: nil,
+	})
+	if err != nil {
+		t.Fatalf("resolveSection failed: %v", err)
+	}
+	if entry.Line != 4 || entry.Slug != "setup-1" {
+		t.Errorf("Expected setup-1 at line 4, got %q at %d", entry.Slug, entry.Line)
+	}
+
+	_, err = resolveSection(entries, sectionAddress{
+		Heading: "#unknown", Occurrence: nil,
+		MatchMode: nil, CaseSensitive: nil, Strict: nil,
+	})
+	if !errors.Is(err, ErrSectionNotFound) {
+		t.Errorf("Expected ErrSectionNotFound, got %v", err)
+	}
+}
@@ -11,10 +11,15 @@ import (
 
 // MarkdownTreeArgs defines the input arguments for the markdown_tree tool.
 type MarkdownTreeArgs struct {
-	FilePath           string  `json:"file_path"                      description:"Path to markdown file"                                                                                             required:"true"`
+	FilePath           string  `json:"file_path"                      description:"Path to markdown file"                                                                                                                                                                required:"true"`
 	Format             *string `json:"format,omitempty"               description:"Output format: 'json' for structured data or 'ascii' for visual tree. Default: 'json'"`
-	SectionNamePattern *string `json:"section_name_pattern,omitempty" description:"Regex pattern to filter which sections appear in tree. Example: 'Task.*' shows only sections starting with 'Task'"`
-	MaxDepth           *int    `json:"max_depth,omitempty"            description:"Maximum tree depth to display (1-6, 0=all). Default: 2 (H1+H2)"`
+	SectionNamePattern *string `json:"section_name_pattern,omitempty" description:"Filter which sections appear in tree (parents of matches are kept). Interpreted per match_mode; e.g. 'Task.*' with match_mode 'regex' or 'Task *' with 'glob'"`
+	MaxDepth           *int    `json:"max_depth,omitempty"            description:"Maximum tree depth to display (1-6, 0=all). Default: 2 (H1+H2). With section_heading, counts levels from that section (1=section only)"`
+	SectionHeading     *string `json:"section_heading,omitempty"      description:"Show only the subtree of this section. Heading text or a path separated by ' > ', e.g. 'Phase 2 > Task 2.1'"`
+	Occurrence         *int    `json:"occurrence,omitempty"           description:"Which match of section_heading to use when several sections match (1=first). Default: 1"`
+	MatchMode          *string `json:"match_mode,omitempty"           description:"How section_heading and section_name_pattern are compared with heading names: 'exact', 'prefix', 'substring', 'regex' (RE2, unanchored) or 'glob' (whole name). Default: 'substring'"`
+	CaseSensitive      *bool   `json:"case_sensitive,omitempty"       description:"Compare heading names case-sensitively. Default: false"`
+	Strict             *bool   `json:"strict,omitempty"               description:"Fail with a list of candidate sections instead of using the first match when section_heading is ambiguous. Default: false"`
 }
 
 // MarkdownTreeResponse defines the response structure.
@@ -53,20 +58,42 @@ func RegisterMarkdownTree(srv server.Server) {
 				return nil, fmt.Errorf("%w for %s", ErrNoEntries, args.FilePath)
 			}
 
+			// Filter by depth (default: 2, use 0 for unlimited)
+			depth := 2
+			if args.MaxDepth != nil {
+				depth = *args.MaxDepth
+			}
+
+			// Restrict to a section subtree if requested
+			entries, depth, err = scopeToSection(
+				entries,
+				sectionAddressFromArgs(
+					args.SectionHeading,
+					args.Occurrence,
+					args.MatchMode,
+					args.CaseSensitive,
+					args.Strict,
+				),
+				depth,
+			)
+			if err != nil {
+				return nil, err
+			}
+
 			// Filter by pattern if provided
 			if args.SectionNamePattern != nil &&
 				*args.SectionNamePattern != "" {
-				entries = ctags.FilterByPatternWithParents(
-					entries,
+				matcher, err := newNameMatcher(
 					*args.SectionNamePattern,
+					args.MatchMode,
+					args.CaseSensitive,
 				)
+				if err != nil {
+					return nil, err
+				}
+				entries = ctags.FilterByMatcherWithParents(entries, matcher)
 			}
 
-			// Filter by depth (default: 2, use 0 for unlimited)
-			depth := 2
-			if args.MaxDepth != nil {
-				depth = *args.MaxDepth
-			}
 			if depth > 0 {
 				entries = ctags.FilterByDepth(entries, depth)
 			}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/localrivet/gomcp/server"
+)
+
+// MarkdownUndoArgs defines the input arguments.
+type MarkdownUndoArgs struct {
+	FilePath string `json:"file_path"      description:"Path to markdown file edited by this server" required:"true"`
+	Redo     *bool  `json:"redo,omitempty" description:"Re-apply the most recently undone edit instead of undoing the latest edit. Default: false"`
+}
+
+// MarkdownUndoResponse defines the response structure.
+type MarkdownUndoResponse struct {
+	EditID   int    `json:"edit_id"` // As listed by markdown_history
+	Tool     string `json:"tool"`
+	EditTime string `json:"edit_time"`
+	Redo     bool   `json:"redo"`
+	FileHash string `json:"file_hash"` // Version after the undo
+	Mtime    string `json:"mtime"`
+}
+
+// RegisterMarkdownUndo registers the markdown_undo tool.
+func RegisterMarkdownUndo(srv server.Server) {
+	srv.Tool(
+		"markdown_undo",
+		"Undo the latest edit this server made to a file, or with redo re-apply the last undone one. Repeated calls walk back through the journal listed by markdown_history. Refuses if the file was changed outside the server since the edit.",
+		handleUndo,
+	)
+}
+
+// handleUndo implements the markdown_undo tool logic.
+func handleUndo(
+	_ *server.Context,
+	args MarkdownUndoArgs,
+) (interface{}, error) {
+	redo := args.Redo != nil && *args.Redo
+
+	doc, err := loadDocument(args.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	journal := getJournal()
+	entries := journal.entries(args.FilePath)
+	entry, ok := undoable(entries)
+	action := "undo"
+	if redo {
+		entry, ok = redoable(entries)
+		action = "redo"
+	}
+	if !ok {
+		return nil, fmt.Errorf(
+			"%w: no edit of %s to %s",
+			ErrNoJournalEntry,
+			args.FilePath,
+			action,
+		)
+	}
+
+	updated, err := doc.replay(entry, redo)
+	if err != nil {
+		return nil, err
+	}
+	journal.setUndone(args.FilePath, entry.id, !redo)
+
+	return MarkdownUndoResponse{
+		EditID:   entry.id,
+		Tool:     entry.tool,
+		EditTime: formatModTime(entry.time),
+		Redo:     redo,
+		FileHash: updated.version.Hash,
+		Mtime:    formatModTime(updated.version.ModTime),
+	}, nil
+}
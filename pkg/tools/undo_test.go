@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// undoDoc is the document edited by the undo tests.
+const undoDoc = "# Plan\n" +
+	"\n" +
+	"## Phase 1\n" +
+	"\n" +
+	"Old text.\n" +
+	"\n" +
+	"## Phase 2\n"
+
+// replaceBody replaces the body of heading in filePath with content, making
+// an edit for the journal.
+func replaceBody(
+	t *testing.T,
+	filePath, heading, content, hash string,
+) MarkdownReplaceSectionResponse {
+	t.Helper()
+
+	return runTool[MarkdownReplaceSectionResponse](
+		t,
+		handleReplaceSection,
+		MarkdownReplaceSectionArgs{
+			FilePath:       filePath,
+			SectionHeading: heading,
+			Content:        content,
+			ExpectedHash:   &hash,
+		},
+	)
+}
+
+func TestHandleUndo_UndoAndRedo(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, undoDoc)
+	undoArgs := MarkdownUndoArgs{FilePath: filePath}
+	redoArgs := MarkdownUndoArgs{FilePath: filePath, Redo: ptr(true)}
+	undo := func(redo bool) MarkdownUndoResponse {
+		t.Helper()
+		return runTool[MarkdownUndoResponse](
+			t,
+			handleUndo,
+			MarkdownUndoArgs{FilePath: filePath, Redo: &redo},
+		)
+	}
+
+	first := replaceBody(t, filePath, "Phase 1", "New text.", hash)
+	edited := readFixture(t, filePath)
+	replaceBody(t, filePath, "Phase 2", "More.", first.FileHash)
+
+	response := undo(false)
+	if response.EditID != 2 || response.Tool != "markdown_replace_section" ||
+		response.Redo || response.FileHash != first.FileHash {
+		t.Errorf("Unexpected response %+v", response)
+	}
+	if got := readFixture(t, filePath); got != edited {
+		t.Errorf("Unexpected content after undo:\n%s", got)
+	}
+
+	response = undo(false)
+	if response.EditID != 1 || response.FileHash != hash {
+		t.Errorf("Unexpected response %+v", response)
+	}
+	if got := readFixture(t, filePath); got != undoDoc {
+		t.Errorf("Unexpected content after second undo:\n%s", got)
+	}
+	if _, err := handleUndo(nil, undoArgs); !errors.Is(
+		err,
+		ErrNoJournalEntry,
+	) {
+		t.Errorf("Expected ErrNoJournalEntry, got %v", err)
+	}
+
+	response = undo(true)
+	if response.EditID != 1 || !response.Redo ||
+		response.FileHash != first.FileHash {
+		t.Errorf("Unexpected response %+v", response)
+	}
+	if got := readFixture(t, filePath); got != edited {
+		t.Errorf("Unexpected content after redo:\n%s", got)
+	}
+
+	// A new edit drops the undone edit 2 from the journal
+	replaceBody(t, filePath, "Phase 1", "Other.", first.FileHash)
+	if _, err := handleUndo(nil, redoArgs); !errors.Is(
+		err,
+		ErrNoJournalEntry,
+	) {
+		t.Errorf("Expected ErrNoJournalEntry, got %v", err)
+	}
+	if response := undo(false); response.EditID != 3 {
+		t.Errorf("Unexpected response %+v", response)
+	}
+}
+
+func TestHandleUndo_ExternalChange(t *testing.T) {
+	t.Parallel()
+
+	filePath, hash := writeFixture(t, undoDoc)
+	replaceBody(t, filePath, "Phase 1", "New text.", hash)
+
+	undoArgs := MarkdownUndoArgs{FilePath: filePath}
+	external := undoDoc + "\nAdded by hand.\n"
+	if err := os.WriteFile(filePath, []byte(external), 0o600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := handleUndo(nil, undoArgs); !errors.Is(
+		err,
+		ctags.ErrFileChanged,
+	) {
+		t.Errorf("Expected ErrFileChanged, got %v", err)
+	}
+	if got := readFixture(t, filePath); got != external {
+		t.Errorf("Refused undo changed the file:\n%s", got)
+	}
+}
+
+func TestEditJournal_Limit(t *testing.T) {
+	t.Parallel()
+
+	filePath, _ := writeFixture(t, undoDoc)
+	doc, err := loadDocument(filePath)
+	if err != nil {
+		t.Fatalf("loadDocument failed: %v", err)
+	}
+
+	journal := newEditJournal(2)
+	for range 3 {
+		journal.record(doc, doc, "markdown_replace_section")
+	}
+	entries := journal.entries(filePath)
+	if len(entries) != 2 || entries[0].id != 2 || entries[1].id != 3 {
+		t.Errorf("Unexpected entries %+v", entries)
+	}
+
+	journal.setLimit(1)
+	if entries := journal.entries(filePath); len(entries) != 1 ||
+		entries[0].id != 3 {
+		t.Errorf("Unexpected entries %+v", entries)
+	}
+
+	journal.setLimit(0)
+	journal.record(doc, doc, "markdown_replace_section")
+	if entries := journal.entries(filePath); len(entries) != 0 {
+		t.Errorf("Disabled journal kept entries %+v", entries)
+	}
+}
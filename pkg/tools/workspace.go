@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// maxFileWorkers bounds the number of files processed concurrently.
+const maxFileWorkers = 8
+
+// workspace lists the files selected in a documentation directory.
+type workspace struct {
+	Directory string   // Absolute directory path
+	RelPaths  []string // Slash-separated paths relative to Directory
+	FilePaths []string // Paths to the same files, for reading
+}
+
+// resolveWorkspace lists the files below directory selected by the include
+// and exclude globs, in sorted order.
+func resolveWorkspace(
+	directory string,
+	include []string,
+	exclude []string,
+) (workspace, error) {
+	abs, err := filepath.Abs(directory)
+	if err != nil {
+		return workspace{}, fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	filter, err := ctags.NewPathFilter(include, exclude)
+	if err != nil {
+		return workspace{}, err
+	}
+	relPaths, err := ctags.WorkspaceFiles(abs, filter)
+	if err != nil {
+		return workspace{}, err
+	}
+
+	filePaths := make([]string, 0, len(relPaths))
+	for _, relPath := range relPaths {
+		filePaths = append(
+			filePaths,
+			filepath.Join(abs, filepath.FromSlash(relPath)),
+		)
+	}
+
+	return workspace{
+		Directory: abs,
+		RelPaths:  relPaths,
+		FilePaths: filePaths,
+	}, nil
+}
+
+// forEachFile calls fn with every index in [0, count) using a pool of
+// workers. Indexes are handed out in order; none are started once ctx is
+// cancelled. fn must only write to per-index state.
+func forEachFile(ctx context.Context, count int, fn func(i int)) {
+	workers := min(runtime.NumCPU(), maxFileWorkers, count)
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := range count {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/localrivet/gomcp/server"
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// MarkdownWorkspaceTreeArgs defines the input arguments.
+type MarkdownWorkspaceTreeArgs struct {
+	Directory string   `json:"directory"           description:"Path to the documentation directory"                                                                                                                                                        required:"true"`
+	Include   []string `json:"include,omitempty"   description:"Globs selecting files, relative to directory. '*' stays within a path segment, '**' spans directories; a glob without '/' matches file names at any depth. Default: ['*.md', '*.markdown']"`
+	Exclude   []string `json:"exclude,omitempty"   description:"Globs of files or directories to skip, e.g. 'archive/**' or 'CHANGELOG.md'. Hidden directories are always skipped"`
+	MaxDepth  *int     `json:"max_depth,omitempty" description:"Maximum heading depth per file (1-6, 0=all). Default: 2 (H1+H2)"`
+}
+
+// FileError reports a file that could not be processed.
+type FileError struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// MarkdownWorkspaceTreeResponse defines the response structure.
+type MarkdownWorkspaceTreeResponse struct {
+	Directory string          `json:"directory"` // Absolute directory path
+	Files     int             `json:"files"`     // Files in the tree
+	Headings  int             `json:"headings"`  // Headings in the tree
+	TreeJSON  *ctags.TreeNode `json:"tree_json"` // Directories, files, headings
+	Errors    []FileError     `json:"errors,omitempty"`
+}
+
+// RegisterMarkdownWorkspaceTree registers the markdown_workspace_tree tool.
+func RegisterMarkdownWorkspaceTree(srv server.Server) {
+	srv.Tool(
+		"markdown_workspace_tree",
+		"Display the structure of a whole documentation folder as one tree: directories, then markdown files, then each file's headings. Use to find which document covers a topic before reading sections; use markdown_tree for a single file.",
+		handleWorkspaceTree,
+	)
+}
+
+// handleWorkspaceTree implements the markdown_workspace_tree tool logic.
+func handleWorkspaceTree(
+	_ *server.Context,
+	args MarkdownWorkspaceTreeArgs,
+) (interface{}, error) {
+	// Note: gomcp's server.Context does not provide request-level context.
+	// Application-level cancellation is handled via signal handling in main.go.
+	reqCtx := context.Background()
+
+	workspace, err := resolveWorkspace(
+		args.Directory,
+		args.Include,
+		args.Exclude,
+	)
+	if err != nil {
+		return nil, err
+	}
+	relPaths := workspace.RelPaths
+
+	// Parse all uncached files with one ctags execution
+	cache := ctags.GetGlobalCache()
+	results, err := cache.GetTagsBatch(reqCtx, workspace.FilePaths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	// Filter by depth (default: 2, use 0 for unlimited)
+	depth := 2
+	if args.MaxDepth != nil {
+		depth = *args.MaxDepth
+	}
+
+	files := make([]ctags.WorkspaceFile, 0, len(results))
+	var fileErrors []FileError
+	headings := 0
+	for i, result := range results {
+		if result.Err != nil {
+			fileErrors = append(fileErrors, FileError{
+				Path:  relPaths[i],
+				Error: result.Err.Error(),
+			})
+			continue
+		}
+
+		entries := result.Tags
+		if depth > 0 {
+			entries = ctags.FilterByDepth(entries, depth)
+		}
+		headings += len(entries)
+		files = append(files, ctags.WorkspaceFile{
+			Path:    relPaths[i],
+			Entries: entries,
+		})
+	}
+
+	return MarkdownWorkspaceTreeResponse{
+		Directory: workspace.Directory,
+		Files:     len(files),
+		Headings:  headings,
+		TreeJSON: ctags.BuildWorkspaceTree(
+			filepath.Base(workspace.Directory),
+			files,
+		),
+		Errors: fileErrors,
+	}, nil
+}
@@ -0,0 +1,116 @@
+package tools
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yoseforb/markdown-nav-mcp/pkg/ctags"
+)
+
+// createWorkspace creates files (relative path to content) below a new
+// temporary directory and returns the directory.
+func createWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for relPath, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", relPath, err)
+		}
+	}
+	return root
+}
+
+func TestHandleWorkspaceTree(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{
+		"README.md":        "# Project\n## Setup\n### Details\n",
+		"docs/guide.md":    "# Guide\n## Install\n",
+		"docs/draft.md":    "# Draft\n",
+		"archive/old.md":   "# Old\n",
+		"docs/diagram.svg": "<svg/>",
+	})
+
+	maxDepth := 2
+	result, err := handleWorkspaceTree(nil, MarkdownWorkspaceTreeArgs{
+		Directory: root,
+		Include:   nil,
+		Exclude:   []string{"archive/**", "draft.md"},
+		MaxDepth:  &maxDepth,
+	})
+	if err != nil {
+		t.Fatalf("handleWorkspaceTree failed: %v", err)
+	}
+	response, ok := result.(MarkdownWorkspaceTreeResponse)
+	if !ok {
+		t.Fatalf("Unexpected response type %T", result)
+	}
+
+	if response.Files != 2 || response.Headings != 4 {
+		t.Errorf("Expected 2 files and 4 headings, got %d and %d",
+			response.Files, response.Headings)
+	}
+	if len(response.Errors) != 0 {
+		t.Errorf("Expected no errors, got %+v", response.Errors)
+	}
+
+	tree := response.TreeJSON
+	if tree.Name != filepath.Base(root) || tree.Kind != ctags.NodeDirectory {
+		t.Errorf("Unexpected root node %q (%s)", tree.Name, tree.Kind)
+	}
+	if len(tree.Children) != 2 {
+		t.Fatalf("Expected docs/ and README.md, got %d children",
+			len(tree.Children))
+	}
+
+	docs, readme := tree.Children[0], tree.Children[1]
+	if docs.Name != "docs" || len(docs.Children) != 1 {
+		t.Fatalf("Expected docs/ with one file, got %+v", docs)
+	}
+	if docs.Children[0].Path != "docs/guide.md" {
+		t.Errorf("Expected docs/guide.md, got %q", docs.Children[0].Path)
+	}
+	if readme.Kind != ctags.NodeFile || readme.Name != "README.md" {
+		t.Errorf("Expected README.md file node, got %+v", readme)
+	}
+
+	// max_depth limits each file to H1+H2
+	project := readme.Children[0]
+	if project.Name != "Project" || len(project.Children) != 1 ||
+		len(project.Children[0].Children) != 0 {
+		t.Errorf("Expected Project > Setup without H3, got %+v", project)
+	}
+}
+
+func TestHandleWorkspaceTree_InvalidArgs(t *testing.T) {
+	t.Parallel()
+
+	root := createWorkspace(t, map[string]string{"a.md": "# A\n"})
+
+	_, err := handleWorkspaceTree(nil, MarkdownWorkspaceTreeArgs{
+		Directory: root,
+		Include:   []string{"[z-a].md"},
+		Exclude:   nil,
+		MaxDepth:  nil,
+	})
+	if !errors.Is(err, ctags.ErrInvalidPattern) {
+		t.Errorf("Expected ErrInvalidPattern, got %v", err)
+	}
+
+	_, err = handleWorkspaceTree(nil, MarkdownWorkspaceTreeArgs{
+		Directory: filepath.Join(root, "a.md"),
+		Include:   nil,
+		Exclude:   nil,
+		MaxDepth:  nil,
+	})
+	if !errors.Is(err, ctags.ErrNotADirectory) {
+		t.Errorf("Expected ErrNotADirectory, got %v", err)
+	}
+}